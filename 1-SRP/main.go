@@ -1,27 +1,151 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+
+	"github.com/imrancluster/go-solid/id"
 )
 
+// ErrNoRate is returned for currency pairs a RateProvider has no rate for
+var ErrNoRate = errors.New("invoice: no exchange rate")
+
 type Invoice struct {
-	ID     int
-	Amount float64
+	ID       string
+	Amount   float64
+	Currency string
+	// Payments that settled the invoice, in the order they were made and
+	// in whichever currency they were made in
+	Payments []Payment
 }
 
 func (i Invoice) CalculateTax() float64 {
 	return i.Amount * 0.15 // 15% tax calculation
 }
 
+// Payment is what the invoice knows of a payment toward it. How the
+// payment was taken is the processor's business, not the invoice's.
+type Payment struct {
+	ID       string
+	Method   string
+	Amount   float64
+	Currency string
+}
+
+// Separate responsibility for issuing invoices under a new ID. How IDs are
+// made is up to the generator, so tests can number invoices predictably.
+type InvoiceIssuer struct {
+	IDs id.Generator
+}
+
+func (i InvoiceIssuer) Issue(amount float64, currency string) Invoice {
+	return Invoice{ID: i.IDs.NewID(), Amount: amount, Currency: currency}
+}
+
+// RateProvider quotes exchange rates: one unit of from buys rate units of
+// to. Where the rates come from is up to whoever wires the balance.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// RateTable quotes every rate through Base: Rates holds what one Base buys
+// of each other currency
+type RateTable struct {
+	Base  string
+	Rates map[string]float64
+}
+
+func (t RateTable) Rate(_ context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	fromUnits, fromOK := t.units(from)
+	toUnits, toOK := t.units(to)
+	if !fromOK || !toOK {
+		return 0, fmt.Errorf("%w: %s to %s", ErrNoRate, from, to)
+	}
+	return toUnits / fromUnits, nil
+}
+
+// units is what one Base buys of currency
+func (t RateTable) units(currency string) (float64, bool) {
+	if currency == t.Base {
+		return 1, true
+	}
+	units, ok := t.Rates[currency]
+	return units, ok && units > 0
+}
+
+// Separate responsibility for working out what is left to pay when the
+// invoice is paid in several currencies. The rates are up to the provider.
+type InvoiceBalance struct {
+	Rates RateProvider
+}
+
+// Due is what is left to pay in the invoice's currency, rounded to cents
+func (b InvoiceBalance) Due(ctx context.Context, invoice Invoice) (float64, error) {
+	due := invoice.Amount
+	for _, payment := range invoice.Payments {
+		rate, err := b.Rates.Rate(ctx, payment.Currency, invoice.Currency)
+		if err != nil {
+			return 0, err
+		}
+		due -= payment.Amount * rate
+	}
+	return math.Round(due*100) / 100, nil
+}
+
 // Separate responsibility for printing the invoice
 type InvoicePrinter struct{}
 
 func (p InvoicePrinter) PrintInvoice(invoice Invoice) {
-	fmt.Printf("Invoice ID: %d, Amount: %f\n", invoice.ID, invoice.Amount)
+	fmt.Printf("Invoice ID: %s, Amount: %f %s\n", invoice.ID, invoice.Amount, invoice.Currency)
+}
+
+// Separate responsibility for printing how the invoice was paid
+type PaymentTrailPrinter struct{}
+
+func (p PaymentTrailPrinter) PrintTrail(invoice Invoice) {
+	for _, payment := range invoice.Payments {
+		fmt.Printf("  %s %-6s %f %s\n", payment.ID, payment.Method, payment.Amount, payment.Currency)
+	}
 }
 
 func main() {
-	invoice := Invoice{ID: 1, Amount: 1000}
+	ctx := context.Background()
+	rates := RateTable{Base: "USD", Rates: map[string]float64{"EUR": 0.92, "BTC": 0.000016}}
+	balance := InvoiceBalance{Rates: rates}
+
+	// ULIDs sort by when the invoice was issued
+	ids := id.ULID{}
+	invoice := InvoiceIssuer{IDs: ids}.Issue(1000, "USD")
 	printer := InvoicePrinter{}
 	printer.PrintInvoice(invoice)
+
+	// Part by card in dollars, part by card in euros
+	invoice.Payments = append(invoice.Payments,
+		Payment{ID: ids.NewID(), Method: "card", Amount: 600, Currency: "USD"},
+		Payment{ID: ids.NewID(), Method: "card", Amount: 184, Currency: "EUR"},
+	)
+	due, err := balance.Due(ctx, invoice)
+	if err != nil {
+		fmt.Println("Working out the balance failed:", err)
+		return
+	}
+	fmt.Printf("Due: %.2f %s\n", due, invoice.Currency)
+
+	// The rest in bitcoin, at the same rates
+	rate, err := rates.Rate(ctx, invoice.Currency, "BTC")
+	if err != nil {
+		fmt.Println("Converting the balance failed:", err)
+		return
+	}
+	invoice.Payments = append(invoice.Payments, Payment{ID: ids.NewID(), Method: "crypto", Amount: due * rate, Currency: "BTC"})
+	if due, err := balance.Due(ctx, invoice); err == nil {
+		fmt.Printf("Due: %.2f %s\n", due, invoice.Currency)
+	}
+
+	PaymentTrailPrinter{}.PrintTrail(invoice)
 }
@@ -0,0 +1,133 @@
+// Command birds shows a subtype that cannot honor a method at all: a
+// Penguin is a Bird, but it cannot Fly. The fix is to stop promising
+// flight for every bird.
+//
+// Run it with: go run ./3-LSP/birds
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FlyingBird is the broken abstraction: it assumes every bird flies
+type FlyingBird interface {
+	Name() string
+	Fly(meters float64) error
+	Altitude() float64
+}
+
+// Sparrow honors FlyingBird
+type Sparrow struct {
+	altitude float64
+}
+
+func (s *Sparrow) Name() string { return "sparrow" }
+
+func (s *Sparrow) Fly(meters float64) error {
+	s.altitude += meters
+	return nil
+}
+
+func (s *Sparrow) Altitude() float64 { return s.altitude }
+
+// Penguin "is a" bird, so it implements FlyingBird, but all it can do with
+// Fly is refuse. Every caller of FlyingBird now has to know about penguins.
+type Penguin struct{}
+
+func (p *Penguin) Name() string { return "penguin" }
+
+func (p *Penguin) Fly(meters float64) error {
+	return errors.New("penguins cannot fly")
+}
+
+func (p *Penguin) Altitude() float64 { return 0 }
+
+// flyProperty is the contract callers of FlyingBird assume: flying up
+// succeeds and raises the bird by that much
+func flyProperty(b FlyingBird) error {
+	before := b.Altitude()
+	if err := b.Fly(10); err != nil {
+		return fmt.Errorf("%s: Fly(10) returned error: %w", b.Name(), err)
+	}
+	if after := b.Altitude(); after != before+10 {
+		return fmt.Errorf("%s: altitude after Fly(10) = %v, want %v", b.Name(), after, before+10)
+	}
+	return nil
+}
+
+// Bird is the corrected abstraction: it only promises what every bird can do
+type Bird interface {
+	Name() string
+	Move(meters float64)
+	Distance() float64
+}
+
+// Flyer is a capability of its own, for the birds that have it
+type Flyer interface {
+	Fly(meters float64) error
+	Altitude() float64
+}
+
+// Swallow walks and flies
+type Swallow struct {
+	distance, altitude float64
+}
+
+func (s *Swallow) Name() string        { return "swallow" }
+func (s *Swallow) Move(meters float64) { s.distance += meters }
+func (s *Swallow) Distance() float64   { return s.distance }
+func (s *Swallow) Altitude() float64   { return s.altitude }
+
+func (s *Swallow) Fly(meters float64) error {
+	s.altitude += meters
+	return nil
+}
+
+// Emperor is a penguin that only promises what it can keep
+type Emperor struct {
+	distance float64
+}
+
+func (e *Emperor) Name() string        { return "emperor penguin" }
+func (e *Emperor) Move(meters float64) { e.distance += meters }
+func (e *Emperor) Distance() float64   { return e.distance }
+
+// moveProperty holds for every Bird: moving adds up
+func moveProperty(b Bird) error {
+	before := b.Distance()
+	b.Move(3)
+	if after := b.Distance(); after != before+3 {
+		return fmt.Errorf("%s: distance after Move(3) = %v, want %v", b.Name(), after, before+3)
+	}
+	return nil
+}
+
+func main() {
+	fmt.Println("Every bird flies")
+	report("Sparrow", flyProperty(&Sparrow{}))
+	report("Penguin", flyProperty(&Penguin{}))
+
+	fmt.Println("Flying is a capability")
+	for _, bird := range []Bird{&Swallow{}, &Emperor{}} {
+		report(bird.Name(), moveProperty(bird))
+		// Callers that need flight ask for it instead of hoping
+		if flyer, ok := bird.(Flyer); ok {
+			report(bird.Name()+" flies", flyProperty(flyingBird{bird, flyer}))
+		}
+	}
+}
+
+// flyingBird joins a Bird and its Flyer capability for flyProperty
+type flyingBird struct {
+	Bird
+	Flyer
+}
+
+func report(name string, err error) {
+	if err != nil {
+		fmt.Printf("  FAIL %s: %v\n", name, err)
+		return
+	}
+	fmt.Printf("  ok   %s\n", name)
+}
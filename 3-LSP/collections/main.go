@@ -0,0 +1,138 @@
+// Command collections shows a read-only view that cannot honor Add, and how
+// splitting the interface into Reader and Writer removes the violation: the
+// fix for LSP here is interface segregation.
+//
+// Run it with: go run ./3-LSP/collections
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrReadOnly is all a read-only Collection can answer to Add
+var ErrReadOnly = errors.New("collection is read-only")
+
+// Collection is the fat interface: every collection can be read and added to
+type Collection interface {
+	Add(item string) error
+	Len() int
+	At(i int) string
+}
+
+// List honors Collection
+type List struct {
+	items []string
+}
+
+func (l *List) Add(item string) error {
+	l.items = append(l.items, item)
+	return nil
+}
+
+func (l *List) Len() int        { return len(l.items) }
+func (l *List) At(i int) string { return l.items[i] }
+
+// ReadOnlyList wraps a List to hand out without letting callers change it.
+// It has to implement Add to be a Collection, and can only refuse.
+type ReadOnlyList struct {
+	list *List
+}
+
+func (r ReadOnlyList) Add(item string) error { return ErrReadOnly }
+func (r ReadOnlyList) Len() int              { return r.list.Len() }
+func (r ReadOnlyList) At(i int) string       { return r.list.At(i) }
+
+// addProperty is the contract callers of Collection assume: Add succeeds
+// and the item is there afterwards
+func addProperty(c Collection) error {
+	before := c.Len()
+	if err := c.Add("new"); err != nil {
+		return fmt.Errorf("Add returned error: %w", err)
+	}
+	if c.Len() != before+1 || c.At(before) != "new" {
+		return fmt.Errorf("after Add the collection has %d items, want %d ending in %q", c.Len(), before+1, "new")
+	}
+	return nil
+}
+
+// Reader is what every collection can do
+type Reader interface {
+	Len() int
+	At(i int) string
+}
+
+// Writer is a capability of its own
+type Writer interface {
+	Add(item string) error
+}
+
+// ReadWriter is what code that fills a collection asks for
+type ReadWriter interface {
+	Reader
+	Writer
+}
+
+// Items is the segregated List, it is a ReadWriter
+type Items struct {
+	items []string
+}
+
+func (i *Items) Add(item string) error {
+	i.items = append(i.items, item)
+	return nil
+}
+
+func (i *Items) Len() int          { return len(i.items) }
+func (i *Items) At(idx int) string { return i.items[idx] }
+func (i *Items) View() View        { return View{items: slices.Clone(i.items)} }
+
+// View is a read-only snapshot. It is only a Reader, so nobody can hand it
+// to code that needs to write.
+type View struct {
+	items []string
+}
+
+func (v View) Len() int        { return len(v.items) }
+func (v View) At(i int) string { return v.items[i] }
+
+// readProperty holds for every Reader: At is defined for every index below
+// Len and reading twice gives the same items
+func readProperty(r Reader) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("reading panicked: %v", p)
+		}
+	}()
+	for i := 0; i < r.Len(); i++ {
+		if r.At(i) != r.At(i) {
+			return fmt.Errorf("At(%d) changed between reads", i)
+		}
+	}
+	return nil
+}
+
+func main() {
+	list := &List{items: []string{"a", "b"}}
+
+	fmt.Println("One Collection interface")
+	report("List", addProperty(list))
+	report("ReadOnlyList", addProperty(ReadOnlyList{list: list}))
+
+	fmt.Println("Segregated Reader and Writer")
+	items := &Items{items: []string{"a", "b"}}
+	report("Items as ReadWriter", addProperty(items))
+	report("Items as Reader", readProperty(items))
+	report("View as Reader", readProperty(items.View()))
+	// addProperty(items.View()) does not compile: the mistake is caught
+	// before it can be made
+}
+
+func report(name string, err error) {
+	if err != nil {
+		fmt.Printf("  FAIL %s: %v\n", name, err)
+		return
+	}
+	fmt.Printf("  ok   %s\n", name)
+}
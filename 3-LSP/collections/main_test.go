@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestAddProperty holds for List and fails for ReadOnlyList, which is a
+// Collection that cannot honor Add
+func TestAddProperty(t *testing.T) {
+	list := &List{items: []string{"a", "b"}}
+	if err := addProperty(list); err != nil {
+		t.Errorf("List: %v", err)
+	}
+	err := addProperty(ReadOnlyList{list: list})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("ReadOnlyList error = %v, want %v", err, ErrReadOnly)
+	}
+}
+
+// TestSegregatedCollections holds every collection to the contract of the
+// interfaces it is used through
+func TestSegregatedCollections(t *testing.T) {
+	items := &Items{items: []string{"a", "b"}}
+	if err := addProperty(items); err != nil {
+		t.Errorf("Items as ReadWriter: %v", err)
+	}
+	for name, r := range map[string]Reader{"Items": items, "View": items.View(), "empty View": View{}} {
+		if err := readProperty(r); err != nil {
+			t.Errorf("%s as Reader: %v", name, err)
+		}
+	}
+}
+
+// TestViewIsASnapshot makes sure writing to Items does not show through a
+// View taken before
+func TestViewIsASnapshot(t *testing.T) {
+	items := &Items{items: []string{"a", "b"}}
+	view := items.View()
+	if err := items.Add("c"); err != nil {
+		t.Fatal(err)
+	}
+	if view.Len() != 2 || view.At(0) != "a" || view.At(1) != "b" {
+		t.Errorf("View after Add has %d items, want a and b", view.Len())
+	}
+	if _, ok := any(view).(Writer); ok {
+		t.Error("View is a Writer, want it to be read-only")
+	}
+}
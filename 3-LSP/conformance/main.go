@@ -0,0 +1,121 @@
+// Command conformance runs the PaymentProcessor contract against every
+// registered processor, bare and decorated, and reports the outcome.
+//
+// Run it with: go run ./3-LSP/conformance
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/fx"
+	"github.com/imrancluster/go-solid/3-LSP/ledger"
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+	"github.com/imrancluster/go-solid/3-LSP/settlement"
+)
+
+// entry is a processor under test and how to build a fresh one
+type entry struct {
+	name         string
+	newProcessor func() payment.PaymentProcessor
+}
+
+// entries lists every registered processor, then the decorated ones
+func entries() []entry {
+	quiet := log.New(io.Discard, "", 0)
+	var processors []entry
+	for _, name := range payment.Names() {
+		processors = append(processors, entry{name, func() payment.PaymentProcessor {
+			p, _ := payment.New(name)
+			return p
+		}})
+	}
+	processors = append(processors,
+		entry{"sync(async)", func() payment.PaymentProcessor {
+			return payment.SyncProcessor{Processor: &payment.AsyncPayment{Delay: time.Millisecond}, PollInterval: time.Millisecond}
+		}},
+		entry{"logging(card)", func() payment.PaymentProcessor {
+			return payment.LoggingProcessor{Processor: &payment.CardPayment{}, Logger: quiet}
+		}},
+		entry{"logging(crypto)", func() payment.PaymentProcessor {
+			return payment.LoggingProcessor{Processor: &payment.CryptoPayment{}, Logger: quiet}
+		}},
+		entry{"retrying(card)", func() payment.PaymentProcessor {
+			return payment.RetryingProcessor{Processor: &payment.CardPayment{}}
+		}},
+		entry{"settlement(card)", func() payment.PaymentProcessor {
+			return new(settlement.Collector).Wrap("card", &payment.CardPayment{})
+		}},
+		entry{"gift-card(partial)", func() payment.PaymentProcessor {
+			g := payment.NewGiftCardPayment(payment.DEFAULT_GIFT_CARD, payment.DEFAULT_GIFT_CARD_BALANCE)
+			g.AllowPartial = true
+			return g
+		}},
+		entry{"ledger(card)", func() payment.PaymentProcessor {
+			return (&ledger.Recorder{Ledger: &ledger.MemoryLedger{}}).Wrap("card", &payment.CardPayment{})
+		}},
+		entry{"v1(v2(bank-transfer))", func() payment.PaymentProcessor {
+			return payment.V1(payment.V2(&payment.BankTransferPayment{}))
+		}},
+		entry{"simulated(latency)", func() payment.PaymentProcessor {
+			return &payment.SimulatedProcessor{Latency: time.Millisecond, Jitter: time.Millisecond}
+		}},
+		entry{"authenticating(card(sca))", func() payment.PaymentProcessor {
+			resolver := payment.ChallengeResolverFunc(func(context.Context, payment.Challenge) (string, error) {
+				return payment.DEFAULT_CHALLENGE_CODE, nil
+			})
+			return payment.AuthenticatingProcessor{Processor: &payment.CardPayment{ChallengeAbove: 100}, Resolver: resolver}
+		}},
+		entry{"locked(simulated)", func() payment.PaymentProcessor {
+			return &payment.LockedProcessor{Processor: &payment.SimulatedProcessor{Jitter: time.Millisecond}}
+		}},
+		entry{"ratelimited(card)", func() payment.PaymentProcessor {
+			return &payment.RateLimitedProcessor{Processor: &payment.CardPayment{}, Rate: 100000, Burst: 1000}
+		}},
+		entry{"converting(crypto)", func() payment.PaymentProcessor {
+			rates := fx.Table{Base: payment.USD, Rates: map[payment.Currency]float64{payment.EUR: 0.92, payment.BTC: 0.000016}}
+			return &payment.ConvertingProcessor{Processor: &payment.CryptoPayment{}, Rates: rates, Accept: []payment.Currency{payment.USD, payment.EUR}}
+		}},
+		entry{"retrying(logging(cash))", func() payment.PaymentProcessor {
+			return payment.RetryingProcessor{Processor: payment.LoggingProcessor{Processor: &payment.CashPayment{}, Logger: quiet}}
+		}},
+	)
+	return processors
+}
+
+func main() {
+	failed := false
+	for _, p := range entries() {
+		passed, total := 0, 0
+		for _, result := range paymenttest.Verify(p.newProcessor) {
+			if errors.Is(result.Err, paymenttest.ErrNotApplicable) {
+				continue
+			}
+			total++
+			if result.Err != nil {
+				failed = true
+				fmt.Printf("%s: FAIL %s: %v\n", p.name, result.Check.Name, result.Err)
+				continue
+			}
+			passed++
+		}
+		fmt.Printf("%-27s %d/%d checks passed\n", p.name, passed, total)
+	}
+
+	// The same scenarios against every registered processor side by side
+	fmt.Println()
+	matrix, err := paymenttest.RunMatrix(payment.Names())
+	if err != nil {
+		log.Fatal(err)
+	}
+	matrix.Render(os.Stdout)
+	if failed || !matrix.Passed() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+)
+
+// TestConformance runs the contract against every processor the command
+// reports on, so go test fails where go run ./3-LSP/conformance would
+func TestConformance(t *testing.T) {
+	for _, p := range entries() {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			paymenttest.RunProcessorSuite(t, p.newProcessor)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Package contract makes the implicit processor contract executable.
+//
+// A precondition is what a caller must provide; a substitute may weaken it
+// but never strengthen it. A postcondition is what a processor promises in
+// return; a substitute may strengthen it but never weaken it.
+package contract
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrPrecondition marks errors caused by the caller breaking the contract
+	ErrPrecondition = errors.New("contract: precondition failed")
+	// ErrPostcondition marks errors caused by the implementation breaking the contract
+	ErrPostcondition = errors.New("contract: postcondition failed")
+)
+
+// Requires returns err, marked as a precondition failure, when cond is false.
+// Callers can match both ErrPrecondition and err with errors.Is.
+func Requires(cond bool, err error) error {
+	if cond {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrPrecondition, err)
+}
+
+// Ensures returns a postcondition failure described by format when cond is false
+func Ensures(cond bool, format string, args ...any) error {
+	if cond {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrPostcondition, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,134 @@
+// Package fx quotes exchange rates. Table reads them from a fixed table
+// and HTTP from a Frankfurter-style rates API; both implement
+// payment.RateProvider, so converting processors get their rates without
+// knowing which.
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// FRANKFURTER_URL is a free API of the European Central Bank's reference
+// rates
+const FRANKFURTER_URL = "https://api.frankfurter.app"
+
+var (
+	_ payment.RateProvider = Table{}
+	_ payment.RateProvider = HTTP{}
+)
+
+// Table quotes every rate through Base: Rates holds what one Base buys of
+// each other currency, so EUR to GBP is Rates[GBP] / Rates[EUR].
+type Table struct {
+	Base  payment.Currency
+	Rates map[payment.Currency]float64
+}
+
+func (t Table) Rate(_ context.Context, from, to payment.Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	fromUnits, ok := t.units(from)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s to %s", payment.ErrNoRate, from, to)
+	}
+	toUnits, ok := t.units(to)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s to %s", payment.ErrNoRate, from, to)
+	}
+	return toUnits / fromUnits, nil
+}
+
+// units is what one Base buys of currency
+func (t Table) units(currency payment.Currency) (float64, bool) {
+	if currency == t.Base {
+		return 1, true
+	}
+	units, ok := t.Rates[currency]
+	return units, ok && units > 0
+}
+
+// HTTP asks a Frankfurter-style API for the latest rate on every quote
+type HTTP struct {
+	// BaseURL defaults to FRANKFURTER_URL
+	BaseURL string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// latest is the response of GET /latest
+type latest struct {
+	Base    payment.Currency             `json:"base"`
+	Rates   map[payment.Currency]float64 `json:"rates"`
+	Message string                       `json:"message"`
+}
+
+// Rate fails with payment.ErrNoRate when the API does not know a currency
+func (h HTTP) Rate(ctx context.Context, from, to payment.Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	base := h.BaseURL
+	if base == "" {
+		base = FRANKFURTER_URL
+	}
+	query := url.Values{"from": {string(from)}, "to": {string(to)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/latest?"+query.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	var body latest
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil && resp.StatusCode < 300 {
+		return 0, fmt.Errorf("fx: decoding rates: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity:
+		return 0, fmt.Errorf("%w: %s to %s: %s", payment.ErrNoRate, from, to, body.Message)
+	case resp.StatusCode >= 300:
+		return 0, fmt.Errorf("fx: %s: %s", resp.Status, body.Message)
+	}
+	rate, ok := body.Rates[to]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("%w: %s to %s", payment.ErrNoRate, from, to)
+	}
+	return rate, nil
+}
+
+// Server answers GET /latest like the Frankfurter API with the rates of
+// Table, for demos and tests that run without network access
+type Server struct {
+	Table Table
+}
+
+func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet || r.URL.Path != "/latest" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(latest{Message: "not found"})
+		return
+	}
+	from := payment.Currency(r.URL.Query().Get("from"))
+	to := payment.Currency(r.URL.Query().Get("to"))
+	rate, err := s.Table.Rate(r.Context(), from, to)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(latest{Message: "not found"})
+		return
+	}
+	json.NewEncoder(w).Encode(latest{Base: from, Rates: map[payment.Currency]float64{to: rate}})
+}
@@ -0,0 +1,99 @@
+// Package iocontract checks custom io.Reader and io.Writer implementations
+// against the contracts documented on the interfaces, the way paymenttest
+// checks payment processors. Code written against io.Reader relies on those
+// rules, so a type that breaks them is no substitute for *os.File or
+// *bytes.Buffer however well it compiles.
+package iocontract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// MAX_EMPTY_READS is how many 0, nil reads in a row count as a reader that
+// never makes progress
+const MAX_EMPTY_READS = 100
+
+// CheckReader reads everything newReader(data) returns through buffers of
+// several sizes and fails on the first broken rule:
+//
+//   - n is never negative or larger than the buffer
+//   - the bytes read, including those returned with an error, are data
+//   - the stream ends with io.EOF, and keeps returning 0, io.EOF after it
+//   - the reader does not return 0, nil forever
+func CheckReader(newReader func(data []byte) io.Reader, data []byte) error {
+	for _, size := range []int{1, 3, 512, len(data) + 1} {
+		if err := checkRead(newReader(data), data, size); err != nil {
+			return fmt.Errorf("reading with a %d byte buffer: %w", size, err)
+		}
+	}
+	return nil
+}
+
+func checkRead(r io.Reader, data []byte, size int) error {
+	var got []byte
+	buf := make([]byte, size)
+	for empty := 0; ; {
+		n, err := r.Read(buf)
+		if n < 0 || n > len(buf) {
+			return fmt.Errorf("Read returned n = %d for a buffer of %d", n, len(buf))
+		}
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("Read returned error: %w", err)
+		}
+		if n == 0 {
+			if empty++; empty >= MAX_EMPTY_READS {
+				return fmt.Errorf("Read returned 0, nil %d times in a row", empty)
+			}
+		} else {
+			empty = 0
+		}
+	}
+	if !bytes.Equal(got, data) {
+		return fmt.Errorf("read %q, want %q", got, data)
+	}
+	if n, err := r.Read(buf); n != 0 || err != io.EOF {
+		return fmt.Errorf("Read after io.EOF = %d, %v, want 0, io.EOF", n, err)
+	}
+	return nil
+}
+
+// CheckWriter writes data through w and fails on the first broken rule:
+//
+//   - a short write returns an error
+//   - n is never negative or larger than the data
+//   - the data passed to Write is left unchanged
+//
+// written returns what reached the destination so far, it may be nil when
+// the destination cannot be inspected.
+func CheckWriter(w io.Writer, written func() []byte, data []byte) error {
+	var sent []byte
+	for chunk := range slices.Chunk(data, 7) {
+		before := bytes.Clone(chunk)
+		n, err := w.Write(chunk)
+		if n < 0 || n > len(chunk) {
+			return fmt.Errorf("Write of %d bytes returned n = %d", len(chunk), n)
+		}
+		if n < len(chunk) && err == nil {
+			return fmt.Errorf("Write of %d bytes wrote %d without an error", len(chunk), n)
+		}
+		if !bytes.Equal(chunk, before) {
+			return errors.New("Write modified the data it was given")
+		}
+		sent = append(sent, chunk[:n]...)
+		if err != nil {
+			return fmt.Errorf("Write returned error: %w", err)
+		}
+	}
+	if written != nil && !bytes.Equal(written(), sent) {
+		return fmt.Errorf("destination holds %q, want %q", written(), sent)
+	}
+	return nil
+}
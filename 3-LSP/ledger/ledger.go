@@ -0,0 +1,184 @@
+// Package ledger keeps an append-only record of every payment, refund and
+// void made through the processors it wraps, and reconciles the balance of
+// each processor from it.
+package ledger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// BALANCE_TOLERANCE absorbs float rounding when reconciling balances
+const BALANCE_TOLERANCE = 1e-9
+
+// ErrUnbalanced is returned when a processor's ledger balance differs from
+// the expected one
+var ErrUnbalanced = errors.New("ledger: balance does not reconcile")
+
+// Kind is what an entry records
+type Kind string
+
+const (
+	KindPayment Kind = "payment"
+	KindRefund  Kind = "refund"
+	KindVoid    Kind = "void"
+)
+
+// Entry is one line of the ledger. Refunds and voids take their currency
+// from the payment they belong to, voids their amount too.
+type Entry struct {
+	Kind      Kind             `json:"kind"`
+	Processor string           `json:"processor"`
+	PaymentID string           `json:"payment_id"`
+	Amount    float64          `json:"amount"`
+	Currency  payment.Currency `json:"currency"`
+	Time      time.Time        `json:"time"`
+}
+
+// Ledger stores entries in the order they were appended. Entries are never
+// changed or removed.
+type Ledger interface {
+	Append(ctx context.Context, entry Entry) error
+	Entries(ctx context.Context) ([]Entry, error)
+}
+
+// MemoryLedger keeps entries in memory. The zero value is ready to use.
+type MemoryLedger struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (m *MemoryLedger) Append(ctx context.Context, entry Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MemoryLedger) Entries(ctx context.Context) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.entries), nil
+}
+
+// FileLedger appends entries to Path as JSON lines
+type FileLedger struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (f *FileLedger) Append(ctx context.Context, entry Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Entries reads the whole file. A missing file is an empty ledger.
+func (f *FileLedger) Entries(ctx context.Context) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.Open(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", f.Path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Balances sums payments minus refunds and voids per processor and
+// currency. Refunds and voids of payments the ledger never saw are ignored.
+func Balances(ctx context.Context, l Ledger) (map[string]map[payment.Currency]float64, error) {
+	entries, err := l.Entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payments := make(map[string]Entry)
+	balances := make(map[string]map[payment.Currency]float64)
+	for _, e := range entries {
+		if balances[e.Processor] == nil {
+			balances[e.Processor] = make(map[payment.Currency]float64)
+		}
+		switch e.Kind {
+		case KindPayment:
+			payments[e.PaymentID] = e
+			balances[e.Processor][e.Currency] += e.Amount
+		case KindRefund:
+			if p, ok := payments[e.PaymentID]; ok {
+				balances[e.Processor][p.Currency] -= e.Amount
+			}
+		case KindVoid:
+			if p, ok := payments[e.PaymentID]; ok {
+				balances[e.Processor][p.Currency] -= p.Amount
+			}
+		}
+	}
+	return balances, nil
+}
+
+// Reconcile checks the ledger balance of processor against want, for
+// example the totals of its settlement batches
+func Reconcile(ctx context.Context, l Ledger, processor string, want map[payment.Currency]float64) error {
+	balances, err := Balances(ctx, l)
+	if err != nil {
+		return err
+	}
+	got := balances[processor]
+	for currency, amount := range want {
+		if math.Abs(got[currency]-amount) > BALANCE_TOLERANCE {
+			return fmt.Errorf("%w: %s has %f %s, want %f", ErrUnbalanced, processor, got[currency], currency, amount)
+		}
+	}
+	for currency, amount := range got {
+		if _, ok := want[currency]; !ok && math.Abs(amount) > BALANCE_TOLERANCE {
+			return fmt.Errorf("%w: %s has %f %s, want none", ErrUnbalanced, processor, amount, currency)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package ledger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/clock"
+)
+
+// Recorder appends what the processors it wraps do to Ledger. Idempotent
+// repeats of a payment, refund or void are recorded once.
+type Recorder struct {
+	Ledger Ledger
+	// Clock stamps the entries, it defaults to the real clock
+	Clock clock.Clock
+
+	mu       sync.Mutex
+	recorded map[string]bool
+	err      error
+}
+
+// Wrap returns p recording to the ledger under name. The result behaves
+// exactly like p otherwise and is a Voider only if p is.
+func (r *Recorder) Wrap(name string, p payment.PaymentProcessor) payment.PaymentProcessor {
+	wrapped := recording{PaymentProcessor: p, name: name, recorder: r}
+	if v, ok := p.(payment.Voider); ok {
+		return voidRecording{recording: wrapped, voider: v}
+	}
+	return wrapped
+}
+
+// Err returns the first entry the ledger failed to append. The operation it
+// describes still succeeded, so the error is not returned to the caller.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) append(ctx context.Context, entry Entry) {
+	key := string(entry.Kind) + "/" + entry.Processor + "/" + entry.PaymentID
+	r.mu.Lock()
+	if r.recorded[key] {
+		r.mu.Unlock()
+		return
+	}
+	if r.recorded == nil {
+		r.recorded = make(map[string]bool)
+	}
+	r.recorded[key] = true
+	r.mu.Unlock()
+
+	entry.Time = clock.Or(r.Clock).Now()
+	// the operation already happened, a canceled ctx must not lose its entry
+	if err := r.Ledger.Append(context.WithoutCancel(ctx), entry); err != nil {
+		r.mu.Lock()
+		if r.err == nil {
+			r.err = err
+		}
+		delete(r.recorded, key)
+		r.mu.Unlock()
+	}
+}
+
+// recording is the decorator returned by Recorder.Wrap
+type recording struct {
+	payment.PaymentProcessor
+	name     string
+	recorder *Recorder
+}
+
+func (r recording) ProcessPayment(ctx context.Context, amount float64, currency payment.Currency, idempotencyKey string) (payment.PaymentResult, error) {
+	result, err := r.PaymentProcessor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+	if err == nil {
+		r.recorder.append(ctx, Entry{Kind: KindPayment, Processor: r.name, PaymentID: result.ID, Amount: result.Amount, Currency: result.Currency})
+	}
+	return result, err
+}
+
+func (r recording) Refund(ctx context.Context, paymentID string, amount float64) error {
+	err := payment.Refund(ctx, r.PaymentProcessor, paymentID, amount)
+	if err == nil {
+		r.recorder.append(ctx, Entry{Kind: KindRefund, Processor: r.name, PaymentID: paymentID, Amount: amount})
+	}
+	return err
+}
+
+func (r recording) Unwrap() payment.PaymentProcessor {
+	return r.PaymentProcessor
+}
+
+type voidRecording struct {
+	recording
+	voider payment.Voider
+}
+
+func (v voidRecording) Void(ctx context.Context, paymentID string) error {
+	err := v.voider.Void(ctx, paymentID)
+	if err == nil {
+		v.recorder.append(ctx, Entry{Kind: KindVoid, Processor: v.name, PaymentID: paymentID})
+	}
+	return err
+}
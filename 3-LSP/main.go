@@ -1,34 +1,193 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"time"
 
-// Base interface
-type PaymentProcessor interface {
-	ProcessPayment(amount float64) string
-}
+	"github.com/imrancluster/go-solid/3-LSP/ledger"
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/settlement"
+	"github.com/imrancluster/go-solid/3-LSP/webhook"
+	"github.com/imrancluster/go-solid/clock"
+)
 
-// CashPayment implements the base interface
-type CashPayment struct{}
+func main() {
+	ctx := context.Background()
+	var clk clock.Clock = clock.Real{}
+	var paymentProcessor payment.PaymentProcessor
 
-func (c CashPayment) ProcessPayment(amount float64) string {
-	return fmt.Sprintf("Processing cash payment of %f", amount)
-}
+	// Using CashPayment
+	paymentProcessor = &payment.CashPayment{}
+	payAndRefund(ctx, paymentProcessor, 500, payment.USD)
 
-// CardPayment also implements the same interface
-type CardPayment struct{}
+	// Using CardPayment
+	paymentProcessor = &payment.CardPayment{}
+	payAndRefund(ctx, paymentProcessor, 1000, payment.EUR)
 
-func (c CardPayment) ProcessPayment(amount float64) string {
-	return fmt.Sprintf("Processing card payment of %f", amount)
-}
+	// Newer methods behave differently but honor the same contract
+	payAndRefund(ctx, &payment.BankTransferPayment{}, 200, payment.GBP)
+	payAndRefund(ctx, &payment.CryptoPayment{}, 0.5, payment.BTC)
+	payAndRefund(ctx, &payment.MobileWalletPayment{}, 200, payment.BDT)
 
-func main() {
-	var paymentProcessor PaymentProcessor
+	// Every processor rejects a currency it does not list the same way
+	payAndRefund(ctx, &payment.CryptoPayment{}, 200, payment.USD)
 
-	// Using CashPayment
-	paymentProcessor = CashPayment{}
-	fmt.Println(paymentProcessor.ProcessPayment(500))
+	// An async processor is pending at first, the adapter waits for completion
+	async := &payment.AsyncPayment{}
+	result, _ := async.ProcessPayment(ctx, 300, payment.USD, "")
+	fmt.Println(result, "is", result.Status)
+	payAndRefund(ctx, payment.SyncProcessor{Processor: async}, 300, payment.USD)
 
-	// Using CardPayment
-	paymentProcessor = CardPayment{}
-	fmt.Println(paymentProcessor.ProcessPayment(1000))
+	// A webhook confirms the async payment later, the merchant catches up
+	secret := []byte("demo-secret")
+	confirmed := make(chan webhook.Event, 1)
+	receiver := &webhook.Receiver{Secret: secret, OnEvent: func(e webhook.Event) { confirmed <- e }}
+	server := httptest.NewServer(receiver)
+	pending, _ := async.ProcessPayment(ctx, 75, payment.USD, "")
+	simulator := webhook.Simulator{Processor: async, URL: server.URL, Secret: secret}
+	go simulator.Watch(ctx, pending.ID)
+	event := <-confirmed
+	status, _ := receiver.Status(pending.ID)
+	fmt.Println("Webhook confirmed", event.PaymentID, "as", status)
+	server.Close()
+
+	// Optional capabilities are discovered, not assumed
+	for _, p := range []payment.PaymentProcessor{&payment.CardPayment{}, async} {
+		fmt.Printf("%T can %v\n", p, payment.Capabilities(p))
+	}
+	if capturer, ok := any(async).(payment.Capturer); ok {
+		result, _ := async.ProcessPayment(ctx, 120, payment.EUR, "")
+		captured, _ := capturer.Capture(ctx, result.ID)
+		fmt.Println(captured, "captured early, now", captured.Status)
+	}
+
+	// Card reserves funds first, cash is taken at once; both are Authorizers
+	for _, a := range []payment.Authorizer{&payment.CardPayment{}, &payment.CashPayment{}} {
+		authorized, _ := a.Authorize(ctx, 60, payment.USD, "")
+		captured, _ := a.Capture(ctx, authorized.ID)
+		fmt.Printf("%T authorized as %s, captured as %s\n", a, authorized.Status, captured.Status)
+	}
+	// Voiding releases an authorization, a captured payment needs a refund
+	card := &payment.CardPayment{}
+	held, _ := card.Authorize(ctx, 45, payment.USD, "")
+	fmt.Println("Void authorization:", card.Void(ctx, held.ID))
+	taken, _ := card.ProcessPayment(ctx, 45, payment.USD, "")
+	fmt.Println("Void captured payment:", card.Void(ctx, taken.ID))
+
+	// A frozen clock lets the hold lapse without waiting for it
+	frozen := clock.NewFrozen(clk.Now())
+	shortHold := &payment.CardPayment{AuthorizationTTL: time.Hour}
+	shortHold.Clock = frozen
+	authorized, _ := shortHold.Authorize(ctx, 60, payment.USD, "")
+	frozen.Advance(2 * time.Hour)
+	if _, err := shortHold.Capture(ctx, authorized.ID); err != nil {
+		fmt.Println("Late capture failed:", err)
+	}
+
+	// Installment plans work the same whichever processor pays them
+	plan, _ := payment.NewInstallmentPlan(900, payment.USD, 3)
+	wallet := &payment.MobileWalletPayment{}
+	for !plan.Complete() {
+		result, err := plan.PayNext(ctx, wallet, "plan-7")
+		if err != nil {
+			fmt.Println("Installment failed:", err)
+			break
+		}
+		fmt.Println(result, "-", plan.Remaining(), "remaining")
+	}
+
+	// Resubmitting with the same idempotency key does not charge twice
+	first, _ := card.ProcessPayment(ctx, 80, payment.USD, "order-42")
+	again, _ := card.ProcessPayment(ctx, 80, payment.USD, "order-42")
+	fmt.Println("Resubmitted order-42 returned payment", again.ID, "same as", first.ID)
+
+	// Settlement batches the day's payments of every processor alike, and
+	// the ledger records payments and refunds of every processor alike
+	var collector settlement.Collector
+	books := ledger.Recorder{Ledger: &ledger.MemoryLedger{}, Clock: clk}
+	cashDesk := books.Wrap("cash", collector.Wrap("cash", &payment.CashPayment{}))
+	terminal := books.Wrap("card", collector.Wrap("card", &payment.CardPayment{}))
+	cashDesk.ProcessPayment(ctx, 20, payment.USD, "")
+	terminal.ProcessPayment(ctx, 35, payment.USD, "")
+	terminal.ProcessPayment(ctx, 15, payment.EUR, "")
+	today := clk.Now()
+	collector.SettleDay(ctx, today, settlement.SummarySettler{W: os.Stdout})
+	for _, batch := range collector.Batches(today) {
+		fmt.Println("Ledger reconciles with", batch.Processor, "settlement:", ledger.Reconcile(ctx, books.Ledger, batch.Processor, batch.Totals()) == nil)
+	}
+
+	// Gift cards decline what their balance cannot cover unless a partial
+	// capture was asked for
+	gift := payment.NewGiftCardPayment("GIFT-42", 50)
+	if _, err := gift.ProcessPayment(ctx, 80, payment.USD, ""); err != nil {
+		fmt.Println("Gift card payment failed:", err)
+	}
+	gift.AllowPartial = true
+	if partial, err := gift.ProcessPayment(ctx, 80, payment.USD, ""); err == nil {
+		fmt.Printf("Gift card covered %f of %f %s\n", partial.Amount, partial.Requested, partial.Currency)
+	}
+
+	// Large card payments need strong customer authentication; the same
+	// call works with processors that never ask for it
+	customer := payment.ChallengeResolverFunc(func(ctx context.Context, c payment.Challenge) (string, error) {
+		fmt.Printf("Customer asked to %s for %f %s\n", c.Prompt, c.Amount, c.Currency)
+		return payment.DEFAULT_CHALLENGE_CODE, nil
+	})
+	for _, p := range []payment.PaymentProcessor{&payment.CardPayment{ChallengeAbove: 500}, &payment.CashPayment{}} {
+		if result, err := payment.ProcessWithChallenge(ctx, p, 800, payment.USD, "", customer); err == nil {
+			fmt.Println(result, "is", result.Status)
+		}
+	}
+
+	// A flaky gateway is just another processor: declines carry a reason,
+	// transient failures are hidden by retrying with the same key
+	flaky := &payment.SimulatedProcessor{DeclineRate: 0.2, TransientRate: 0.3, Latency: time.Millisecond, Seed: 7}
+	for i := 1; i <= 5; i++ {
+		key := fmt.Sprintf("sim-%d", i)
+		result, err := payment.RetryingProcessor{Processor: flaky}.ProcessPayment(ctx, 10, payment.USD, key)
+		if err != nil {
+			fmt.Println("Simulated payment", key, "failed:", err)
+			continue
+		}
+		fmt.Println("Simulated payment", key, "processed as", result.ID)
+	}
+
+	// Callers on the v2 contract use existing processors through the adapter
+	v2 := payment.V2(&payment.CardPayment{})
+	paid, _ := v2.Pay(ctx, payment.PaymentRequest{Amount: 99, Currency: payment.GBP, IdempotencyKey: "v2-1"})
+	refund, _ := v2.Refund(ctx, payment.RefundRequest{PaymentID: paid.ID, Amount: 99})
+	fmt.Println("v2:", paid, "- refunded", refund.Amount)
+
+	// Old callers keep the string based interface through the adapter
+	var legacy payment.LegacyProcessor = payment.Legacy{Processor: &payment.CashPayment{}}
+	fmt.Println(legacy.ProcessPayment(750))
+}
+
+// payAndRefund works with any PaymentProcessor without knowing the concrete type.
+// It only relies on the contract the processors check with contract.Requires
+// and contract.Ensures: positive amounts in a listed currency are accepted and
+// refunds never exceed the captured amount. Processors that cannot refund
+// are told apart by the Refunder capability, not by a surprise failure.
+func payAndRefund(ctx context.Context, p payment.PaymentProcessor, amount float64, currency payment.Currency) {
+	result, err := p.ProcessPayment(ctx, amount, currency, "")
+	if err != nil {
+		fmt.Println("Payment failed:", err)
+		return
+	}
+	fmt.Println(result)
+	fmt.Printf("Gross %f, fee %f, net %f %s\n", result.Amount, result.Fee, result.Net, result.Currency)
+
+	refunder, ok := p.(payment.Refunder)
+	if !ok {
+		fmt.Printf("Payment %s cannot be refunded\n", result.ID)
+		return
+	}
+	if err := refunder.Refund(ctx, result.ID, amount/2); err != nil {
+		fmt.Println("Refund failed:", err)
+		return
+	}
+	fmt.Printf("Refunded %f of payment %s\n", amount/2, result.ID)
 }
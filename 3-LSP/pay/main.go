@@ -0,0 +1,56 @@
+// Command pay makes one payment through any registered processor and prints
+// the result, for demos and for poking at the contract by hand.
+//
+// Run it with: go run ./3-LSP/pay -processor card -amount 100 -currency USD -key order-1
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+func main() {
+	name := flag.String("processor", "card", "registered processor: "+strings.Join(payment.Names(), ", "))
+	amount := flag.Float64("amount", 0, "amount to pay")
+	currency := flag.String("currency", "", "currency code, defaults to the first one the processor accepts")
+	key := flag.String("key", "", "idempotency key")
+	flag.Parse()
+
+	p, err := payment.New(*name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *currency == "" {
+		if currencies := p.Currencies(); len(currencies) > 0 {
+			*currency = string(currencies[0])
+		}
+	}
+
+	result, err := p.ProcessPayment(context.Background(), *amount, payment.Currency(*currency), *key)
+	out := struct {
+		Processor    string                 `json:"processor"`
+		Capabilities []payment.Capability   `json:"capabilities"`
+		Currencies   []payment.Currency     `json:"currencies"`
+		Result       *payment.PaymentResult `json:"result,omitempty"`
+		Error        string                 `json:"error,omitempty"`
+	}{Processor: *name, Capabilities: payment.Capabilities(p), Currencies: p.Currencies()}
+	if err != nil {
+		out.Error = err.Error()
+	} else {
+		out.Result = &result
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(out)
+	if err != nil {
+		os.Exit(1)
+	}
+}
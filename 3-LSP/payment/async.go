@@ -0,0 +1,157 @@
+package payment
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_ASYNC_DELAY   = 50 * time.Millisecond
+	DEFAULT_POLL_INTERVAL = 10 * time.Millisecond
+)
+
+// AsyncProcessor accepts payments that complete later and can be polled
+type AsyncProcessor interface {
+	PaymentProcessor
+	StatusChecker
+}
+
+// AsyncPayment returns pending results that are captured after Delay
+type AsyncPayment struct {
+	book
+	// Delay defaults to DEFAULT_ASYNC_DELAY
+	Delay time.Duration
+	// Idempotency defaults to an in-memory store
+	Idempotency IdempotencyStore
+
+	mu         sync.Mutex
+	completeAt map[string]time.Time
+}
+
+func (a *AsyncPayment) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := validatePayment(ctx, amount, currency, asyncCurrencies); err != nil {
+		return PaymentResult{}, err
+	}
+	return a.idempotent(a.Idempotency, idempotencyKey, amount, currency, func() (PaymentResult, error) {
+		result := a.record("async", "async", amount, currency, asyncFees, StatusPending).result
+
+		delay := a.Delay
+		if delay <= 0 {
+			delay = DEFAULT_ASYNC_DELAY
+		}
+		a.mu.Lock()
+		if a.completeAt == nil {
+			a.completeAt = make(map[string]time.Time)
+		}
+		a.completeAt[result.ID] = result.Timestamp.Add(delay)
+		a.mu.Unlock()
+
+		return ensureResult(result, amount, currency)
+	})
+}
+
+func (a *AsyncPayment) Currencies() []Currency {
+	return slices.Clone(asyncCurrencies)
+}
+
+// Status captures payments whose Delay has passed before reporting them
+func (a *AsyncPayment) Status(ctx context.Context, paymentID string) (Status, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	due, ok := a.due(paymentID)
+	if !ok {
+		return "", ErrPaymentNotFound
+	}
+	result, err := a.lookup(paymentID)
+	if err == nil && due && result.Status == StatusPending {
+		result, err = a.captureHeld(ctx, paymentID)
+	}
+	return result.Status, err
+}
+
+// Capture completes a pending payment now instead of waiting for Delay
+func (a *AsyncPayment) Capture(ctx context.Context, paymentID string) (PaymentResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PaymentResult{}, err
+	}
+	if _, ok := a.due(paymentID); !ok {
+		return PaymentResult{}, ErrPaymentNotFound
+	}
+	a.mu.Lock()
+	if now := a.now(); now.Before(a.completeAt[paymentID]) {
+		a.completeAt[paymentID] = now
+	}
+	a.mu.Unlock()
+	return a.captureHeld(ctx, paymentID)
+}
+
+// Refund fails with ErrNotCaptured while a payment is pending. A payment
+// whose Delay has passed is captured first, as Status would.
+func (a *AsyncPayment) Refund(ctx context.Context, paymentID string, amount float64) error {
+	if err := validateAmount(amount); err != nil {
+		return err
+	}
+	if due, _ := a.due(paymentID); due {
+		if _, err := a.captureHeld(ctx, paymentID); err != nil {
+			return err
+		}
+	}
+	_, _, err := a.refund(ctx, paymentID, amount)
+	return err
+}
+
+// due reports whether the Delay of a known payment has passed
+func (a *AsyncPayment) due(paymentID string) (due, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	completeAt, ok := a.completeAt[paymentID]
+	return ok && !a.now().Before(completeAt), ok
+}
+
+// SyncProcessor blocks until an async payment completes, so callers that
+// expect captured results can use an AsyncProcessor unchanged.
+type SyncProcessor struct {
+	Processor AsyncProcessor
+	// PollInterval defaults to DEFAULT_POLL_INTERVAL
+	PollInterval time.Duration
+}
+
+func (s SyncProcessor) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	result, err := s.Processor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = DEFAULT_POLL_INTERVAL
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for result.Status == StatusPending {
+		select {
+		case <-ctx.Done():
+			return PaymentResult{}, ctx.Err()
+		case <-ticker.C:
+		}
+		if result.Status, err = s.Processor.Status(ctx, result.ID); err != nil {
+			return PaymentResult{}, err
+		}
+	}
+	return result, nil
+}
+
+func (s SyncProcessor) Refund(ctx context.Context, paymentID string, amount float64) error {
+	return Refund(ctx, s.Processor, paymentID, amount)
+}
+
+func (s SyncProcessor) Currencies() []Currency {
+	return s.Processor.Currencies()
+}
+
+func (s SyncProcessor) Unwrap() PaymentProcessor {
+	return s.Processor
+}
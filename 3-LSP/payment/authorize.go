@@ -0,0 +1,56 @@
+package payment
+
+import (
+	"context"
+	"time"
+)
+
+const DEFAULT_AUTHORIZATION_TTL = 7 * 24 * time.Hour
+
+// Authorizer reserves funds first and captures them later. Authorize
+// returns an authorized or an already captured result; Capture turns an
+// authorization into a captured payment until it expires, after which it
+// fails with ErrAuthorizationExpired. Authorizations cannot be refunded
+// before they are captured.
+type Authorizer interface {
+	Capturer
+	Authorize(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error)
+}
+
+// Authorize reserves amount on the card until AuthorizationTTL passes.
+// Amounts above ChallengeAbove ask for authentication first, as payments
+// do; completing the challenge reserves the amount.
+func (c *CardPayment) Authorize(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := validatePayment(ctx, amount, currency, cardCurrencies); err != nil {
+		return PaymentResult{}, err
+	}
+	return c.idempotent(c.Idempotency, idempotencyKey, amount, currency, func() (PaymentResult, error) {
+		if c.ChallengeAbove > 0 && amount > c.ChallengeAbove {
+			return PaymentResult{}, c.challenges.issue("card", amount, currency, idempotencyKey, true)
+		}
+		return c.reserve(amount, currency)
+	})
+}
+
+// reserve authorizes amount for AuthorizationTTL
+func (c *CardPayment) reserve(amount float64, currency Currency) (PaymentResult, error) {
+	ttl := c.AuthorizationTTL
+	if ttl <= 0 {
+		ttl = DEFAULT_AUTHORIZATION_TTL
+	}
+	return ensureResult(c.authorize("card", "card", amount, currency, cardFees, ttl), amount, currency)
+}
+
+func (c *CardPayment) Capture(ctx context.Context, paymentID string) (PaymentResult, error) {
+	return c.captureHeld(ctx, paymentID)
+}
+
+// Authorize takes the cash right away: there is nothing to reserve, so the
+// result is already captured and Capture has nothing left to do
+func (c *CashPayment) Authorize(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	return c.ProcessPayment(ctx, amount, currency, idempotencyKey)
+}
+
+func (c *CashPayment) Capture(ctx context.Context, paymentID string) (PaymentResult, error) {
+	return c.captureHeld(ctx, paymentID)
+}
@@ -0,0 +1,188 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/contract"
+	"github.com/imrancluster/go-solid/clock"
+)
+
+// book keeps the payments of a processor and their refunds so every
+// processor shares the same semantics. The zero value is ready to use.
+// Processors that refund embed refundableBook to export Refund.
+type book struct {
+	// Clock timestamps payments and expires authorizations, it defaults to
+	// the real clock
+	Clock clock.Clock
+
+	mu       sync.Mutex
+	seq      int
+	payments map[string]*entry
+
+	keyMu sync.Mutex
+	keys  MemoryIdempotencyStore
+}
+
+type entry struct {
+	result PaymentResult
+	// expires is when an authorization lapses
+	expires  time.Time
+	refunded float64
+}
+
+// transition moves the entry to next if its lifecycle allows it
+func (e *entry) transition(next Status) error {
+	status, err := Transition(e.result.Status, next)
+	e.result.Status = status
+	return err
+}
+
+// now reads the clock of the book
+func (b *book) now() time.Time {
+	return clock.Or(b.Clock).Now()
+}
+
+func (b *book) record(prefix, method string, amount float64, currency Currency, fees FeeSchedule, status Status) *entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.payments == nil {
+		b.payments = make(map[string]*entry)
+	}
+	b.seq++
+	fee := fees.Fee(amount)
+	e := &entry{result: PaymentResult{
+		ID:        fmt.Sprintf("%s-%d", prefix, b.seq),
+		Method:    method,
+		Amount:    amount,
+		Fee:       fee,
+		Net:       amount - fee,
+		Currency:  currency,
+		Status:    status,
+		Timestamp: b.now(),
+	}}
+	b.payments[e.result.ID] = e
+	return e
+}
+
+// capture records amount and returns a captured result with an ID built
+// from prefix and the fee from fees
+func (b *book) capture(prefix, method string, amount float64, currency Currency, fees FeeSchedule) PaymentResult {
+	return b.record(prefix, method, amount, currency, fees, StatusCaptured).result
+}
+
+// authorize records amount as reserved until ttl passes
+func (b *book) authorize(prefix, method string, amount float64, currency Currency, fees FeeSchedule, ttl time.Duration) PaymentResult {
+	e := b.record(prefix, method, amount, currency, fees, StatusAuthorized)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e.expires = e.result.Timestamp.Add(ttl)
+	return e.result
+}
+
+// lookup returns the recorded result of a payment
+func (b *book) lookup(paymentID string) (PaymentResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.payments[paymentID]
+	if !ok {
+		return PaymentResult{}, ErrPaymentNotFound
+	}
+	return e.result, nil
+}
+
+// captureHeld captures an authorized or pending payment. Payments that are
+// captured already are returned unchanged, so capturing twice is safe.
+func (b *book) captureHeld(ctx context.Context, paymentID string) (PaymentResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PaymentResult{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.payments[paymentID]
+	if !ok {
+		return PaymentResult{}, ErrPaymentNotFound
+	}
+	switch e.result.Status {
+	case StatusVoided:
+		return PaymentResult{}, ErrPaymentVoided
+	case StatusAuthorized:
+		if b.now().After(e.expires) {
+			return PaymentResult{}, ErrAuthorizationExpired
+		}
+		fallthrough
+	case StatusPending:
+		if err := e.transition(StatusCaptured); err != nil {
+			return PaymentResult{}, err
+		}
+	}
+	return e.result, nil
+}
+
+// refundableBook is a book whose processor is a Refunder
+type refundableBook struct {
+	book
+}
+
+func (b *refundableBook) Refund(ctx context.Context, paymentID string, amount float64) error {
+	_, _, err := b.refund(ctx, paymentID, amount)
+	return err
+}
+
+// refund records a refund and reports whether it repeated an earlier one
+func (b *book) refund(ctx context.Context, paymentID string, amount float64) (PaymentResult, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return PaymentResult{}, false, err
+	}
+	if err := validateAmount(amount); err != nil {
+		return PaymentResult{}, false, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.payments[paymentID]
+	if !ok {
+		return PaymentResult{}, false, ErrPaymentNotFound
+	}
+	switch e.result.Status {
+	case StatusVoided:
+		return PaymentResult{}, false, ErrPaymentVoided
+	case StatusAuthorized, StatusPending:
+		return PaymentResult{}, false, ErrNotCaptured
+	}
+	if e.refunded > 0 {
+		if e.refunded == amount {
+			return e.result, true, nil
+		}
+		return PaymentResult{}, false, ErrAlreadyRefunded
+	}
+	if err := contract.Requires(amount <= e.result.Amount, ErrRefundExceedsCapture); err != nil {
+		return PaymentResult{}, false, err
+	}
+	if err := e.transition(StatusRefunded); err != nil {
+		return PaymentResult{}, false, err
+	}
+	e.refunded = amount
+	return e.result, false, nil
+}
+
+func (b *book) Void(ctx context.Context, paymentID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.payments[paymentID]
+	if !ok {
+		return ErrPaymentNotFound
+	}
+	switch e.result.Status {
+	case StatusCaptured, StatusRefunded:
+		return ErrAlreadyCaptured
+	case StatusVoided:
+		return nil
+	default:
+		return e.transition(StatusVoided)
+	}
+}
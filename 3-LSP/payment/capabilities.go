@@ -0,0 +1,121 @@
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnsupported is returned by wrappers and adapters asked for a
+// capability the processor they wrap does not have
+var ErrUnsupported = errors.New("payment: operation not supported")
+
+// Capabilities beyond ProcessPayment are small interfaces of their own.
+// Callers discover them with a type assertion, so adding one never changes
+// what the base PaymentProcessor contract promises.
+
+// Refunder returns up to the captured amount of a payment. A payment is
+// refunded at most once: repeating the same refund is a no-op, a different
+// amount fails with ErrAlreadyRefunded. Authorized and pending payments
+// hold no captured funds yet and fail with ErrNotCaptured.
+type Refunder interface {
+	Refund(ctx context.Context, paymentID string, amount float64) error
+}
+
+// Refund refunds through p if it is a Refunder and fails with
+// ErrUnsupported otherwise. Decorators use it to forward refunds, which
+// makes a decorated processor that cannot refund say so for every payment.
+func Refund(ctx context.Context, p PaymentProcessor, paymentID string, amount float64) error {
+	r, ok := p.(Refunder)
+	if !ok {
+		return ErrUnsupported
+	}
+	return r.Refund(ctx, paymentID, amount)
+}
+
+// Unwrapper is implemented by decorators that forward refunds with Refund,
+// Unwrap returns the processor they decorate
+type Unwrapper interface {
+	Unwrap() PaymentProcessor
+}
+
+// CanRefund reports whether refunds through p reach a Refunder. Decorators
+// always have Refund, so they can only refund if what they wrap can.
+func CanRefund(p PaymentProcessor) bool {
+	for {
+		if _, ok := p.(Refunder); !ok {
+			return false
+		}
+		u, ok := p.(Unwrapper)
+		if !ok {
+			return true
+		}
+		p = u.Unwrap()
+	}
+}
+
+// Capturer completes a pending or authorized payment on demand. Capturing a
+// captured payment returns it unchanged, unknown IDs fail with
+// ErrPaymentNotFound.
+type Capturer interface {
+	Capture(ctx context.Context, paymentID string) (PaymentResult, error)
+}
+
+// Voider cancels a payment whose funds were not captured yet, an
+// authorization or a pending payment. Voiding a voided payment is a no-op;
+// a captured or refunded payment fails with ErrAlreadyCaptured and has to
+// be refunded instead. Voided payments cannot be captured or refunded.
+type Voider interface {
+	Void(ctx context.Context, paymentID string) error
+}
+
+// StatusChecker reports the current status of a payment
+type StatusChecker interface {
+	Status(ctx context.Context, paymentID string) (Status, error)
+}
+
+// Capability names an optional interface a processor implements
+type Capability string
+
+const (
+	CapabilityRefund    Capability = "refund"
+	CapabilityCapture   Capability = "capture"
+	CapabilityAuthorize Capability = "authorize"
+	CapabilityVoid      Capability = "void"
+	CapabilityStatus    Capability = "status"
+	CapabilityFees      Capability = "fees"
+	CapabilityBalance   Capability = "balance"
+	CapabilityChallenge Capability = "challenge"
+)
+
+// Capabilities reports the optional interfaces p implements. Decorators
+// always have Refund, so for them refund is only a capability if the
+// processor they wrap has it too, see CanRefund.
+func Capabilities(p PaymentProcessor) []Capability {
+	var capabilities []Capability
+	var processor any = p
+	if CanRefund(p) {
+		capabilities = append(capabilities, CapabilityRefund)
+	}
+	if _, ok := processor.(Capturer); ok {
+		capabilities = append(capabilities, CapabilityCapture)
+	}
+	if _, ok := processor.(Authorizer); ok {
+		capabilities = append(capabilities, CapabilityAuthorize)
+	}
+	if _, ok := processor.(Voider); ok {
+		capabilities = append(capabilities, CapabilityVoid)
+	}
+	if _, ok := processor.(StatusChecker); ok {
+		capabilities = append(capabilities, CapabilityStatus)
+	}
+	if _, ok := processor.(FeeReporter); ok {
+		capabilities = append(capabilities, CapabilityFees)
+	}
+	if _, ok := processor.(BalanceChecker); ok {
+		capabilities = append(capabilities, CapabilityBalance)
+	}
+	if _, ok := processor.(ChallengeCompleter); ok {
+		capabilities = append(capabilities, CapabilityChallenge)
+	}
+	return capabilities
+}
@@ -0,0 +1,92 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+)
+
+// contextChecks are the checks of the suite that cover cancellation and
+// deadlines
+var contextChecks = []string{"ReturnsOnCancellation", "ReturnsOnDeadline", "CanceledPaymentsAreNotRemembered"}
+
+// TestProcessorsHonorContext runs the context checks against every
+// registered processor
+func TestProcessorsHonorContext(t *testing.T) {
+	for _, name := range payment.Names() {
+		t.Run(name, func(t *testing.T) {
+			for _, err := range verifyContext(t, name, newProcessor(t, name)) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestContextChecksCatchIgnoredContext makes sure the checks fail a
+// processor that drops the context its callers pass
+func TestContextChecksCatchIgnoredContext(t *testing.T) {
+	errs := verifyContext(t, "background(cash)", func() payment.PaymentProcessor {
+		return background{&payment.CashPayment{}}
+	})
+	if len(errs) != len(contextChecks) {
+		t.Errorf("a processor ignoring its context failed %d of %d context checks: %v", len(errs), len(contextChecks), errs)
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, name := range payment.Names() {
+		p := newProcessor(t, name)()
+		if _, err := p.ProcessPayment(ctx, 100, p.Currencies()[0], ""); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: ProcessPayment with a canceled context error = %v, want %v", name, err, context.Canceled)
+		}
+	}
+}
+
+// verifyContext runs the context checks and returns their failures
+func verifyContext(t *testing.T, name string, newProcessor func() payment.PaymentProcessor) []error {
+	t.Helper()
+	var errs []error
+	var ran int
+	for _, result := range paymenttest.Verify(newProcessor) {
+		if !slices.Contains(contextChecks, result.Check.Name) {
+			continue
+		}
+		ran++
+		if result.Err != nil && !errors.Is(result.Err, paymenttest.ErrNotApplicable) {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", name, result.Check.Name, result.Err))
+		}
+	}
+	if ran != len(contextChecks) {
+		t.Fatalf("the suite ran %d context checks, want %s", ran, strings.Join(contextChecks, ", "))
+	}
+	return errs
+}
+
+func newProcessor(t *testing.T, name string) func() payment.PaymentProcessor {
+	t.Helper()
+	if _, err := payment.New(name); err != nil {
+		t.Fatal(err)
+	}
+	return func() payment.PaymentProcessor {
+		p, _ := payment.New(name)
+		return p
+	}
+}
+
+// background processes every payment with a background context, whatever
+// context it was given
+type background struct {
+	payment.PaymentProcessor
+}
+
+func (b background) ProcessPayment(_ context.Context, amount float64, currency payment.Currency, idempotencyKey string) (payment.PaymentResult, error) {
+	return b.PaymentProcessor.ProcessPayment(context.Background(), amount, currency, idempotencyKey)
+}
@@ -0,0 +1,49 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/imrancluster/go-solid/3-LSP/contract"
+)
+
+// ErrUnsupportedCurrency is returned by every processor for a currency it does not accept
+var ErrUnsupportedCurrency = errors.New("payment: unsupported currency")
+
+// Currency is an ISO 4217 style currency code
+type Currency string
+
+const (
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	BDT Currency = "BDT"
+	BTC Currency = "BTC"
+	ETH Currency = "ETH"
+)
+
+// Currencies each processor accepts
+var (
+	cashCurrencies      = []Currency{USD, EUR, GBP, BDT}
+	cardCurrencies      = []Currency{USD, EUR, GBP}
+	bankCurrencies      = []Currency{USD, EUR, GBP, BDT}
+	cryptoCurrencies    = []Currency{BTC, ETH}
+	walletCurrencies    = []Currency{USD, BDT}
+	asyncCurrencies     = []Currency{USD, EUR}
+	giftCardCurrencies  = []Currency{USD, EUR, GBP}
+	simulatedCurrencies = []Currency{USD, EUR, GBP, BDT}
+)
+
+// validatePayment is the precondition shared by every processor: a live
+// context first, then a valid amount, then a currency from the processor's
+// supported set
+func validatePayment(ctx context.Context, amount float64, currency Currency, supported []Currency) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateAmount(amount); err != nil {
+		return err
+	}
+	return contract.Requires(slices.Contains(supported, currency), ErrUnsupportedCurrency)
+}
@@ -0,0 +1,121 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	DEFAULT_RETRY_ATTEMPTS = 3
+	DEFAULT_RETRY_BACKOFF  = 10 * time.Millisecond
+)
+
+// ErrTransient marks failures that may succeed when tried again
+var ErrTransient = errors.New("payment: transient failure")
+
+// LoggingProcessor logs every call to the wrapped processor and otherwise
+// behaves exactly like it
+type LoggingProcessor struct {
+	Processor PaymentProcessor
+	// Logger defaults to log.Default()
+	Logger *log.Logger
+}
+
+func (l LoggingProcessor) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	result, err := l.Processor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+	if err != nil {
+		l.logger().Printf("payment of %f %s (key %q) failed: %v", amount, currency, idempotencyKey, err)
+	} else {
+		l.logger().Printf("payment of %f %s (key %q) processed as %s: %s", amount, currency, idempotencyKey, result.ID, result.Status)
+	}
+	return result, err
+}
+
+func (l LoggingProcessor) Refund(ctx context.Context, paymentID string, amount float64) error {
+	err := Refund(ctx, l.Processor, paymentID, amount)
+	if err != nil {
+		l.logger().Printf("refund of %f for %s failed: %v", amount, paymentID, err)
+	} else {
+		l.logger().Printf("refunded %f for %s", amount, paymentID)
+	}
+	return err
+}
+
+func (l LoggingProcessor) Currencies() []Currency {
+	return l.Processor.Currencies()
+}
+
+func (l LoggingProcessor) Unwrap() PaymentProcessor {
+	return l.Processor
+}
+
+func (l LoggingProcessor) logger() *log.Logger {
+	if l.Logger == nil {
+		return log.Default()
+	}
+	return l.Logger
+}
+
+// RetryingProcessor retries calls that fail with ErrTransient. Payments are
+// retried with the same idempotency key, so only keyed payments are safe
+// from being charged twice.
+type RetryingProcessor struct {
+	Processor PaymentProcessor
+	// Attempts defaults to DEFAULT_RETRY_ATTEMPTS
+	Attempts int
+	// Backoff is the wait before the first retry and doubles after each,
+	// it defaults to DEFAULT_RETRY_BACKOFF
+	Backoff time.Duration
+}
+
+func (r RetryingProcessor) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	var result PaymentResult
+	err := r.retry(ctx, func() (err error) {
+		result, err = r.Processor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+		return err
+	})
+	return result, err
+}
+
+func (r RetryingProcessor) Refund(ctx context.Context, paymentID string, amount float64) error {
+	return r.retry(ctx, func() error {
+		return Refund(ctx, r.Processor, paymentID, amount)
+	})
+}
+
+func (r RetryingProcessor) Currencies() []Currency {
+	return r.Processor.Currencies()
+}
+
+func (r RetryingProcessor) Unwrap() PaymentProcessor {
+	return r.Processor
+}
+
+func (r RetryingProcessor) retry(ctx context.Context, call func() error) error {
+	attempts := r.Attempts
+	if attempts <= 0 {
+		attempts = DEFAULT_RETRY_ATTEMPTS
+	}
+	backoff := r.Backoff
+	if backoff <= 0 {
+		backoff = DEFAULT_RETRY_BACKOFF
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		if err = call(); !errors.Is(err, ErrTransient) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package payment_test
+
+import (
+	"io"
+	"log"
+	"slices"
+	"testing"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+)
+
+// TestDecoratorsConform runs the contract against every processor behind
+// each decorator, so decorating a processor is proven not to break it
+func TestDecoratorsConform(t *testing.T) {
+	quiet := log.New(io.Discard, "", 0)
+	decorators := []struct {
+		name string
+		wrap func(p payment.PaymentProcessor) payment.PaymentProcessor
+	}{
+		{"logging", func(p payment.PaymentProcessor) payment.PaymentProcessor {
+			return payment.LoggingProcessor{Processor: p, Logger: quiet}
+		}},
+		{"retrying", func(p payment.PaymentProcessor) payment.PaymentProcessor {
+			return payment.RetryingProcessor{Processor: p}
+		}},
+	}
+	for _, decorator := range decorators {
+		for _, name := range []string{"cash", "card", "crypto"} {
+			t.Run(decorator.name+"("+name+")", func(t *testing.T) {
+				paymenttest.RunProcessorSuite(t, func() payment.PaymentProcessor {
+					p, err := payment.New(name)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return decorator.wrap(p)
+				})
+			})
+		}
+	}
+}
+
+// TestV2RoundTripConforms runs the contract against every registered
+// processor lifted to ProcessorV2 and lowered back again
+func TestV2RoundTripConforms(t *testing.T) {
+	for _, name := range payment.Names() {
+		t.Run("v1(v2("+name+"))", func(t *testing.T) {
+			paymenttest.RunProcessorSuite(t, func() payment.PaymentProcessor {
+				p, err := payment.New(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return payment.V1(payment.V2(p))
+			})
+		})
+	}
+}
+
+// TestDecoratorCapabilities makes sure a decorator or adapter only reports
+// refunds when the processor it wraps can refund, however deep it is nested
+func TestDecoratorCapabilities(t *testing.T) {
+	quiet := log.New(io.Discard, "", 0)
+	tests := []struct {
+		processor payment.PaymentProcessor
+		canRefund bool
+	}{
+		{&payment.CardPayment{}, true},
+		{&payment.CryptoPayment{}, false},
+		{payment.LoggingProcessor{Processor: &payment.CardPayment{}, Logger: quiet}, true},
+		{payment.LoggingProcessor{Processor: &payment.CryptoPayment{}, Logger: quiet}, false},
+		{payment.RetryingProcessor{Processor: &payment.LockedProcessor{Processor: &payment.CashPayment{}}}, true},
+		{payment.RetryingProcessor{Processor: &payment.LockedProcessor{Processor: &payment.CryptoPayment{}}}, false},
+		{payment.V1(payment.V2(&payment.CardPayment{})), true},
+		{payment.V1(payment.V2(&payment.CryptoPayment{})), false},
+		{payment.LoggingProcessor{Processor: payment.V1(payment.V2(&payment.CryptoPayment{})), Logger: quiet}, false},
+	}
+	for _, tt := range tests {
+		if got := payment.CanRefund(tt.processor); got != tt.canRefund {
+			t.Errorf("CanRefund(%T) = %v, want %v", tt.processor, got, tt.canRefund)
+		}
+		if got := slices.Contains(payment.Capabilities(tt.processor), payment.CapabilityRefund); got != tt.canRefund {
+			t.Errorf("Capabilities(%T) has refund = %v, want %v", tt.processor, got, tt.canRefund)
+		}
+	}
+}
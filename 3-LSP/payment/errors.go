@@ -0,0 +1,21 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Failures every processor reports with the same sentinel, so callers can
+// handle them with errors.Is whatever processor they hold. Together with
+// ErrInvalidAmount and ErrUnsupportedCurrency they cover why a valid call
+// to ProcessPayment can fail.
+var (
+	// ErrDeclined is returned when the method refuses a payment. Nothing is
+	// captured; retrying the same payment fails the same way.
+	ErrDeclined = errors.New("payment: declined")
+	// ErrInsufficientFunds is returned when the funds behind the method
+	// cannot cover a payment. It is an ErrDeclined.
+	ErrInsufficientFunds = fmt.Errorf("%w: insufficient funds", ErrDeclined)
+	// ErrDuplicate is returned when a payment collides with one made before
+	ErrDuplicate = errors.New("payment: duplicate payment")
+)
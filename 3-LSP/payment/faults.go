@@ -0,0 +1,99 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+// Fault is a failure a FaultyProcessor injects into one call
+type Fault string
+
+const (
+	FaultNone Fault = ""
+	// FaultTimeout processes the payment but loses the response, the
+	// caller sees ErrTransient
+	FaultTimeout Fault = "timeout"
+	// FaultTransient fails with ErrTransient without processing anything
+	FaultTransient Fault = "transient"
+	// FaultDuplicate delivers the request twice and returns the second
+	// response, like a network that resent it
+	FaultDuplicate Fault = "duplicate"
+)
+
+// FaultyProcessor injects faults into the payments of the wrapped
+// processor, for checking that the layers above it cope: retries have to
+// get past timeouts and transient failures, idempotency keys have to keep
+// timed out and duplicated requests from charging twice. Refunds pass
+// through unharmed. Use it through a pointer.
+type FaultyProcessor struct {
+	Processor PaymentProcessor
+	// Schedule lists the fault of each call in turn; once it runs out the
+	// rates below decide
+	Schedule []Fault
+	// TimeoutRate, TransientRate and DuplicateRate are the shares of calls,
+	// from 0 to 1, that get each fault
+	TimeoutRate   float64
+	TransientRate float64
+	DuplicateRate float64
+	// Seed makes the sequence of faults repeatable
+	Seed uint64
+
+	mu    sync.Mutex
+	calls int
+	rng   *rand.Rand
+}
+
+func (f *FaultyProcessor) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	switch f.next() {
+	case FaultTimeout:
+		if _, err := f.Processor.ProcessPayment(ctx, amount, currency, idempotencyKey); err != nil {
+			return PaymentResult{}, err
+		}
+		return PaymentResult{}, fmt.Errorf("%w: response timed out", ErrTransient)
+	case FaultTransient:
+		if err := ctx.Err(); err != nil {
+			return PaymentResult{}, err
+		}
+		return PaymentResult{}, fmt.Errorf("%w: injected fault", ErrTransient)
+	case FaultDuplicate:
+		f.Processor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+	}
+	return f.Processor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+}
+
+func (f *FaultyProcessor) Refund(ctx context.Context, paymentID string, amount float64) error {
+	return Refund(ctx, f.Processor, paymentID, amount)
+}
+
+func (f *FaultyProcessor) Currencies() []Currency {
+	return f.Processor.Currencies()
+}
+
+func (f *FaultyProcessor) Unwrap() PaymentProcessor {
+	return f.Processor
+}
+
+// next returns the fault of the next call
+func (f *FaultyProcessor) next() Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= len(f.Schedule) {
+		return f.Schedule[f.calls-1]
+	}
+	if f.rng == nil {
+		f.rng = rand.New(rand.NewPCG(f.Seed, f.Seed))
+	}
+	roll := f.rng.Float64()
+	switch {
+	case roll < f.TimeoutRate:
+		return FaultTimeout
+	case roll < f.TimeoutRate+f.TransientRate:
+		return FaultTransient
+	case roll < f.TimeoutRate+f.TransientRate+f.DuplicateRate:
+		return FaultDuplicate
+	}
+	return FaultNone
+}
@@ -0,0 +1,49 @@
+package payment
+
+import "math"
+
+// FeeSchedule is what a processor charges the merchant per payment
+type FeeSchedule struct {
+	// Percentage of the amount, 0.029 is 2.9%
+	Percentage float64
+	// Fixed is added to every payment, in the payment's currency
+	Fixed float64
+}
+
+// Fee is the charge for a payment of amount, rounded to four decimals
+func (f FeeSchedule) Fee(amount float64) float64 {
+	return math.Round((amount*f.Percentage+f.Fixed)*1e4) / 1e4
+}
+
+// FeeReporter is implemented by processors that publish their fee schedule
+type FeeReporter interface {
+	Fees() FeeSchedule
+}
+
+// Fee schedules of the built-in processors
+var (
+	cashFees      = FeeSchedule{}
+	cardFees      = FeeSchedule{Percentage: 0.029, Fixed: 0.30}
+	bankFees      = FeeSchedule{Fixed: 1}
+	walletFees    = FeeSchedule{Percentage: 0.015}
+	asyncFees     = FeeSchedule{Percentage: 0.01}
+	giftCardFees  = FeeSchedule{}
+	simulatedFees = FeeSchedule{Percentage: 0.02, Fixed: 0.10}
+)
+
+func (c *CashPayment) Fees() FeeSchedule         { return cashFees }
+func (c *CardPayment) Fees() FeeSchedule         { return cardFees }
+func (b *BankTransferPayment) Fees() FeeSchedule { return bankFees }
+func (m *MobileWalletPayment) Fees() FeeSchedule { return walletFees }
+func (a *AsyncPayment) Fees() FeeSchedule        { return asyncFees }
+func (g *GiftCardPayment) Fees() FeeSchedule     { return giftCardFees }
+func (s *SimulatedProcessor) Fees() FeeSchedule  { return simulatedFees }
+
+// Fees charges the network fee as a fixed amount per payment
+func (c *CryptoPayment) Fees() FeeSchedule {
+	fee := c.NetworkFee
+	if fee <= 0 {
+		fee = DEFAULT_NETWORK_FEE
+	}
+	return FeeSchedule{Fixed: fee}
+}
@@ -0,0 +1,123 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+)
+
+// ErrNoRate is returned for currency pairs a RateProvider has no rate for
+var ErrNoRate = errors.New("payment: no exchange rate")
+
+// RateProvider quotes exchange rates: one unit of from buys rate units of
+// to. Processors that convert payments depend on it, so where rates come
+// from is decided by whoever wires them.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to Currency) (float64, error)
+}
+
+// ConvertingProcessor also accepts the currencies in Accept, which the
+// wrapped processor does not. Such payments are converted into Settle at
+// the rate Rates quotes and charged in it; the result keeps the amount and
+// currency asked for, reports the fee converted back and what was charged
+// in Settled. Payments in the wrapped processor's own currencies pass
+// through. Use it through a pointer.
+type ConvertingProcessor struct {
+	Processor PaymentProcessor
+	Rates     RateProvider
+	// Accept lists the currencies converted
+	Accept []Currency
+	// Settle defaults to the first currency of Processor
+	Settle Currency
+
+	mu sync.Mutex
+	// quotes keeps the conversion of every keyed payment, so the payment
+	// is replayed even after the rate moved
+	quotes map[string]quote
+}
+
+type quote struct {
+	amount    float64
+	currency  Currency
+	converted float64
+}
+
+func (c *ConvertingProcessor) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if slices.Contains(c.Processor.Currencies(), currency) {
+		return c.Processor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+	}
+	if err := validatePayment(ctx, amount, currency, c.Accept); err != nil {
+		return PaymentResult{}, err
+	}
+	settle := c.settle()
+	converted, err := c.convert(ctx, amount, currency, settle, idempotencyKey)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	result, err := c.Processor.ProcessPayment(ctx, converted, settle, idempotencyKey)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	result.Settled, result.SettledCurrency = result.Amount, result.Currency
+	result.Fee = math.Round(result.Fee*amount/converted*1e4) / 1e4
+	result.Amount, result.Currency, result.Net = amount, currency, amount-result.Fee
+	if result.Requested != 0 {
+		result.Requested = math.Round(result.Requested*amount/converted*1e4) / 1e4
+	}
+	return ensureResult(result, amount, currency)
+}
+
+func (c *ConvertingProcessor) Currencies() []Currency {
+	currencies := c.Processor.Currencies()
+	for _, currency := range c.Accept {
+		if !slices.Contains(currencies, currency) {
+			currencies = append(currencies, currency)
+		}
+	}
+	return currencies
+}
+
+func (c *ConvertingProcessor) settle() Currency {
+	if c.Settle != "" {
+		return c.Settle
+	}
+	if currencies := c.Processor.Currencies(); len(currencies) > 0 {
+		return currencies[0]
+	}
+	return ""
+}
+
+// convert quotes amount in to, reusing the quote of idempotencyKey when
+// the same payment is sent again
+func (c *ConvertingProcessor) convert(ctx context.Context, amount float64, from, to Currency, idempotencyKey string) (float64, error) {
+	if idempotencyKey != "" {
+		c.mu.Lock()
+		q, ok := c.quotes[idempotencyKey]
+		c.mu.Unlock()
+		if ok && q.amount == amount && q.currency == from {
+			return q.converted, nil
+		}
+	}
+	rate, err := c.Rates.Rate(ctx, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("converting %s to %s: %w", from, to, err)
+	}
+	converted := amount * rate
+	if err := validateAmount(converted); err != nil {
+		return 0, fmt.Errorf("converting %s to %s at %v: %w", from, to, rate, err)
+	}
+	if idempotencyKey != "" {
+		c.mu.Lock()
+		if c.quotes == nil {
+			c.quotes = make(map[string]quote)
+		}
+		if _, ok := c.quotes[idempotencyKey]; !ok {
+			c.quotes[idempotencyKey] = quote{amount, from, converted}
+		}
+		c.mu.Unlock()
+	}
+	return converted, nil
+}
@@ -0,0 +1,168 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+const (
+	DEFAULT_GIFT_CARD         = "GIFT-0001"
+	DEFAULT_GIFT_CARD_BALANCE = 10000.0
+)
+
+// BalanceStore holds prepaid balances per card and currency
+type BalanceStore interface {
+	Balance(card string, currency Currency) (float64, error)
+	// Debit takes amount off the balance, or fails with
+	// ErrInsufficientFunds and changes nothing
+	Debit(card string, currency Currency, amount float64) error
+	Credit(card string, currency Currency, amount float64) error
+}
+
+// MemoryBalanceStore keeps balances in memory. The zero value is ready to
+// use and holds no money.
+type MemoryBalanceStore struct {
+	mu       sync.Mutex
+	balances map[string]float64
+}
+
+// Load sets the balance of card in currency
+func (m *MemoryBalanceStore) Load(card string, currency Currency, amount float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.balances == nil {
+		m.balances = make(map[string]float64)
+	}
+	m.balances[card+"/"+string(currency)] = amount
+}
+
+func (m *MemoryBalanceStore) Balance(card string, currency Currency) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.balances[card+"/"+string(currency)], nil
+}
+
+func (m *MemoryBalanceStore) Debit(card string, currency Currency, amount float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := card + "/" + string(currency)
+	if m.balances[key] < amount {
+		return ErrInsufficientFunds
+	}
+	m.balances[key] -= amount
+	return nil
+}
+
+func (m *MemoryBalanceStore) Credit(card string, currency Currency, amount float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.balances == nil {
+		m.balances = make(map[string]float64)
+	}
+	m.balances[card+"/"+string(currency)] += amount
+	return nil
+}
+
+// BalanceChecker is implemented by prepaid processors that can report what
+// is left to spend
+type BalanceChecker interface {
+	Balance(ctx context.Context, currency Currency) (float64, error)
+}
+
+// GiftCardPayment spends the prepaid balance of Card. A payment the balance
+// cannot cover fails with ErrInsufficientFunds unless AllowPartial is set,
+// in which case the whole balance is captured and Requested keeps what was
+// asked for. Taking what is left is one step for the payments of a
+// processor, so it is safe for concurrent use. Refunds go back onto the
+// card.
+type GiftCardPayment struct {
+	book
+	Card string
+	// Balances defaults to an empty in-memory store
+	Balances BalanceStore
+	// AllowPartial captures what the balance covers instead of failing
+	AllowPartial bool
+	// Idempotency defaults to an in-memory store
+	Idempotency IdempotencyStore
+
+	store MemoryBalanceStore
+	// spend keeps other payments from debiting between reading the balance
+	// and taking it
+	spend sync.Mutex
+}
+
+// NewGiftCardPayment returns a processor for card loaded with balance in
+// every currency it accepts
+func NewGiftCardPayment(card string, balance float64) *GiftCardPayment {
+	g := &GiftCardPayment{Card: card}
+	for _, currency := range giftCardCurrencies {
+		g.store.Load(card, currency, balance)
+	}
+	return g
+}
+
+func (g *GiftCardPayment) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := validatePayment(ctx, amount, currency, giftCardCurrencies); err != nil {
+		return PaymentResult{}, err
+	}
+	return g.idempotent(g.Idempotency, idempotencyKey, amount, currency, func() (PaymentResult, error) {
+		captured, err := g.debit(amount, currency)
+		if err != nil {
+			return PaymentResult{}, err
+		}
+		result := g.capture("gift", "gift card", captured, currency, giftCardFees)
+		if captured != amount {
+			result.Requested = amount
+		}
+		return ensureResult(result, captured, currency)
+	})
+}
+
+// debit takes amount off the balance and returns what it took, which is
+// what is left if that is less and AllowPartial is set
+func (g *GiftCardPayment) debit(amount float64, currency Currency) (float64, error) {
+	balances := g.balances()
+	g.spend.Lock()
+	defer g.spend.Unlock()
+	err := balances.Debit(g.Card, currency, amount)
+	if !errors.Is(err, ErrInsufficientFunds) || !g.AllowPartial {
+		return amount, err
+	}
+	left, err := balances.Balance(g.Card, currency)
+	if err != nil {
+		return 0, err
+	}
+	if left <= 0 {
+		return 0, ErrInsufficientFunds
+	}
+	return left, balances.Debit(g.Card, currency, left)
+}
+
+func (g *GiftCardPayment) Currencies() []Currency {
+	return slices.Clone(giftCardCurrencies)
+}
+
+// Refund puts the refunded amount back onto the card
+func (g *GiftCardPayment) Refund(ctx context.Context, paymentID string, amount float64) error {
+	result, repeated, err := g.refund(ctx, paymentID, amount)
+	if err != nil || repeated {
+		return err
+	}
+	return g.balances().Credit(g.Card, result.Currency, amount)
+}
+
+func (g *GiftCardPayment) Balance(ctx context.Context, currency Currency) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return g.balances().Balance(g.Card, currency)
+}
+
+func (g *GiftCardPayment) balances() BalanceStore {
+	if g.Balances != nil {
+		return g.Balances
+	}
+	return &g.store
+}
@@ -0,0 +1,69 @@
+package payment
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrIdempotencyKeyReused is returned when a key is sent again for a
+// different payment. It is an ErrDuplicate.
+var ErrIdempotencyKeyReused = fmt.Errorf("%w: idempotency key reused for a different payment", ErrDuplicate)
+
+// IdempotencyStore remembers the result of each payment by its idempotency key
+type IdempotencyStore interface {
+	Get(key string) (PaymentResult, bool)
+	Put(key string, result PaymentResult)
+}
+
+// MemoryIdempotencyStore keeps results in memory. The zero value is ready to use.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	results map[string]PaymentResult
+}
+
+func (m *MemoryIdempotencyStore) Get(key string) (PaymentResult, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result, ok := m.results[key]
+	return result, ok
+}
+
+func (m *MemoryIdempotencyStore) Put(key string, result PaymentResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.results == nil {
+		m.results = make(map[string]PaymentResult)
+	}
+	m.results[key] = result
+}
+
+// idempotent returns the result stored under key or runs process and stores
+// its result. An empty key always runs process. A nil store falls back to
+// one kept by the book, so every processor honors keys the same way.
+func (b *book) idempotent(store IdempotencyStore, key string, amount float64, currency Currency, process func() (PaymentResult, error)) (PaymentResult, error) {
+	if key == "" {
+		return process()
+	}
+
+	b.keyMu.Lock()
+	defer b.keyMu.Unlock()
+	if store == nil {
+		store = &b.keys
+	}
+	if result, ok := store.Get(key); ok {
+		requested := result.Amount
+		if result.Requested > 0 {
+			requested = result.Requested
+		}
+		if requested != amount || result.Currency != currency {
+			return PaymentResult{}, ErrIdempotencyKeyReused
+		}
+		return result, nil
+	}
+	result, err := process()
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	store.Put(key, result)
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidInstallments is returned for plans with fewer than one installment
+	ErrInvalidInstallments = errors.New("payment: a plan needs at least one installment")
+	// ErrPlanComplete is returned when paying an installment of a finished plan
+	ErrPlanComplete = errors.New("payment: installment plan already complete")
+)
+
+// Installment is one scheduled part of an InstallmentPlan
+type Installment struct {
+	Number    int
+	Amount    float64
+	PaymentID string
+	Paid      bool
+}
+
+// InstallmentPlan splits a total into equal installments. The plan only
+// talks to PaymentProcessor, so it behaves the same whichever processor
+// pays it: installments are paid in order, a failed installment is not
+// marked paid and can be tried again, and the plan is complete once all
+// installments are paid.
+type InstallmentPlan struct {
+	Total        float64
+	Currency     Currency
+	Installments []Installment
+}
+
+// NewInstallmentPlan schedules total in count installments. The last
+// installment absorbs any rounding so the amounts add up to total.
+func NewInstallmentPlan(total float64, currency Currency, count int) (*InstallmentPlan, error) {
+	if err := validateAmount(total); err != nil {
+		return nil, err
+	}
+	if count < 1 {
+		return nil, ErrInvalidInstallments
+	}
+
+	plan := &InstallmentPlan{Total: total, Currency: currency}
+	each := total / float64(count)
+	scheduled := 0.0
+	for i := 1; i <= count; i++ {
+		amount := each
+		if i == count {
+			amount = total - scheduled
+		}
+		scheduled += amount
+		plan.Installments = append(plan.Installments, Installment{Number: i, Amount: amount})
+	}
+	return plan, nil
+}
+
+// PayNext pays the next unpaid installment through processor. A non-empty
+// idempotencyKey is suffixed with the installment number, so retrying
+// PayNext after a lost response does not pay an installment twice.
+func (p *InstallmentPlan) PayNext(ctx context.Context, processor PaymentProcessor, idempotencyKey string) (PaymentResult, error) {
+	next := p.next()
+	if next == nil {
+		return PaymentResult{}, ErrPlanComplete
+	}
+	key := idempotencyKey
+	if key != "" {
+		key = fmt.Sprintf("%s-%d", idempotencyKey, next.Number)
+	}
+	result, err := processor.ProcessPayment(ctx, next.Amount, p.Currency, key)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	next.PaymentID = result.ID
+	next.Paid = true
+	return result, nil
+}
+
+// Complete reports whether every installment is paid
+func (p *InstallmentPlan) Complete() bool {
+	return p.next() == nil
+}
+
+// Remaining is the amount still to be paid
+func (p *InstallmentPlan) Remaining() float64 {
+	remaining := 0.0
+	for _, installment := range p.Installments {
+		if !installment.Paid {
+			remaining += installment.Amount
+		}
+	}
+	return remaining
+}
+
+func (p *InstallmentPlan) next() *Installment {
+	for i := range p.Installments {
+		if !p.Installments[i].Paid {
+			return &p.Installments[i]
+		}
+	}
+	return nil
+}
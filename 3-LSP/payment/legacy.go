@@ -0,0 +1,30 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+)
+
+// LegacyProcessor is the original string based processor interface
+type LegacyProcessor interface {
+	ProcessPayment(amount float64) string
+}
+
+// Legacy adapts a PaymentProcessor to the old string based interface so
+// existing callers keep working while they migrate to results. Payments are
+// made in the first currency the processor accepts.
+type Legacy struct {
+	Processor PaymentProcessor
+}
+
+func (l Legacy) ProcessPayment(amount float64) string {
+	var currency Currency
+	if currencies := l.Processor.Currencies(); len(currencies) > 0 {
+		currency = currencies[0]
+	}
+	result, err := l.Processor.ProcessPayment(context.Background(), amount, currency, "")
+	if err != nil {
+		return fmt.Sprintf("Payment failed: %v", err)
+	}
+	return result.String()
+}
@@ -0,0 +1,62 @@
+package payment
+
+import (
+	"context"
+	"sync"
+)
+
+// LockedProcessor serializes the payments and refunds of the wrapped
+// processor, for processors that read and then write state in separate
+// steps without guarding it. The built-in processors guard their own
+// state. A call waiting for its turn still returns ctx.Err() promptly once
+// ctx is done. Like the other decorators it forwards refunds and nothing
+// else. Use it through a pointer.
+type LockedProcessor struct {
+	Processor PaymentProcessor
+
+	once sync.Once
+	// turn holds a token while a call runs
+	turn chan struct{}
+}
+
+func (l *LockedProcessor) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := l.lock(ctx); err != nil {
+		return PaymentResult{}, err
+	}
+	defer l.unlock()
+	return l.Processor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+}
+
+func (l *LockedProcessor) Refund(ctx context.Context, paymentID string, amount float64) error {
+	if err := l.lock(ctx); err != nil {
+		return err
+	}
+	defer l.unlock()
+	return Refund(ctx, l.Processor, paymentID, amount)
+}
+
+func (l *LockedProcessor) Currencies() []Currency {
+	return l.Processor.Currencies()
+}
+
+func (l *LockedProcessor) Unwrap() PaymentProcessor {
+	return l.Processor
+}
+
+// lock waits for the turn of the caller or until ctx is done
+func (l *LockedProcessor) lock(ctx context.Context) error {
+	l.once.Do(func() { l.turn = make(chan struct{}, 1) })
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case l.turn <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *LockedProcessor) unlock() {
+	<-l.turn
+}
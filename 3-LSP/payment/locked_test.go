@@ -0,0 +1,127 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+)
+
+// payAndRefund pays 3 through p with paymenttest.Hammer, refunding every
+// other payment, and returns the payments and refunds that went through.
+// Payments failing with allowed are skipped.
+func payAndRefund(t *testing.T, p payment.PaymentProcessor, allowed error) (payments []payment.PaymentResult, refunded float64) {
+	t.Helper()
+	var mu sync.Mutex
+	ctx := context.Background()
+	currency := p.Currencies()[0]
+	err := paymenttest.Hammer(func(_, i int) error {
+		result, err := p.ProcessPayment(ctx, 3, currency, "")
+		if errors.Is(err, allowed) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("ProcessPayment(3) returned error: %w", err)
+		}
+		var back float64
+		if i%2 == 0 {
+			if err := payment.Refund(ctx, p, result.ID, result.Amount); err != nil {
+				return fmt.Errorf("Refund(%q, %v) returned error: %w", result.ID, result.Amount, err)
+			}
+			back = result.Amount
+		}
+		mu.Lock()
+		payments = append(payments, result)
+		refunded += back
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	return payments, refunded
+}
+
+// TestGiftCardConcurrentPartial drains a gift card that captures partial
+// payments from many goroutines: every unit taken off the balance is a
+// unit captured
+func TestGiftCardConcurrentPartial(t *testing.T) {
+	card := payment.NewGiftCardPayment("card", 100)
+	card.AllowPartial = true
+
+	payments, refunded := payAndRefund(t, card, payment.ErrInsufficientFunds)
+	var captured float64
+	for _, result := range payments {
+		captured += result.Amount
+	}
+	balance, err := card.Balance(context.Background(), card.Currencies()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance < 0 {
+		t.Errorf("balance = %v, want it never to go below zero", balance)
+	}
+	if spent := 100 - balance; spent != captured-refunded {
+		t.Errorf("balance went down by %v, want %v captured minus %v refunded", spent, captured, refunded)
+	}
+}
+
+// TestLockedSimulator runs a simulator that declines some payments and
+// takes a while for the rest from many goroutines
+func TestLockedSimulator(t *testing.T) {
+	p := &payment.LockedProcessor{Processor: &payment.SimulatedProcessor{DeclineRate: 0.2, Jitter: 50 * time.Microsecond, Seed: 1}}
+
+	payments, _ := payAndRefund(t, p, payment.ErrDeclined)
+	if len(payments) == 0 {
+		t.Fatal("every payment was declined")
+	}
+	seen := make(map[string]bool)
+	for _, result := range payments {
+		if seen[result.ID] {
+			t.Errorf("concurrent payments shared ID %q", result.ID)
+		}
+		seen[result.ID] = true
+	}
+}
+
+// TestLockedWaitHonorsContext queues a payment behind a slow one: it has
+// to give up when its deadline passes, not when its turn comes
+func TestLockedWaitHonorsContext(t *testing.T) {
+	p := &payment.LockedProcessor{Processor: &payment.SimulatedProcessor{Latency: 500 * time.Millisecond}}
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		close(started)
+		p.ProcessPayment(context.Background(), 10, payment.USD, "")
+	}()
+	<-started
+	time.Sleep(20 * time.Millisecond)
+
+	for name, call := range map[string]func(ctx context.Context) error{
+		"ProcessPayment": func(ctx context.Context) error {
+			_, err := p.ProcessPayment(ctx, 10, payment.USD, "")
+			return err
+		},
+		"Refund": func(ctx context.Context) error {
+			return p.Refund(ctx, "sim-1", 10)
+		},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		start := time.Now()
+		err := call(ctx)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s waiting for its turn error = %v, want %v", name, err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+			t.Errorf("%s returned %s after a deadline of 10ms", name, elapsed)
+		}
+	}
+	<-done
+}
@@ -0,0 +1,150 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/contract"
+)
+
+var (
+	// ErrInvalidAmount is returned for amounts that are zero, negative or not a number
+	ErrInvalidAmount = errors.New("payment: amount must be a positive number")
+	// ErrPaymentNotFound is returned when refunding a payment the processor never captured
+	ErrPaymentNotFound = errors.New("payment: payment not found")
+	// ErrRefundExceedsCapture is returned when refunding more than was captured
+	ErrRefundExceedsCapture = errors.New("payment: refund exceeds captured amount")
+	// ErrAlreadyRefunded is returned when a refunded payment is refunded again with a different amount
+	ErrAlreadyRefunded = errors.New("payment: payment already refunded")
+	// ErrNotCaptured is returned when refunding an authorization that was never captured
+	ErrNotCaptured = errors.New("payment: payment not captured")
+	// ErrAuthorizationExpired is returned when capturing an authorization after it lapsed
+	ErrAuthorizationExpired = errors.New("payment: authorization expired")
+	// ErrAlreadyCaptured is returned when voiding a payment whose funds were taken
+	ErrAlreadyCaptured = errors.New("payment: payment already captured")
+	// ErrPaymentVoided is returned when capturing or refunding a voided payment
+	ErrPaymentVoided = errors.New("payment: payment voided")
+)
+
+// PaymentResult describes a processed payment. Every processor fills in
+// ID, Method, Amount, Fee, Net, Currency, Status and Timestamp; the
+// remaining fields are only set by methods they apply to.
+type PaymentResult struct {
+	ID        string
+	Method    string
+	Amount    float64
+	Currency  Currency
+	Status    Status
+	Timestamp time.Time
+	// Fee is what the processor keeps of Amount and Net is what reaches
+	// the merchant. Amount is always the full amount the customer paid.
+	Fee float64
+	Net float64
+	// SettlementDelay is how long until the funds reach the merchant
+	SettlementDelay time.Duration
+	// Confirmations is how many confirmations the method waited for
+	Confirmations int
+	// Requested is the amount asked for when a partial capture took less
+	Requested float64
+	// Settled is what a converted payment was charged, in SettledCurrency
+	Settled         float64
+	SettledCurrency Currency
+}
+
+// String renders the result the way processors used to report payments
+func (r PaymentResult) String() string {
+	return fmt.Sprintf("Processing %s payment of %f %s", r.Method, r.Amount, r.Currency)
+}
+
+// Base interface
+//
+// ProcessPayment captures amount and returns its result, which is either
+// captured or pending. Amounts that are not positive numbers fail with
+// ErrInvalidAmount, currencies missing from Currencies fail with
+// ErrUnsupportedCurrency. Sending a non-empty idempotencyKey again returns
+// the original result without a new payment; reusing it for a different
+// amount or currency fails with ErrIdempotencyKeyReused.
+//
+// Every method returns ctx.Err() promptly once ctx is done. Refunds are a
+// capability of their own, see Refunder.
+type PaymentProcessor interface {
+	ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error)
+	// Currencies lists the currencies the processor accepts
+	Currencies() []Currency
+}
+
+// CashPayment implements the base interface
+type CashPayment struct {
+	refundableBook
+	// Idempotency defaults to an in-memory store
+	Idempotency IdempotencyStore
+}
+
+func (c *CashPayment) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := validatePayment(ctx, amount, currency, cashCurrencies); err != nil {
+		return PaymentResult{}, err
+	}
+	return c.idempotent(c.Idempotency, idempotencyKey, amount, currency, func() (PaymentResult, error) {
+		return ensureResult(c.capture("cash", "cash", amount, currency, cashFees), amount, currency)
+	})
+}
+
+func (c *CashPayment) Currencies() []Currency {
+	return slices.Clone(cashCurrencies)
+}
+
+// CardPayment also implements the same interface
+type CardPayment struct {
+	refundableBook
+	// Idempotency defaults to an in-memory store
+	Idempotency IdempotencyStore
+	// AuthorizationTTL defaults to DEFAULT_AUTHORIZATION_TTL
+	AuthorizationTTL time.Duration
+	// ChallengeAbove asks for strong customer authentication on payments
+	// above it with a ChallengeRequiredError; zero never asks
+	ChallengeAbove float64
+	// ChallengeCode is the answer a challenge expects, it defaults to
+	// DEFAULT_CHALLENGE_CODE
+	ChallengeCode string
+
+	challenges challenges
+}
+
+func (c *CardPayment) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := validatePayment(ctx, amount, currency, cardCurrencies); err != nil {
+		return PaymentResult{}, err
+	}
+	return c.idempotent(c.Idempotency, idempotencyKey, amount, currency, func() (PaymentResult, error) {
+		if c.ChallengeAbove > 0 && amount > c.ChallengeAbove {
+			return PaymentResult{}, c.challenges.issue("card", amount, currency, idempotencyKey, false)
+		}
+		return ensureResult(c.capture("card", "card", amount, currency, cardFees), amount, currency)
+	})
+}
+
+func (c *CardPayment) Currencies() []Currency {
+	return slices.Clone(cardCurrencies)
+}
+
+// validateAmount is the amount precondition shared by payments and refunds
+func validateAmount(amount float64) error {
+	return contract.Requires(!math.IsNaN(amount) && !math.IsInf(amount, 0) && amount > 0, ErrInvalidAmount)
+}
+
+// ensureResult is the postcondition shared by every processor
+func ensureResult(result PaymentResult, amount float64, currency Currency) (PaymentResult, error) {
+	err := contract.Ensures(result.ID != "" && result.Method != "" && result.Amount == amount && result.Currency == currency,
+		"result %+v must carry an ID, a method and %v %s", result, amount, currency)
+	if err == nil {
+		err = contract.Ensures(result.Fee >= 0 && result.Net == result.Amount-result.Fee,
+			"result %+v must report its fee instead of deducting it from the amount", result)
+	}
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	return result, nil
+}
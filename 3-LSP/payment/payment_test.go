@@ -0,0 +1,19 @@
+package payment_test
+
+import (
+	"testing"
+
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+)
+
+// FuzzProcessors checks the shared invariants of every registered
+// processor; its seed corpus runs with go test, go test -fuzz explores more
+func FuzzProcessors(f *testing.F) { paymenttest.Fuzz(f) }
+
+// BenchmarkLayers measures every processor bare and behind each decorator,
+// run it with go test -bench Layers
+func BenchmarkLayers(b *testing.B) { paymenttest.BenchmarkLayers(b) }
+
+// TestScenarioMatrix runs every scenario against every processor and logs
+// the matrix, go test -v shows it
+func TestScenarioMatrix(t *testing.T) { paymenttest.RunScenarioMatrix(t) }
@@ -0,0 +1,93 @@
+package payment
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+const (
+	DEFAULT_SETTLEMENT_DELAY = 48 * time.Hour
+	DEFAULT_NETWORK_FEE      = 0.0005
+	DEFAULT_CONFIRMATIONS    = 3
+)
+
+// BankTransferPayment captures immediately but the funds settle later
+type BankTransferPayment struct {
+	refundableBook
+	// SettlementDelay defaults to DEFAULT_SETTLEMENT_DELAY
+	SettlementDelay time.Duration
+	// Idempotency defaults to an in-memory store
+	Idempotency IdempotencyStore
+}
+
+func (b *BankTransferPayment) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := validatePayment(ctx, amount, currency, bankCurrencies); err != nil {
+		return PaymentResult{}, err
+	}
+	return b.idempotent(b.Idempotency, idempotencyKey, amount, currency, func() (PaymentResult, error) {
+		delay := b.SettlementDelay
+		if delay <= 0 {
+			delay = DEFAULT_SETTLEMENT_DELAY
+		}
+		result := b.capture("bank", "bank transfer", amount, currency, bankFees)
+		result.SettlementDelay = delay
+		return ensureResult(result, amount, currency)
+	})
+}
+
+func (b *BankTransferPayment) Currencies() []Currency {
+	return slices.Clone(bankCurrencies)
+}
+
+// CryptoPayment is charged a fixed network fee per payment. Transfers on
+// the chain are final, so it is no Refunder: callers find out with a type
+// assertion or get ErrUnsupported from Refund instead of a broken refund.
+type CryptoPayment struct {
+	book
+	// NetworkFee defaults to DEFAULT_NETWORK_FEE
+	NetworkFee float64
+	// Idempotency defaults to an in-memory store
+	Idempotency IdempotencyStore
+}
+
+func (c *CryptoPayment) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := validatePayment(ctx, amount, currency, cryptoCurrencies); err != nil {
+		return PaymentResult{}, err
+	}
+	return c.idempotent(c.Idempotency, idempotencyKey, amount, currency, func() (PaymentResult, error) {
+		return ensureResult(c.capture("crypto", "crypto", amount, currency, c.Fees()), amount, currency)
+	})
+}
+
+func (c *CryptoPayment) Currencies() []Currency {
+	return slices.Clone(cryptoCurrencies)
+}
+
+// MobileWalletPayment waits for the wallet provider to confirm the payment
+type MobileWalletPayment struct {
+	refundableBook
+	// Confirmations defaults to DEFAULT_CONFIRMATIONS
+	Confirmations int
+	// Idempotency defaults to an in-memory store
+	Idempotency IdempotencyStore
+}
+
+func (m *MobileWalletPayment) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := validatePayment(ctx, amount, currency, walletCurrencies); err != nil {
+		return PaymentResult{}, err
+	}
+	return m.idempotent(m.Idempotency, idempotencyKey, amount, currency, func() (PaymentResult, error) {
+		confirmations := m.Confirmations
+		if confirmations <= 0 {
+			confirmations = DEFAULT_CONFIRMATIONS
+		}
+		result := m.capture("wallet", "mobile wallet", amount, currency, walletFees)
+		result.Confirmations = confirmations
+		return ensureResult(result, amount, currency)
+	})
+}
+
+func (m *MobileWalletPayment) Currencies() []Currency {
+	return slices.Clone(walletCurrencies)
+}
@@ -0,0 +1,110 @@
+package payment
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/clock"
+)
+
+const (
+	DEFAULT_RATE_LIMIT = 10.0
+	DEFAULT_BURST      = 1
+)
+
+// RateLimitedProcessor lets at most Rate calls per second through to the
+// wrapped processor, with bursts of up to Burst calls, like a gateway that
+// throttles its clients. Calls over the limit wait for a token instead of
+// failing, and return ctx.Err() if the context is done first. Use it
+// through a pointer.
+type RateLimitedProcessor struct {
+	Processor PaymentProcessor
+	// Rate is in calls per second and defaults to DEFAULT_RATE_LIMIT
+	Rate float64
+	// Burst defaults to DEFAULT_BURST
+	Burst int
+	// Clock refills the bucket, it defaults to the real clock
+	Clock clock.Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func (r *RateLimitedProcessor) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := r.wait(ctx); err != nil {
+		return PaymentResult{}, err
+	}
+	return r.Processor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+}
+
+func (r *RateLimitedProcessor) Refund(ctx context.Context, paymentID string, amount float64) error {
+	if err := r.wait(ctx); err != nil {
+		return err
+	}
+	return Refund(ctx, r.Processor, paymentID, amount)
+}
+
+func (r *RateLimitedProcessor) Currencies() []Currency {
+	return r.Processor.Currencies()
+}
+
+func (r *RateLimitedProcessor) Unwrap() PaymentProcessor {
+	return r.Processor
+}
+
+// wait takes a token from the bucket, waiting for one if it is empty
+func (r *RateLimitedProcessor) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delay := r.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		r.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token, possibly one that has not been refilled yet, and
+// returns how long until it is
+func (r *RateLimitedProcessor) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rate := r.Rate
+	if rate <= 0 {
+		rate = DEFAULT_RATE_LIMIT
+	}
+	burst := float64(r.Burst)
+	if burst <= 0 {
+		burst = DEFAULT_BURST
+	}
+
+	now := clock.Or(r.Clock).Now()
+	if r.last.IsZero() {
+		r.tokens = burst
+	} else {
+		r.tokens = min(burst, r.tokens+now.Sub(r.last).Seconds()*rate)
+	}
+	r.last = now
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens / rate * float64(time.Second))
+}
+
+// cancel returns a token reserved by a call that gave up waiting
+func (r *RateLimitedProcessor) cancel() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens++
+}
@@ -0,0 +1,103 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+	"github.com/imrancluster/go-solid/clock"
+)
+
+// TestRateLimitedThroughput makes sure calls beyond the burst are held to
+// the rate
+func TestRateLimitedThroughput(t *testing.T) {
+	const rate, burst, calls = 200.0, 5, 25
+	p := &payment.RateLimitedProcessor{Processor: &payment.CashPayment{}, Rate: rate, Burst: burst}
+
+	start := time.Now()
+	for i := 0; i < calls; i++ {
+		if _, err := p.ProcessPayment(context.Background(), 1, "USD", ""); err != nil {
+			t.Fatalf("ProcessPayment(1) returned error: %v", err)
+		}
+	}
+	want := time.Duration(float64(calls-burst) / rate * float64(time.Second))
+	if elapsed := time.Since(start); elapsed < want*9/10 {
+		t.Errorf("%d calls at %v per second with a burst of %d took %v, want at least %v", calls, rate, burst, elapsed, want)
+	}
+}
+
+// TestRateLimitedBurst makes sure the first Burst calls do not wait
+func TestRateLimitedBurst(t *testing.T) {
+	p := &payment.RateLimitedProcessor{Processor: &payment.CashPayment{}, Rate: 1, Burst: 3}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := p.ProcessPayment(context.Background(), 1, "USD", ""); err != nil {
+			t.Fatalf("ProcessPayment(1) returned error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > paymenttest.PROMPT {
+		t.Errorf("a burst of 3 took %v, want it to go through at once", elapsed)
+	}
+}
+
+// TestRateLimitedWaitHonorsContext makes sure a call waiting for a token
+// gives up when its context is done, and hands the token back
+func TestRateLimitedWaitHonorsContext(t *testing.T) {
+	p := &payment.RateLimitedProcessor{Processor: &payment.CashPayment{}, Rate: 10, Burst: 1}
+	if _, err := p.ProcessPayment(context.Background(), 1, "USD", ""); err != nil {
+		t.Fatalf("ProcessPayment(1) returned error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := p.ProcessPayment(ctx, 1, "USD", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ProcessPayment waiting past its deadline error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond+paymenttest.PROMPT {
+		t.Errorf("ProcessPayment returned %v after its deadline, want at most %v", elapsed-10*time.Millisecond, paymenttest.PROMPT)
+	}
+	// the next token is due 100ms after the first call, not 200ms
+	start = time.Now()
+	if _, err := p.ProcessPayment(context.Background(), 1, "USD", ""); err != nil {
+		t.Fatalf("ProcessPayment(1) returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("ProcessPayment after a canceled wait took %v, want the canceled token back", elapsed)
+	}
+}
+
+func TestRateLimitedConforms(t *testing.T) {
+	paymenttest.RunProcessorSuite(t, func() payment.PaymentProcessor {
+		return &payment.RateLimitedProcessor{Processor: &payment.CardPayment{}, Rate: 1e6, Burst: 1000}
+	})
+}
+
+// TestRateLimitedClock refills the bucket by moving a frozen clock instead
+// of waiting for it
+func TestRateLimitedClock(t *testing.T) {
+	now := clock.NewFrozen(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	p := &payment.RateLimitedProcessor{Processor: &payment.CashPayment{}, Rate: 1, Burst: 2, Clock: now}
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := p.ProcessPayment(context.Background(), 1, "USD", ""); err != nil {
+			t.Fatalf("ProcessPayment(1) returned error: %v", err)
+		}
+		if i%2 == 1 {
+			now.Advance(2 * time.Second)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > paymenttest.PROMPT {
+		t.Errorf("6 calls with the clock moved 2 seconds after every 2 took %v, want them to go through at once", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	p.ProcessPayment(context.Background(), 1, "USD", "")
+	p.ProcessPayment(context.Background(), 1, "USD", "")
+	if _, err := p.ProcessPayment(ctx, 1, "USD", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ProcessPayment with the bucket empty and the clock standing still error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,63 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownProcessor is returned by New for names nobody registered
+var ErrUnknownProcessor = errors.New("payment: unknown processor")
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]func() PaymentProcessor{
+		"cash":          func() PaymentProcessor { return &CashPayment{} },
+		"card":          func() PaymentProcessor { return &CardPayment{} },
+		"bank-transfer": func() PaymentProcessor { return &BankTransferPayment{} },
+		"crypto":        func() PaymentProcessor { return &CryptoPayment{} },
+		"mobile-wallet": func() PaymentProcessor { return &MobileWalletPayment{} },
+		"async":         func() PaymentProcessor { return &AsyncPayment{} },
+		"gift-card":     func() PaymentProcessor { return NewGiftCardPayment(DEFAULT_GIFT_CARD, DEFAULT_GIFT_CARD_BALANCE) },
+		"simulated":     func() PaymentProcessor { return &SimulatedProcessor{} },
+	}
+)
+
+// Register makes a processor available by name, like database/sql drivers.
+// It panics if newProcessor is nil or name is already registered.
+func Register(name string, newProcessor func() PaymentProcessor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if newProcessor == nil {
+		panic("payment: Register processor is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("payment: Register called twice for processor " + name)
+	}
+	registry[name] = newProcessor
+}
+
+// New builds a fresh processor registered under name
+func New(name string) (PaymentProcessor, error) {
+	registryMu.RLock()
+	newProcessor, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProcessor, name)
+	}
+	return newProcessor(), nil
+}
+
+// Names lists the registered processors in sorted order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return slices.Clip(names)
+}
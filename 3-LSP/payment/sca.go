@@ -0,0 +1,179 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const DEFAULT_CHALLENGE_CODE = "123456"
+
+var (
+	// ErrChallengeRequired is returned when a payment needs strong customer
+	// authentication first. Nothing is captured, so to callers that cannot
+	// authenticate it is an ErrDeclined like any other.
+	ErrChallengeRequired = fmt.Errorf("%w: authentication required", ErrDeclined)
+	// ErrChallengeFailed is returned when the customer failed a challenge.
+	// It is an ErrDeclined.
+	ErrChallengeFailed = fmt.Errorf("%w: authentication failed", ErrDeclined)
+	// ErrChallengeNotFound is returned when completing an unknown or
+	// already completed challenge
+	ErrChallengeNotFound = errors.New("payment: challenge not found")
+)
+
+// Challenge asks the customer to authenticate a payment, 3-D Secure style
+type Challenge struct {
+	ID       string
+	Amount   float64
+	Currency Currency
+	// Prompt is what the customer is asked to do
+	Prompt string
+}
+
+// ChallengeRequiredError carries the challenge of a payment that needs
+// authentication. It matches ErrChallengeRequired.
+type ChallengeRequiredError struct {
+	Challenge Challenge
+}
+
+func (e *ChallengeRequiredError) Error() string {
+	return fmt.Sprintf("%v: challenge %s", ErrChallengeRequired, e.Challenge.ID)
+}
+
+func (e *ChallengeRequiredError) Unwrap() error {
+	return ErrChallengeRequired
+}
+
+// ChallengeCompleter is implemented by processors that may ask for strong
+// customer authentication. CompleteChallenge makes the payment once the
+// customer answered; a wrong answer fails with ErrChallengeFailed and uses
+// the challenge up.
+type ChallengeCompleter interface {
+	CompleteChallenge(ctx context.Context, challengeID, response string) (PaymentResult, error)
+}
+
+// ChallengeResolver gets the customer's answer to a challenge
+type ChallengeResolver interface {
+	Resolve(ctx context.Context, challenge Challenge) (string, error)
+}
+
+// ChallengeResolverFunc lets an ordinary function resolve challenges
+type ChallengeResolverFunc func(ctx context.Context, challenge Challenge) (string, error)
+
+func (f ChallengeResolverFunc) Resolve(ctx context.Context, challenge Challenge) (string, error) {
+	return f(ctx, challenge)
+}
+
+// ProcessWithChallenge pays through p and lets resolver answer the
+// challenge if p asks for one. Processors without strong customer
+// authentication never ask, so it works with any processor.
+func ProcessWithChallenge(ctx context.Context, p PaymentProcessor, amount float64, currency Currency, idempotencyKey string, resolver ChallengeResolver) (PaymentResult, error) {
+	result, err := p.ProcessPayment(ctx, amount, currency, idempotencyKey)
+	var required *ChallengeRequiredError
+	if !errors.As(err, &required) {
+		return result, err
+	}
+	completer, ok := p.(ChallengeCompleter)
+	if !ok {
+		return PaymentResult{}, err
+	}
+	response, err := resolver.Resolve(ctx, required.Challenge)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	return completer.CompleteChallenge(ctx, required.Challenge.ID, response)
+}
+
+// AuthenticatingProcessor answers challenges through Resolver, so callers
+// that know nothing about authentication can use a processor that asks
+// for it
+type AuthenticatingProcessor struct {
+	Processor PaymentProcessor
+	Resolver  ChallengeResolver
+}
+
+func (a AuthenticatingProcessor) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	return ProcessWithChallenge(ctx, a.Processor, amount, currency, idempotencyKey, a.Resolver)
+}
+
+func (a AuthenticatingProcessor) Refund(ctx context.Context, paymentID string, amount float64) error {
+	return Refund(ctx, a.Processor, paymentID, amount)
+}
+
+func (a AuthenticatingProcessor) Currencies() []Currency {
+	return a.Processor.Currencies()
+}
+
+func (a AuthenticatingProcessor) Unwrap() PaymentProcessor {
+	return a.Processor
+}
+
+// challenges keeps the open challenges of a processor
+type challenges struct {
+	mu   sync.Mutex
+	seq  int
+	open map[string]openChallenge
+}
+
+type openChallenge struct {
+	Challenge
+	idempotencyKey string
+	// authorize is set for challenges of an authorization, whose funds are
+	// reserved rather than captured once the customer answers
+	authorize bool
+}
+
+func (c *challenges) issue(prefix string, amount float64, currency Currency, idempotencyKey string, authorize bool) *ChallengeRequiredError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.open == nil {
+		c.open = make(map[string]openChallenge)
+	}
+	c.seq++
+	challenge := Challenge{
+		ID:       fmt.Sprintf("%s-challenge-%d", prefix, c.seq),
+		Amount:   amount,
+		Currency: currency,
+		Prompt:   "enter the code your bank sent you",
+	}
+	c.open[challenge.ID] = openChallenge{challenge, idempotencyKey, authorize}
+	return &ChallengeRequiredError{Challenge: challenge}
+}
+
+// take removes an open challenge
+func (c *challenges) take(challengeID string) (openChallenge, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	open, ok := c.open[challengeID]
+	if !ok {
+		return openChallenge{}, ErrChallengeNotFound
+	}
+	delete(c.open, challengeID)
+	return open, nil
+}
+
+// CompleteChallenge captures the payment once the customer entered
+// ChallengeCode, or reserves it if the challenge came from Authorize
+func (c *CardPayment) CompleteChallenge(ctx context.Context, challengeID, response string) (PaymentResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PaymentResult{}, err
+	}
+	open, err := c.challenges.take(challengeID)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	code := c.ChallengeCode
+	if code == "" {
+		code = DEFAULT_CHALLENGE_CODE
+	}
+	if response != code {
+		return PaymentResult{}, ErrChallengeFailed
+	}
+	return c.idempotent(c.Idempotency, open.idempotencyKey, open.Amount, open.Currency, func() (PaymentResult, error) {
+		if open.authorize {
+			return c.reserve(open.Amount, open.Currency)
+		}
+		return ensureResult(c.capture("card", "card", open.Amount, open.Currency, cardFees), open.Amount, open.Currency)
+	})
+}
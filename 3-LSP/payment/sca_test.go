@@ -0,0 +1,58 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// TestAuthorizeChallenge makes sure authorizations above ChallengeAbove ask
+// for authentication like payments do, and that completing the challenge
+// reserves the funds instead of capturing them
+func TestAuthorizeChallenge(t *testing.T) {
+	ctx := context.Background()
+	card := &payment.CardPayment{ChallengeAbove: 100}
+
+	if result, err := card.Authorize(ctx, 100, "USD", ""); err != nil || result.Status != payment.StatusAuthorized {
+		t.Fatalf("Authorize(100) = %+v, %v, want it authorized without a challenge", result, err)
+	}
+
+	_, err := card.Authorize(ctx, 250, "USD", "order-1")
+	var required *payment.ChallengeRequiredError
+	if !errors.As(err, &required) || !errors.Is(err, payment.ErrChallengeRequired) {
+		t.Fatalf("Authorize(250) error = %v, want a ChallengeRequiredError", err)
+	}
+	if required.Challenge.Amount != 250 || required.Challenge.Currency != "USD" {
+		t.Errorf("challenge = %+v, want it for 250 USD", required.Challenge)
+	}
+
+	result, err := card.CompleteChallenge(ctx, required.Challenge.ID, payment.DEFAULT_CHALLENGE_CODE)
+	if err != nil || result.Status != payment.StatusAuthorized || result.Amount != 250 {
+		t.Fatalf("CompleteChallenge = %+v, %v, want 250 authorized", result, err)
+	}
+	if err := card.Refund(ctx, result.ID, 250); !errors.Is(err, payment.ErrNotCaptured) {
+		t.Errorf("Refund before Capture error = %v, want %v", err, payment.ErrNotCaptured)
+	}
+	if captured, err := card.Capture(ctx, result.ID); err != nil || captured.Status != payment.StatusCaptured {
+		t.Errorf("Capture = %+v, %v, want it captured", captured, err)
+	}
+}
+
+// TestAuthorizeChallengeFails makes sure a wrong answer reserves nothing
+func TestAuthorizeChallengeFails(t *testing.T) {
+	ctx := context.Background()
+	card := &payment.CardPayment{ChallengeAbove: 100}
+	_, err := card.Authorize(ctx, 250, "USD", "")
+	var required *payment.ChallengeRequiredError
+	if !errors.As(err, &required) {
+		t.Fatalf("Authorize(250) error = %v, want a ChallengeRequiredError", err)
+	}
+	if _, err := card.CompleteChallenge(ctx, required.Challenge.ID, "000000"); !errors.Is(err, payment.ErrChallengeFailed) {
+		t.Errorf("CompleteChallenge with a wrong code error = %v, want %v", err, payment.ErrChallengeFailed)
+	}
+	if _, err := card.CompleteChallenge(ctx, required.Challenge.ID, payment.DEFAULT_CHALLENGE_CODE); !errors.Is(err, payment.ErrChallengeNotFound) {
+		t.Errorf("CompleteChallenge again error = %v, want %v", err, payment.ErrChallengeNotFound)
+	}
+}
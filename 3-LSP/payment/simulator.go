@@ -0,0 +1,95 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DEFAULT_DECLINE_REASONS are what a SimulatedProcessor declines with when
+// it was given no reasons of its own
+var DEFAULT_DECLINE_REASONS = []string{"do not honor", "card expired", "suspected fraud"}
+
+// SimulatedProcessor behaves like a remote gateway whose failures and
+// latency are configurable, for demos and for testing code that has to
+// cope with an unreliable processor. The zero value never fails and
+// answers at once, so it passes the shared contract like any other
+// processor. Failures always match a shared sentinel: declines are
+// ErrDeclined and transient failures are ErrTransient.
+type SimulatedProcessor struct {
+	refundableBook
+	// DeclineRate is the share of payments, from 0 to 1, that are declined
+	DeclineRate float64
+	// TransientRate is the share of payments, from 0 to 1, that fail with
+	// ErrTransient and may succeed when tried again
+	TransientRate float64
+	// DeclineReasons are picked at random for each decline, they default to
+	// DEFAULT_DECLINE_REASONS
+	DeclineReasons []string
+	// Latency is how long each payment takes, Jitter adds up to as much
+	// again at random
+	Latency time.Duration
+	Jitter  time.Duration
+	// Seed makes the sequence of failures and delays repeatable
+	Seed uint64
+	// Idempotency defaults to an in-memory store
+	Idempotency IdempotencyStore
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func (s *SimulatedProcessor) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	if err := validatePayment(ctx, amount, currency, simulatedCurrencies); err != nil {
+		return PaymentResult{}, err
+	}
+	delay, outcome := s.roll()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return PaymentResult{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return s.idempotent(s.Idempotency, idempotencyKey, amount, currency, func() (PaymentResult, error) {
+		if outcome != nil {
+			return PaymentResult{}, outcome
+		}
+		return ensureResult(s.capture("sim", "simulated", amount, currency, simulatedFees), amount, currency)
+	})
+}
+
+func (s *SimulatedProcessor) Currencies() []Currency {
+	return slices.Clone(simulatedCurrencies)
+}
+
+// roll draws the delay and the failure, if any, of the next payment
+func (s *SimulatedProcessor) roll() (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rng == nil {
+		s.rng = rand.New(rand.NewPCG(s.Seed, s.Seed))
+	}
+
+	delay := s.Latency
+	if s.Jitter > 0 {
+		delay += time.Duration(s.rng.Int64N(int64(s.Jitter)))
+	}
+	roll := s.rng.Float64()
+	switch {
+	case roll < s.DeclineRate:
+		reasons := s.DeclineReasons
+		if len(reasons) == 0 {
+			reasons = DEFAULT_DECLINE_REASONS
+		}
+		return delay, fmt.Errorf("%w: %s", ErrDeclined, reasons[s.rng.IntN(len(reasons))])
+	case roll < s.DeclineRate+s.TransientRate:
+		return delay, ErrTransient
+	}
+	return delay, nil
+}
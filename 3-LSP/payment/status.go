@@ -0,0 +1,58 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrIllegalTransition is returned when a payment would move between two
+// statuses its lifecycle does not connect
+var ErrIllegalTransition = errors.New("payment: illegal status transition")
+
+// Status tells where a payment is in its lifecycle
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusAuthorized Status = "authorized"
+	StatusCaptured   Status = "captured"
+	StatusRefunded   Status = "refunded"
+	StatusFailed     Status = "failed"
+	StatusVoided     Status = "voided"
+)
+
+// transitions is the one lifecycle every processor follows. Refunded,
+// failed and voided payments are final.
+var transitions = map[Status][]Status{
+	StatusPending:    {StatusCaptured, StatusFailed, StatusVoided},
+	StatusAuthorized: {StatusCaptured, StatusFailed, StatusVoided},
+	StatusCaptured:   {StatusRefunded},
+}
+
+// CanTransition reports whether a payment in s may move to next. Staying in
+// the same status is always allowed so repeated operations stay idempotent.
+func (s Status) CanTransition(next Status) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Final reports whether no transition leaves s
+func (s Status) Final() bool {
+	return len(transitions[s]) == 0
+}
+
+// Transition returns next if a payment in from may move there and
+// ErrIllegalTransition otherwise
+func Transition(from, next Status) (Status, error) {
+	if !from.CanTransition(next) {
+		return from, fmt.Errorf("%w: %s to %s", ErrIllegalTransition, from, next)
+	}
+	return next, nil
+}
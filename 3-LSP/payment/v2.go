@@ -0,0 +1,115 @@
+package payment
+
+import (
+	"context"
+	"time"
+
+	"github.com/imrancluster/go-solid/clock"
+)
+
+// PaymentRequest is everything ProcessorV2 needs to make a payment
+type PaymentRequest struct {
+	Amount         float64
+	Currency       Currency
+	IdempotencyKey string
+}
+
+// RefundRequest is everything ProcessorV2 needs to refund a payment
+type RefundRequest struct {
+	PaymentID string
+	Amount    float64
+}
+
+// RefundResult describes an accepted refund
+type RefundResult struct {
+	PaymentID string
+	Amount    float64
+	Timestamp time.Time
+}
+
+// ProcessorV2 is the second version of the contract. Requests and results
+// are structs, so later fields do not change the method set again. The
+// rules are the same as PaymentProcessor's.
+type ProcessorV2 interface {
+	Pay(ctx context.Context, req PaymentRequest) (PaymentResult, error)
+	Refund(ctx context.Context, req RefundRequest) (RefundResult, error)
+	Currencies() []Currency
+}
+
+// V2 lifts a v1 processor into ProcessorV2, so existing implementations
+// keep working with callers that moved to the new contract. Refund is part
+// of ProcessorV2, so processors that cannot refund fail with
+// ErrUnsupported; Unwrap returns p, see CanRefund.
+func V2(p PaymentProcessor) ProcessorV2 {
+	return v2Adapter{p}
+}
+
+type v2Adapter struct {
+	p PaymentProcessor
+}
+
+func (a v2Adapter) Pay(ctx context.Context, req PaymentRequest) (PaymentResult, error) {
+	return a.p.ProcessPayment(ctx, req.Amount, req.Currency, req.IdempotencyKey)
+}
+
+func (a v2Adapter) Refund(ctx context.Context, req RefundRequest) (RefundResult, error) {
+	if err := Refund(ctx, a.p, req.PaymentID, req.Amount); err != nil {
+		return RefundResult{}, err
+	}
+	return RefundResult{PaymentID: req.PaymentID, Amount: req.Amount, Timestamp: a.now()}, nil
+}
+
+// now reads the clock of the processor, processors without one are
+// refunded in real time
+func (a v2Adapter) now() time.Time {
+	if b, ok := a.p.(interface{ now() time.Time }); ok {
+		return b.now()
+	}
+	return clock.Real{}.Now()
+}
+
+func (a v2Adapter) Currencies() []Currency {
+	return a.p.Currencies()
+}
+
+func (a v2Adapter) Unwrap() PaymentProcessor {
+	return a.p
+}
+
+// V1 lowers a ProcessorV2 to PaymentProcessor for callers that have not
+// moved yet. It also lets native v2 processors run the v1 contract suite.
+// A processor V2 lifted comes back unwrapping to the v1 processor, so it
+// only reports refunds if that processor can refund.
+func V1(p ProcessorV2) PaymentProcessor {
+	if lifted, ok := p.(Unwrapper); ok {
+		return unwrappingV1Adapter{v1Adapter{p}, lifted.Unwrap()}
+	}
+	return v1Adapter{p}
+}
+
+type v1Adapter struct {
+	p ProcessorV2
+}
+
+func (a v1Adapter) ProcessPayment(ctx context.Context, amount float64, currency Currency, idempotencyKey string) (PaymentResult, error) {
+	return a.p.Pay(ctx, PaymentRequest{Amount: amount, Currency: currency, IdempotencyKey: idempotencyKey})
+}
+
+func (a v1Adapter) Refund(ctx context.Context, paymentID string, amount float64) error {
+	_, err := a.p.Refund(ctx, RefundRequest{PaymentID: paymentID, Amount: amount})
+	return err
+}
+
+func (a v1Adapter) Currencies() []Currency {
+	return a.p.Currencies()
+}
+
+// unwrappingV1Adapter lowers a ProcessorV2 that wraps a v1 processor
+type unwrappingV1Adapter struct {
+	v1Adapter
+	inner PaymentProcessor
+}
+
+func (a unwrappingV1Adapter) Unwrap() PaymentProcessor {
+	return a.inner
+}
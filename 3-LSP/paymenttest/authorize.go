@@ -0,0 +1,100 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// authorizer returns the processor as an Authorizer or ErrNotApplicable
+func authorizer(p payment.PaymentProcessor) (payment.Authorizer, error) {
+	a, ok := p.(payment.Authorizer)
+	if !ok {
+		return nil, ErrNotApplicable
+	}
+	return a, nil
+}
+
+// authorizeChecks covers the two-phase flow for processors that support it
+func authorizeChecks() []Check {
+	return []Check{
+		{
+			Name: "AuthorizeThenCapture",
+			Rule: "an authorization is authorized or already captured, and capturing it completes it once",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				a, err := authorizer(p)
+				if err != nil {
+					return err
+				}
+				authorized, err := a.Authorize(context.Background(), 100, firstCurrency(p), "")
+				if err != nil {
+					return fmt.Errorf("Authorize(100) returned error: %w", err)
+				}
+				if authorized.Status != payment.StatusAuthorized && authorized.Status != payment.StatusCaptured {
+					return fmt.Errorf("Authorize(100) status = %q, want authorized or captured", authorized.Status)
+				}
+				for i := 0; i < 2; i++ {
+					captured, err := a.Capture(context.Background(), authorized.ID)
+					if err != nil {
+						return fmt.Errorf("Capture(%q) attempt %d returned error: %w", authorized.ID, i+1, err)
+					}
+					if captured.ID != authorized.ID || captured.Amount != 100 || captured.Status != payment.StatusCaptured {
+						return fmt.Errorf("Capture(%q) = %+v, want captured payment of 100", authorized.ID, captured)
+					}
+				}
+				if err := refund(p, authorized.ID, 100); err != nil {
+					return fmt.Errorf("Refund(%q, 100) after capture returned error: %w", authorized.ID, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RefundBeforeCapture",
+			Rule: "an authorization that is not captured yet cannot be refunded",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				a, err := authorizer(p)
+				if err != nil {
+					return err
+				}
+				r, err := refunder(p)
+				if err != nil {
+					return err
+				}
+				authorized, err := a.Authorize(context.Background(), 100, firstCurrency(p), "")
+				if err != nil {
+					return fmt.Errorf("Authorize(100) returned error: %w", err)
+				}
+				err = r.Refund(context.Background(), authorized.ID, 100)
+				if authorized.Status == payment.StatusAuthorized && !errors.Is(err, payment.ErrNotCaptured) {
+					return fmt.Errorf("Refund(%q) of authorization error = %v, want %v", authorized.ID, err, payment.ErrNotCaptured)
+				}
+				if authorized.Status == payment.StatusCaptured && err != nil {
+					return fmt.Errorf("Refund(%q) of captured authorization returned error: %w", authorized.ID, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "AuthorizeHonorsPreconditions",
+			Rule: "Authorize rejects amounts and currencies exactly like ProcessPayment",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				a, err := authorizer(p)
+				if err != nil {
+					return err
+				}
+				if _, err := a.Authorize(context.Background(), -1, firstCurrency(p), ""); !errors.Is(err, payment.ErrInvalidAmount) {
+					return fmt.Errorf("Authorize(-1) error = %v, want %v", err, payment.ErrInvalidAmount)
+				}
+				if _, err := a.Authorize(context.Background(), 100, "XXX", ""); !errors.Is(err, payment.ErrUnsupportedCurrency) {
+					return fmt.Errorf("Authorize(100, XXX) error = %v, want %v", err, payment.ErrUnsupportedCurrency)
+				}
+				return nil
+			},
+		},
+	}
+}
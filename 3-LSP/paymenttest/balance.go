@@ -0,0 +1,94 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// balanceChecker returns the processor, or the first one it unwraps to,
+// as a BalanceChecker holding a positive balance, or ErrNotApplicable.
+// Decorators do not report balances, but what they wrap spends them.
+func balanceChecker(p payment.PaymentProcessor) (payment.BalanceChecker, float64, error) {
+	b, ok := p.(payment.BalanceChecker)
+	for inner := p; !ok; {
+		u, unwraps := inner.(payment.Unwrapper)
+		if !unwraps {
+			return nil, 0, ErrNotApplicable
+		}
+		inner = u.Unwrap()
+		b, ok = inner.(payment.BalanceChecker)
+	}
+	balance, err := b.Balance(context.Background(), firstCurrency(p))
+	if err != nil {
+		return nil, 0, fmt.Errorf("Balance returned error: %w", err)
+	}
+	if balance <= 0 {
+		return nil, 0, ErrNotApplicable
+	}
+	return b, balance, nil
+}
+
+// balanceChecks cover prepaid processors
+func balanceChecks() []Check {
+	return []Check{
+		{
+			Name: "InsufficientFunds",
+			Rule: "a payment above the balance fails with ErrInsufficientFunds, an ErrDeclined, and leaves the balance alone, unless a partial capture says so in Requested",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				b, balance, err := balanceChecker(p)
+				if err != nil {
+					return err
+				}
+				result, err := pay(p, balance+1, "")
+				if err == nil {
+					if result.Amount != balance || result.Requested != balance+1 {
+						return fmt.Errorf("ProcessPayment(%v) over a balance of %v = %+v, want ErrInsufficientFunds or a partial capture of %v", balance+1, balance, result, balance)
+					}
+					return nil
+				}
+				if !errors.Is(err, payment.ErrInsufficientFunds) {
+					return fmt.Errorf("ProcessPayment(%v) over a balance of %v error = %v, want %v", balance+1, balance, err, payment.ErrInsufficientFunds)
+				}
+				if after, _ := b.Balance(context.Background(), firstCurrency(p)); after != balance {
+					return fmt.Errorf("balance after a declined payment = %v, want %v", after, balance)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RefundRestoresBalance",
+			Rule: "spending the whole balance empties it and a refund, repeated or not, puts the amount back once",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				b, balance, err := balanceChecker(p)
+				if err != nil {
+					return err
+				}
+				r, err := refunder(p)
+				if err != nil {
+					return err
+				}
+				result, err := pay(p, balance, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(%v) of the whole balance returned error: %w", balance, err)
+				}
+				if after, _ := b.Balance(context.Background(), firstCurrency(p)); after != 0 {
+					return fmt.Errorf("balance after spending all of it = %v, want 0", after)
+				}
+				for i := 0; i < 2; i++ {
+					if err := r.Refund(context.Background(), result.ID, balance); err != nil {
+						return fmt.Errorf("Refund(%q, %v) attempt %d returned error: %w", result.ID, balance, i+1, err)
+					}
+				}
+				if after, _ := b.Balance(context.Background(), firstCurrency(p)); after != balance {
+					return fmt.Errorf("balance after refund = %v, want %v", after, balance)
+				}
+				return nil
+			},
+		},
+	}
+}
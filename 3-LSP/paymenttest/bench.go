@@ -0,0 +1,78 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"testing"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// Layer wraps a processor in one cross-cutting concern
+type Layer struct {
+	Name string
+	Wrap func(p payment.PaymentProcessor) payment.PaymentProcessor
+	// Key returns the idempotency key of the i-th payment
+	Key func(i int) string
+}
+
+// LAYERS are what BenchmarkLayers puts in front of each processor. The
+// overhead of a layer is its time minus the time of "bare".
+var LAYERS = []Layer{
+	{Name: "bare"},
+	{Name: "logging", Wrap: func(p payment.PaymentProcessor) payment.PaymentProcessor {
+		return payment.LoggingProcessor{Processor: p, Logger: log.New(io.Discard, "", 0)}
+	}},
+	{Name: "retrying", Wrap: func(p payment.PaymentProcessor) payment.PaymentProcessor {
+		return payment.RetryingProcessor{Processor: p}
+	}},
+	{Name: "idempotent", Key: strconv.Itoa},
+	{Name: "replayed", Key: func(int) string { return "replayed" }},
+	{Name: "retrying(logging)", Wrap: func(p payment.PaymentProcessor) payment.PaymentProcessor {
+		return payment.RetryingProcessor{Processor: payment.LoggingProcessor{Processor: p, Logger: log.New(io.Discard, "", 0)}}
+	}},
+}
+
+// BenchmarkProcessor measures one ProcessPayment per iteration, keyed by
+// key when it is not nil. Declines are counted like any other call, so a
+// prepaid processor that runs dry keeps being measured.
+func BenchmarkProcessor(b *testing.B, p payment.PaymentProcessor, key func(i int) string) {
+	b.Helper()
+	ctx := context.Background()
+	currency := firstCurrency(p)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var idempotencyKey string
+		if key != nil {
+			idempotencyKey = key(i)
+		}
+		if _, err := p.ProcessPayment(ctx, 1, currency, idempotencyKey); err != nil && !errors.Is(err, payment.ErrDeclined) {
+			b.Fatalf("ProcessPayment(1) returned error: %v", err)
+		}
+	}
+}
+
+// BenchmarkLayers measures every registered processor behind each of
+// LAYERS. Call it from a benchmark:
+//
+//	func BenchmarkLayers(b *testing.B) { paymenttest.BenchmarkLayers(b) }
+func BenchmarkLayers(b *testing.B) {
+	for _, name := range payment.Names() {
+		for _, layer := range LAYERS {
+			b.Run(name+"/"+layer.Name, func(b *testing.B) {
+				p, err := payment.New(name)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if layer.Wrap != nil {
+					p = layer.Wrap(p)
+				}
+				BenchmarkProcessor(b, p, layer.Key)
+			})
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// capturer returns the processor as a Capturer or ErrNotApplicable
+func capturer(p payment.PaymentProcessor) (payment.Capturer, error) {
+	c, ok := p.(payment.Capturer)
+	if !ok {
+		return nil, ErrNotApplicable
+	}
+	return c, nil
+}
+
+// settle captures a pending payment through p, or the first processor it
+// unwraps to that is a Capturer, so checks that refund hold for payments
+// that complete later. Decorators do not forward Capture, but what they
+// wrap took the payment. It returns ErrNotApplicable when none can capture.
+func settle(p payment.PaymentProcessor, result payment.PaymentResult) error {
+	if result.Status != payment.StatusPending {
+		return nil
+	}
+	c, ok := p.(payment.Capturer)
+	for inner := p; !ok; {
+		u, unwraps := inner.(payment.Unwrapper)
+		if !unwraps {
+			return ErrNotApplicable
+		}
+		inner = u.Unwrap()
+		c, ok = inner.(payment.Capturer)
+	}
+	if _, err := c.Capture(context.Background(), result.ID); err != nil {
+		return fmt.Errorf("Capture(%q) returned error: %w", result.ID, err)
+	}
+	return nil
+}
+
+// captureChecks covers the Capturer capability for processors that have it
+func captureChecks() []Check {
+	return []Check{
+		{
+			Name: "CaptureCompletesPayment",
+			Rule: "capturing a payment returns it captured with the same ID and amount",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				c, err := capturer(p)
+				if err != nil {
+					return err
+				}
+				result, err := pay(p, 100, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+				}
+				for i := 0; i < 2; i++ {
+					captured, err := c.Capture(context.Background(), result.ID)
+					if err != nil {
+						return fmt.Errorf("Capture(%q) attempt %d returned error: %w", result.ID, i+1, err)
+					}
+					if captured.ID != result.ID || captured.Amount != result.Amount || captured.Status != payment.StatusCaptured {
+						return fmt.Errorf("Capture(%q) = %+v, want captured %+v", result.ID, captured, result)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "CaptureUnknownPayment",
+			Rule: "capturing a payment the processor never made fails with ErrPaymentNotFound",
+			Run: func(newProcessor Factory) error {
+				c, err := capturer(newProcessor())
+				if err != nil {
+					return err
+				}
+				if _, err := c.Capture(context.Background(), "unknown"); !errors.Is(err, payment.ErrPaymentNotFound) {
+					return fmt.Errorf("Capture(unknown) error = %v, want %v", err, payment.ErrPaymentNotFound)
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package paymenttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+const (
+	GOROUTINES           = 8
+	PAYMENTS_PER_ROUTINE = 25
+)
+
+// Hammer runs call from GOROUTINES goroutines at once, PAYMENTS_PER_ROUTINE
+// times in each, g numbering the goroutine and i the call within it.
+// Failures are summed up as the first one and how many more there were.
+// Run it with -race to catch unguarded state.
+func Hammer(call func(g, i int) error) error {
+	var (
+		mu       sync.Mutex
+		first    error
+		failures int
+		wg       sync.WaitGroup
+	)
+	for g := 0; g < GOROUTINES; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < PAYMENTS_PER_ROUTINE; i++ {
+				if err := call(g, i); err != nil {
+					mu.Lock()
+					if first == nil {
+						first = err
+					}
+					failures++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if failures > 1 {
+		return fmt.Errorf("%w (and %d more)", first, failures-1)
+	}
+	return first
+}
+
+// hammer pays 1 through p with Hammer and returns every result
+func hammer(p payment.PaymentProcessor, key func(g, i int) string) ([]payment.PaymentResult, error) {
+	var (
+		mu      sync.Mutex
+		results []payment.PaymentResult
+	)
+	currency := firstCurrency(p)
+	err := Hammer(func(g, i int) error {
+		result, err := p.ProcessPayment(context.Background(), 1, currency, key(g, i))
+		if err != nil {
+			return fmt.Errorf("ProcessPayment(1) returned error: %w", err)
+		}
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+		return nil
+	})
+	return results, err
+}
+
+// concurrencyChecks hold processors to the contract under concurrent use
+func concurrencyChecks() []Check {
+	return []Check{
+		{
+			Name: "ConcurrentPayments",
+			Rule: "payments made at the same time from many goroutines all succeed with IDs of their own",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				results, err := hammer(p, func(int, int) string { return "" })
+				if err != nil {
+					return err
+				}
+				seen := make(map[string]bool)
+				for _, result := range results {
+					if seen[result.ID] {
+						return fmt.Errorf("concurrent payments shared ID %q", result.ID)
+					}
+					seen[result.ID] = true
+				}
+				return nil
+			},
+		},
+		{
+			Name: "ConcurrentBalance",
+			Rule: "a prepaid balance goes down by exactly what concurrent payments captured",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				b, before, err := balanceChecker(p)
+				if err != nil {
+					return err
+				}
+				results, err := hammer(p, func(int, int) string { return "" })
+				if err != nil {
+					return err
+				}
+				after, err := b.Balance(context.Background(), firstCurrency(p))
+				if err != nil {
+					return fmt.Errorf("Balance returned error: %w", err)
+				}
+				if spent := float64(len(results)); before-after != spent {
+					return fmt.Errorf("balance went from %v to %v after capturing %v", before, after, spent)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "ConcurrentIdempotency",
+			Rule: "the same idempotency key sent from many goroutines at once charges once",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				results, err := hammer(p, func(_, i int) string { return fmt.Sprintf("order-%d", i) })
+				if err != nil {
+					return err
+				}
+				ids := make(map[string]bool)
+				for _, result := range results {
+					ids[result.ID] = true
+				}
+				if len(ids) != PAYMENTS_PER_ROUTINE {
+					return fmt.Errorf("%d keys sent %d times each made %d payments, want %d", PAYMENTS_PER_ROUTINE, GOROUTINES, len(ids), PAYMENTS_PER_ROUTINE)
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PROMPT is how long a processor may take to notice a done context
+const PROMPT = 100 * time.Millisecond
+
+// promptly runs call and fails if it returns late or without wantErr
+func promptly(name string, wantErr error, call func() error) error {
+	start := time.Now()
+	err := call()
+	if elapsed := time.Since(start); elapsed > PROMPT {
+		return fmt.Errorf("%s took %v after the context was done, want at most %v", name, elapsed, PROMPT)
+	}
+	if !errors.Is(err, wantErr) {
+		return fmt.Errorf("%s error = %v, want %v", name, err, wantErr)
+	}
+	return nil
+}
+
+// contextChecks covers how processors treat cancellation and deadlines
+func contextChecks() []Check {
+	return []Check{
+		{
+			Name: "ReturnsOnCancellation",
+			Rule: "a canceled context makes every call return context.Canceled promptly",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				result, err := pay(p, 100, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+				}
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				err = promptly("ProcessPayment", context.Canceled, func() error {
+					_, err := p.ProcessPayment(ctx, 100, firstCurrency(p), "")
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				r, err := refunder(p)
+				if err != nil {
+					return nil
+				}
+				return promptly("Refund", context.Canceled, func() error {
+					return r.Refund(ctx, result.ID, 100)
+				})
+			},
+		},
+		{
+			Name: "ReturnsOnDeadline",
+			Rule: "an expired deadline makes ProcessPayment return context.DeadlineExceeded promptly",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+				defer cancel()
+				return promptly("ProcessPayment", context.DeadlineExceeded, func() error {
+					_, err := p.ProcessPayment(ctx, 100, firstCurrency(p), "")
+					return err
+				})
+			},
+		},
+		{
+			Name: "CanceledPaymentsAreNotRemembered",
+			Rule: "a payment canceled by its context does not claim its idempotency key",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				if _, err := p.ProcessPayment(ctx, 100, firstCurrency(p), "order-1"); !errors.Is(err, context.Canceled) {
+					return fmt.Errorf("canceled ProcessPayment(100, order-1) error = %v, want %v", err, context.Canceled)
+				}
+				if _, err := pay(p, 200, "order-1"); err != nil {
+					return fmt.Errorf("ProcessPayment(200, order-1) after cancellation returned error: %w", err)
+				}
+				return nil
+			},
+		},
+	}
+}
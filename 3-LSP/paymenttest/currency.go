@@ -0,0 +1,94 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// pay processes amount in the first currency p accepts
+func pay(p payment.PaymentProcessor, amount float64, idempotencyKey string) (payment.PaymentResult, error) {
+	return p.ProcessPayment(context.Background(), amount, firstCurrency(p), idempotencyKey)
+}
+
+func firstCurrency(p payment.PaymentProcessor) payment.Currency {
+	if currencies := p.Currencies(); len(currencies) > 0 {
+		return currencies[0]
+	}
+	return ""
+}
+
+// currencyChecks covers the currency rules documented on PaymentProcessor
+func currencyChecks() []Check {
+	return []Check{
+		{
+			Name: "DeclaresCurrencies",
+			Rule: "every processor accepts at least one currency and lists each only once",
+			Run: func(newProcessor Factory) error {
+				currencies := newProcessor().Currencies()
+				if len(currencies) == 0 {
+					return fmt.Errorf("Currencies() is empty")
+				}
+				sorted := slices.Clone(currencies)
+				slices.Sort(sorted)
+				if len(slices.Compact(sorted)) != len(currencies) {
+					return fmt.Errorf("Currencies() = %v lists a currency more than once", currencies)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "AcceptsDeclaredCurrencies",
+			Rule: "every currency from Currencies is accepted and echoed in the result",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				for _, currency := range p.Currencies() {
+					result, err := p.ProcessPayment(context.Background(), 100, currency, "")
+					if err != nil {
+						return fmt.Errorf("ProcessPayment(100, %s) returned error: %w", currency, err)
+					}
+					if result.Currency != currency {
+						return fmt.Errorf("ProcessPayment(100, %s) currency = %q", currency, result.Currency)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RejectsUnsupportedCurrency",
+			Rule: "a currency missing from Currencies fails with ErrUnsupportedCurrency",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				candidates := []payment.Currency{"", "XXX", payment.USD, payment.EUR, payment.GBP, payment.BDT, payment.BTC, payment.ETH}
+				for _, currency := range candidates {
+					if slices.Contains(p.Currencies(), currency) {
+						continue
+					}
+					if _, err := p.ProcessPayment(context.Background(), 100, currency, ""); !errors.Is(err, payment.ErrUnsupportedCurrency) {
+						return fmt.Errorf("ProcessPayment(100, %q) error = %v, want %v", currency, err, payment.ErrUnsupportedCurrency)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "IdempotencyKeyBindsCurrency",
+			Rule: "reusing a key for another currency fails with ErrIdempotencyKeyReused",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				if _, err := pay(p, 100, "order-1"); err != nil {
+					return fmt.Errorf("ProcessPayment(100, order-1) returned error: %w", err)
+				}
+				for _, currency := range p.Currencies()[1:] {
+					if _, err := p.ProcessPayment(context.Background(), 100, currency, "order-1"); !errors.Is(err, payment.ErrIdempotencyKeyReused) {
+						return fmt.Errorf("ProcessPayment(100, %s, order-1) error = %v, want %v", currency, err, payment.ErrIdempotencyKeyReused)
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// SENTINELS are the errors a failed payment may match. Anything else leaves
+// callers guessing what went wrong.
+var SENTINELS = []error{
+	payment.ErrInvalidAmount,
+	payment.ErrUnsupportedCurrency,
+	payment.ErrDeclined,
+	payment.ErrDuplicate,
+	payment.ErrTransient,
+	context.Canceled,
+	context.DeadlineExceeded,
+}
+
+// classified reports whether err matches one of SENTINELS
+func classified(err error) bool {
+	for _, sentinel := range SENTINELS {
+		if errors.Is(err, sentinel) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorChecks hold every processor to the shared error taxonomy
+func errorChecks() []Check {
+	return []Check{
+		{
+			Name: "ErrorTaxonomy",
+			Rule: "equivalent failures match the same sentinel whatever the processor",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				if _, err := pay(p, -1, ""); !errors.Is(err, payment.ErrInvalidAmount) {
+					return fmt.Errorf("ProcessPayment(-1) error = %v, want %v", err, payment.ErrInvalidAmount)
+				}
+				if _, err := p.ProcessPayment(context.Background(), 100, "XXX", ""); !errors.Is(err, payment.ErrUnsupportedCurrency) {
+					return fmt.Errorf("ProcessPayment(100, XXX) error = %v, want %v", err, payment.ErrUnsupportedCurrency)
+				}
+				if _, err := pay(p, 100, "dup"); err != nil {
+					return fmt.Errorf("ProcessPayment(100, dup) returned error: %w", err)
+				}
+				if _, err := pay(p, 50, "dup"); !errors.Is(err, payment.ErrDuplicate) {
+					return fmt.Errorf("ProcessPayment(50, dup) error = %v, want %v", err, payment.ErrDuplicate)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "ErrorsAreClassified",
+			Rule: "every failed ProcessPayment matches one of SENTINELS",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				for _, amount := range []float64{0, -1, math.NaN(), math.Inf(1), 0.01, 1e12, math.MaxFloat64} {
+					for _, currency := range append(p.Currencies(), "", "XXX") {
+						_, err := p.ProcessPayment(context.Background(), amount, currency, "")
+						if err != nil && !classified(err) {
+							return fmt.Errorf("ProcessPayment(%v, %q) error = %v, want one of the shared sentinels", amount, currency, err)
+						}
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
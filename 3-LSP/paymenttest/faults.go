@@ -0,0 +1,66 @@
+package paymenttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// spy records the ID of every payment the wrapped processor made
+type spy struct {
+	payment.PaymentProcessor
+
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+func (s *spy) ProcessPayment(ctx context.Context, amount float64, currency payment.Currency, idempotencyKey string) (payment.PaymentResult, error) {
+	result, err := s.PaymentProcessor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+	if err == nil {
+		s.mu.Lock()
+		if s.ids == nil {
+			s.ids = make(map[string]bool)
+		}
+		s.ids[result.ID] = true
+		s.mu.Unlock()
+	}
+	return result, err
+}
+
+// faultChecks run the processor behind injected faults and retries
+func faultChecks() []Check {
+	return []Check{
+		{
+			Name: "SurvivesFaults",
+			Rule: "behind retries, keyed payments survive timeouts, transient failures and duplicated requests and are charged exactly once",
+			Run: func(newProcessor Factory) error {
+				made := &spy{PaymentProcessor: newProcessor()}
+				faulty := &payment.FaultyProcessor{
+					Processor: made,
+					Schedule:  []payment.Fault{payment.FaultTimeout, payment.FaultTransient, payment.FaultDuplicate, payment.FaultTimeout, payment.FaultDuplicate},
+				}
+				p := payment.RetryingProcessor{Processor: faulty, Backoff: time.Millisecond}
+				returned := make(map[string]bool)
+				for _, key := range []string{"order-1", "order-2", "order-3"} {
+					result, err := pay(p, 100, key)
+					if err != nil {
+						return fmt.Errorf("ProcessPayment(100, %s) returned error: %w", key, err)
+					}
+					returned[result.ID] = true
+				}
+				if len(made.ids) != len(returned) {
+					return fmt.Errorf("3 keyed payments made %d payments, want 3", len(made.ids))
+				}
+				for id := range returned {
+					if !made.ids[id] {
+						return fmt.Errorf("returned payment %q the processor never made", id)
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package paymenttest
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// FEE_TOLERANCE absorbs float rounding when comparing fees
+const FEE_TOLERANCE = 1e-9
+
+// feeChecks require every processor to report its fee next to the full
+// amount instead of quietly paying out less than the customer was charged
+func feeChecks() []Check {
+	return []Check{
+		{
+			Name: "ReportsFees",
+			Rule: "a payment keeps the requested Amount and reports Fee >= 0 and Net = Amount - Fee",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				result, err := pay(p, 100, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+				}
+				if result.Amount != 100 {
+					return fmt.Errorf("Amount = %v, want 100 with the fee reported separately", result.Amount)
+				}
+				if result.Fee < 0 {
+					return fmt.Errorf("Fee = %v, want >= 0", result.Fee)
+				}
+				if math.Abs(result.Net-(result.Amount-result.Fee)) > FEE_TOLERANCE {
+					return fmt.Errorf("Net = %v, want Amount - Fee = %v", result.Net, result.Amount-result.Fee)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "FeesMatchSchedule",
+			Rule: "a FeeReporter charges exactly what its published FeeSchedule says",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				reporter, ok := p.(payment.FeeReporter)
+				if !ok {
+					return ErrNotApplicable
+				}
+				for _, amount := range []float64{1, 100, 2500} {
+					result, err := pay(p, amount, "")
+					if err != nil {
+						return fmt.Errorf("ProcessPayment(%v) returned error: %w", amount, err)
+					}
+					if want := reporter.Fees().Fee(amount); math.Abs(result.Fee-want) > FEE_TOLERANCE {
+						return fmt.Errorf("ProcessPayment(%v) Fee = %v, want %v from %+v", amount, result.Fee, want, reporter.Fees())
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// Fuzz feeds random amounts and currencies to every registered processor
+// and fails when one of them breaks an invariant. Call it from a fuzz target:
+//
+//	func FuzzProcessors(f *testing.F) { paymenttest.Fuzz(f) }
+func Fuzz(f *testing.F) {
+	names := payment.Names()
+	for i := range names {
+		for _, amount := range []float64{100, 0.01, 0, -1, math.MaxFloat64, math.NaN(), math.Inf(1)} {
+			for _, currency := range []string{"USD", "BDT", "BTC", "", "usd", "XXX"} {
+				f.Add(amount, currency, uint8(i))
+			}
+		}
+	}
+	f.Fuzz(func(t *testing.T, amount float64, currency string, which uint8) {
+		name := names[int(which)%len(names)]
+		p, err := payment.New(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := CheckInvariants(p, amount, payment.Currency(currency)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	})
+}
+
+// CheckInvariants processes one payment and checks the rules that hold for
+// any input: valid input succeeds with a matching result and can be refunded,
+// invalid input fails with the shared sentinel error, and nothing panics.
+// Prepaid processors may also decline valid input with ErrInsufficientFunds.
+func CheckInvariants(p payment.PaymentProcessor, amount float64, currency payment.Currency) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("ProcessPayment(%v, %q) panicked: %v", amount, currency, r)
+		}
+	}()
+
+	validAmount := amount > 0 && !math.IsInf(amount, 0)
+	supported := slices.Contains(p.Currencies(), currency)
+	result, err := p.ProcessPayment(context.Background(), amount, currency, "")
+	switch {
+	case !validAmount:
+		if !errors.Is(err, payment.ErrInvalidAmount) {
+			return fmt.Errorf("ProcessPayment(%v, %q) error = %v, want %v", amount, currency, err, payment.ErrInvalidAmount)
+		}
+		return nil
+	case !supported:
+		if !errors.Is(err, payment.ErrUnsupportedCurrency) {
+			return fmt.Errorf("ProcessPayment(%v, %q) error = %v, want %v", amount, currency, err, payment.ErrUnsupportedCurrency)
+		}
+		return nil
+	case errors.Is(err, payment.ErrInsufficientFunds):
+		return nil
+	case err != nil:
+		return fmt.Errorf("ProcessPayment(%v, %q) returned error: %w", amount, currency, err)
+	}
+
+	if result.ID == "" || result.Amount != amount || result.Currency != currency {
+		return fmt.Errorf("ProcessPayment(%v, %q) = %+v, want a result for that payment", amount, currency, result)
+	}
+	if err := settle(p, result); err != nil {
+		return err
+	}
+	if err := refund(p, result.ID, amount); err != nil {
+		return fmt.Errorf("Refund(%q, %v) returned error: %w", result.ID, amount, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package paymenttest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// idempotencyChecks covers the idempotency key semantics documented on PaymentProcessor
+func idempotencyChecks() []Check {
+	return []Check{
+		{
+			Name: "IdempotentResubmission",
+			Rule: "resubmitting a payment with the same key returns the original result",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				first, err := pay(p, 100, "order-1")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(100, order-1) returned error: %w", err)
+				}
+				for i := 0; i < 3; i++ {
+					again, err := pay(p, 100, "order-1")
+					if err != nil {
+						return fmt.Errorf("resubmitted ProcessPayment(100, order-1) returned error: %w", err)
+					}
+					if again != first {
+						return fmt.Errorf("resubmitted ProcessPayment(100, order-1) = %+v, want %+v", again, first)
+					}
+				}
+				// The resubmission did not capture a second payment to refund
+				if err := settle(p, first); err != nil {
+					return err
+				}
+				if err := refund(p, first.ID, 100); err != nil {
+					return fmt.Errorf("Refund(%q, 100) returned error: %w", first.ID, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "DistinctKeysAreDistinctPayments",
+			Rule: "different keys, or no key at all, always make new payments",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				seen := make(map[string]bool)
+				for _, key := range []string{"order-1", "order-2", "", ""} {
+					result, err := pay(p, 100, key)
+					if err != nil {
+						return fmt.Errorf("ProcessPayment(100, %q) returned error: %w", key, err)
+					}
+					if seen[result.ID] {
+						return fmt.Errorf("ProcessPayment(100, %q) reused payment %q", key, result.ID)
+					}
+					seen[result.ID] = true
+				}
+				return nil
+			},
+		},
+		{
+			Name: "IdempotencyKeyReused",
+			Rule: "reusing a key for a different amount fails with ErrIdempotencyKeyReused",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				if _, err := pay(p, 100, "order-1"); err != nil {
+					return fmt.Errorf("ProcessPayment(100, order-1) returned error: %w", err)
+				}
+				if _, err := pay(p, 200, "order-1"); !errors.Is(err, payment.ErrIdempotencyKeyReused) {
+					return fmt.Errorf("ProcessPayment(200, order-1) error = %v, want %v", err, payment.ErrIdempotencyKeyReused)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "FailedPaymentsAreNotRemembered",
+			Rule: "a rejected payment does not claim its key",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				if _, err := pay(p, -1, "order-1"); !errors.Is(err, payment.ErrInvalidAmount) {
+					return fmt.Errorf("ProcessPayment(-1, order-1) error = %v, want %v", err, payment.ErrInvalidAmount)
+				}
+				if _, err := pay(p, 100, "order-1"); err != nil {
+					return fmt.Errorf("ProcessPayment(100, order-1) after rejection returned error: %w", err)
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// installmentChecks covers the installment plan semantics every processor must share
+func installmentChecks() []Check {
+	return []Check{
+		{
+			Name: "InstallmentsCompletePlan",
+			Rule: "a plan is paid installment by installment, in order, until it is complete",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				plan, err := payment.NewInstallmentPlan(100, firstCurrency(p), 3)
+				if err != nil {
+					return fmt.Errorf("NewInstallmentPlan(100, 3) returned error: %w", err)
+				}
+				for i, installment := range plan.Installments {
+					if plan.Complete() {
+						return fmt.Errorf("plan complete after %d of 3 installments", i)
+					}
+					result, err := plan.PayNext(context.Background(), p, "plan-1")
+					if err != nil {
+						return fmt.Errorf("PayNext() for installment %d returned error: %w", i+1, err)
+					}
+					if result.Amount != installment.Amount {
+						return fmt.Errorf("installment %d paid %v, want %v", i+1, result.Amount, installment.Amount)
+					}
+				}
+				if !plan.Complete() || plan.Remaining() != 0 {
+					return fmt.Errorf("plan not complete after all installments, %v remaining", plan.Remaining())
+				}
+				if _, err := plan.PayNext(context.Background(), p, "plan-1"); !errors.Is(err, payment.ErrPlanComplete) {
+					return fmt.Errorf("PayNext() on complete plan error = %v, want %v", err, payment.ErrPlanComplete)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "FailedInstallmentIsRetried",
+			Rule: "a failed installment stays unpaid and is the next one tried",
+			Run: func(newProcessor Factory) error {
+				plan, err := payment.NewInstallmentPlan(90, "XXX", 3)
+				if err != nil {
+					return fmt.Errorf("NewInstallmentPlan(90, 3) returned error: %w", err)
+				}
+				if _, err := plan.PayNext(context.Background(), newProcessor(), ""); !errors.Is(err, payment.ErrUnsupportedCurrency) {
+					return fmt.Errorf("PayNext() in XXX error = %v, want %v", err, payment.ErrUnsupportedCurrency)
+				}
+				if plan.Installments[0].Paid || plan.Remaining() != 90 {
+					return fmt.Errorf("failed installment changed the plan: %+v", plan.Installments)
+				}
+				return nil
+			},
+		},
+	}
+}
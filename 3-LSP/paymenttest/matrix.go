@@ -0,0 +1,156 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// Scenario is one situation every processor must handle alike
+type Scenario struct {
+	Name string
+	Run  func(p payment.PaymentProcessor) error
+}
+
+// SCENARIOS are the columns of the cross-processor matrix
+var SCENARIOS = []Scenario{
+	{"valid payment", func(p payment.PaymentProcessor) error {
+		if _, err := pay(p, 100, ""); err != nil {
+			return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+		}
+		return nil
+	}},
+	{"zero amount", func(p payment.PaymentProcessor) error {
+		if _, err := pay(p, 0, ""); !errors.Is(err, payment.ErrInvalidAmount) {
+			return fmt.Errorf("ProcessPayment(0) error = %v, want %v", err, payment.ErrInvalidAmount)
+		}
+		return nil
+	}},
+	{"huge amount", func(p payment.PaymentProcessor) error {
+		if _, err := pay(p, 1e12, ""); err != nil && !errors.Is(err, payment.ErrDeclined) {
+			return fmt.Errorf("ProcessPayment(1e12) error = %v, want a payment or %v", err, payment.ErrDeclined)
+		}
+		return nil
+	}},
+	{"duplicate key", func(p payment.PaymentProcessor) error {
+		first, err := pay(p, 100, "order-1")
+		if err != nil {
+			return fmt.Errorf("ProcessPayment(100, order-1) returned error: %w", err)
+		}
+		if again, err := pay(p, 100, "order-1"); err != nil || again.ID != first.ID {
+			return fmt.Errorf("resent ProcessPayment(100, order-1) = %q, %v, want %q", again.ID, err, first.ID)
+		}
+		if _, err := pay(p, 50, "order-1"); !errors.Is(err, payment.ErrDuplicate) {
+			return fmt.Errorf("ProcessPayment(50, order-1) error = %v, want %v", err, payment.ErrDuplicate)
+		}
+		return nil
+	}},
+	{"cancellation", func(p payment.PaymentProcessor) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return promptly("ProcessPayment", context.Canceled, func() error {
+			_, err := p.ProcessPayment(ctx, 100, firstCurrency(p), "")
+			return err
+		})
+	}},
+}
+
+// Matrix holds the outcome of every scenario against every processor.
+// Errs[i][j] is the failure of SCENARIOS[j] against Processors[i].
+type Matrix struct {
+	Processors []string
+	Errs       [][]error
+}
+
+// RunMatrix runs SCENARIOS against a fresh processor registered under each
+// of names
+func RunMatrix(names []string) (Matrix, error) {
+	m := Matrix{Processors: names}
+	for _, name := range names {
+		row := make([]error, len(SCENARIOS))
+		for j, s := range SCENARIOS {
+			p, err := payment.New(name)
+			if err != nil {
+				return Matrix{}, err
+			}
+			row[j] = scenario(s, p)
+		}
+		m.Errs = append(m.Errs, row)
+	}
+	return m, nil
+}
+
+// Passed reports whether every processor handled every scenario
+func (m Matrix) Passed() bool {
+	for _, row := range m.Errs {
+		for _, err := range row {
+			if err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Render writes the matrix as a table with a row per processor and a
+// column per scenario
+func (m Matrix) Render(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "processor")
+	for _, s := range SCENARIOS {
+		fmt.Fprintf(tw, "\t%s", s.Name)
+	}
+	fmt.Fprintln(tw)
+	for i, name := range m.Processors {
+		fmt.Fprint(tw, name)
+		for _, err := range m.Errs[i] {
+			if err != nil {
+				fmt.Fprint(tw, "\tFAIL")
+			} else {
+				fmt.Fprint(tw, "\tpass")
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// RunScenarioMatrix runs SCENARIOS against every registered processor as
+// subtests and logs the rendered matrix. Call it from a test:
+//
+//	func TestScenarioMatrix(t *testing.T) { paymenttest.RunScenarioMatrix(t) }
+func RunScenarioMatrix(t *testing.T) {
+	t.Helper()
+	m, err := RunMatrix(payment.Names())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range m.Processors {
+		for j, s := range SCENARIOS {
+			t.Run(name+"/"+s.Name, func(t *testing.T) {
+				if err := m.Errs[i][j]; err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+	var table strings.Builder
+	m.Render(&table)
+	t.Logf("\n%s", table.String())
+}
+
+// scenario runs s and turns a panic into a failure
+func scenario(s Scenario, p payment.PaymentProcessor) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	return s.Run(p)
+}
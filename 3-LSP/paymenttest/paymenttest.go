@@ -0,0 +1,203 @@
+// Package paymenttest holds the behavioral contract every PaymentProcessor
+// must honor. A new processor proves it is substitutable by running the suite.
+package paymenttest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// ErrNotApplicable is returned by checks for capabilities a processor does not have
+var ErrNotApplicable = errors.New("paymenttest: check does not apply")
+
+// Factory builds a fresh processor for each check
+type Factory func() payment.PaymentProcessor
+
+// Check is one clause of the processor contract
+type Check struct {
+	Name string
+	// Rule states the clause in plain words
+	Rule string
+	Run  func(newProcessor Factory) error
+}
+
+// Result is the outcome of one check against one processor
+type Result struct {
+	Check Check
+	Err   error
+}
+
+// RunProcessorSuite checks the shared contract against processors built by newProcessor
+func RunProcessorSuite(t *testing.T, newProcessor func() payment.PaymentProcessor) {
+	t.Helper()
+	for _, c := range Checks() {
+		t.Run(c.Name, func(t *testing.T) {
+			err := run(c, newProcessor)
+			if errors.Is(err, ErrNotApplicable) {
+				t.Skip(err)
+			}
+			if err != nil {
+				t.Errorf("%s: %v", c.Rule, err)
+			}
+		})
+	}
+}
+
+// Verify runs every check outside of a test and reports each outcome.
+// Checks for capabilities the processor lacks report ErrNotApplicable.
+func Verify(newProcessor func() payment.PaymentProcessor) []Result {
+	var results []Result
+	for _, c := range Checks() {
+		results = append(results, Result{Check: c, Err: run(c, newProcessor)})
+	}
+	return results
+}
+
+// Checks lists the whole contract in the order it is verified
+func Checks() []Check {
+	var checks []Check
+	checks = append(checks, processChecks()...)
+	checks = append(checks, currencyChecks()...)
+	checks = append(checks, refundChecks()...)
+	checks = append(checks, captureChecks()...)
+	checks = append(checks, authorizeChecks()...)
+	checks = append(checks, voidChecks()...)
+	checks = append(checks, installmentChecks()...)
+	checks = append(checks, contextChecks()...)
+	checks = append(checks, feeChecks()...)
+	checks = append(checks, statusChecks()...)
+	checks = append(checks, balanceChecks()...)
+	checks = append(checks, errorChecks()...)
+	checks = append(checks, concurrencyChecks()...)
+	checks = append(checks, faultChecks()...)
+	return append(checks, idempotencyChecks()...)
+}
+
+// run executes a check and turns a panic into a failure
+func run(c Check, newProcessor Factory) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	return c.Run(newProcessor)
+}
+
+func processChecks() []Check {
+	return []Check{
+		{
+			Name: "AcceptsPositiveAmounts",
+			Rule: "any positive amount is processed, prepaid processors only decline what their balance cannot cover",
+			Run: func(newProcessor Factory) error {
+				for _, amount := range []float64{0.01, 1, 99, 500, 1000, 1e9} {
+					p := newProcessor()
+					_, err := pay(p, amount, "")
+					if _, balance, berr := balanceChecker(p); berr == nil && amount > balance && errors.Is(err, payment.ErrInsufficientFunds) {
+						continue
+					}
+					if err != nil {
+						return fmt.Errorf("ProcessPayment(%v) returned error: %w", amount, err)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "PopulatesResult",
+			Rule: "every result carries an ID, the method, the requested amount and currency, a captured or pending status and a timestamp",
+			Run: func(newProcessor Factory) error {
+				before := time.Now()
+				result, err := pay(newProcessor(), 125.5, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(125.5) returned error: %w", err)
+				}
+				switch {
+				case result.ID == "":
+					return fmt.Errorf("ProcessPayment(125.5) result has no ID")
+				case result.Method == "":
+					return fmt.Errorf("ProcessPayment(125.5) result has no method")
+				case result.Amount != 125.5:
+					return fmt.Errorf("ProcessPayment(125.5) amount = %v, want 125.5", result.Amount)
+				case result.Currency == "":
+					return fmt.Errorf("ProcessPayment(125.5) result has no currency")
+				case result.Status != payment.StatusCaptured && result.Status != payment.StatusPending:
+					return fmt.Errorf("ProcessPayment(125.5) status = %q, want captured or pending", result.Status)
+				case result.Timestamp.Before(before) || result.Timestamp.After(time.Now()):
+					return fmt.Errorf("ProcessPayment(125.5) timestamp = %v, want the time of processing", result.Timestamp)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RejectsNonPositiveAmounts",
+			Rule: "zero, negative and non-finite amounts fail with ErrInvalidAmount",
+			Run: func(newProcessor Factory) error {
+				for _, amount := range []float64{0, -0.01, -1000, math.NaN(), math.Inf(1), math.Inf(-1)} {
+					if _, err := pay(newProcessor(), amount, ""); !errors.Is(err, payment.ErrInvalidAmount) {
+						return fmt.Errorf("ProcessPayment(%v) error = %v, want %v", amount, err, payment.ErrInvalidAmount)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "Deterministic",
+			Rule: "the same amount always yields the same result apart from its ID and timestamp",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				first, err := pay(p, 250, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(250) returned error: %w", err)
+				}
+				same := func(a, b payment.PaymentResult) bool {
+					a.ID, b.ID = "", ""
+					a.Timestamp, b.Timestamp = time.Time{}, time.Time{}
+					return a == b
+				}
+				for i := 0; i < 3; i++ {
+					if got, _ := pay(p, 250, ""); !same(got, first) {
+						return fmt.Errorf("ProcessPayment(250) = %+v, want %+v", got, first)
+					}
+				}
+				if got, _ := pay(newProcessor(), 250, ""); !same(got, first) {
+					return fmt.Errorf("fresh processor: ProcessPayment(250) = %+v, want %+v", got, first)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "UniquePaymentIDs",
+			Rule: "every payment gets a non-empty ID the processor never reuses",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				seen := make(map[string]bool)
+				for i := 0; i < 10; i++ {
+					result, err := pay(p, 100, "")
+					if err != nil {
+						return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+					}
+					if result.ID == "" || seen[result.ID] {
+						return fmt.Errorf("ProcessPayment(100) returned empty or reused ID %q", result.ID)
+					}
+					seen[result.ID] = true
+				}
+				return nil
+			},
+		},
+		{
+			Name: "DoesNotPanic",
+			Rule: "extreme amounts return a result or an error instead of panicking",
+			Run: func(newProcessor Factory) error {
+				for _, amount := range []float64{math.MaxFloat64, math.SmallestNonzeroFloat64, -math.MaxFloat64} {
+					pay(newProcessor(), amount, "")
+				}
+				return nil
+			},
+		},
+	}
+}
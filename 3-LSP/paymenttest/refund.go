@@ -0,0 +1,203 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// refunder returns the processor as a Refunder or ErrNotApplicable when it
+// cannot refund, either because it is no Refunder or because it decorates
+// a processor that is none
+func refunder(p payment.PaymentProcessor) (payment.Refunder, error) {
+	if !payment.CanRefund(p) {
+		return nil, ErrNotApplicable
+	}
+	return p.(payment.Refunder), nil
+}
+
+// refund refunds through p if it can refund and does nothing otherwise, for
+// checks where the refund is one step among others
+func refund(p payment.PaymentProcessor, paymentID string, amount float64) error {
+	r, err := refunder(p)
+	if err != nil {
+		return nil
+	}
+	return r.Refund(context.Background(), paymentID, amount)
+}
+
+// paid returns a Refunder holding one captured payment of 100, a pending
+// payment is settled first
+func paid(newProcessor Factory) (payment.Refunder, string, error) {
+	p := newProcessor()
+	r, err := refunder(p)
+	if err != nil {
+		return nil, "", err
+	}
+	result, err := pay(p, 100, "")
+	if err != nil {
+		return nil, "", fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+	}
+	if err := settle(p, result); err != nil {
+		return nil, "", err
+	}
+	return r, result.ID, nil
+}
+
+// refundChecks covers the refund semantics documented on Refunder
+func refundChecks() []Check {
+	return []Check{
+		{
+			Name: "RefundFullAmount",
+			Rule: "the whole captured amount can be refunded",
+			Run: func(newProcessor Factory) error {
+				p, id, err := paid(newProcessor)
+				if err != nil {
+					return err
+				}
+				if err := p.Refund(context.Background(), id, 100); err != nil {
+					return fmt.Errorf("Refund(%q, 100) returned error: %w", id, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RefundPartialAmount",
+			Rule: "part of the captured amount can be refunded",
+			Run: func(newProcessor Factory) error {
+				p, id, err := paid(newProcessor)
+				if err != nil {
+					return err
+				}
+				if err := p.Refund(context.Background(), id, 40); err != nil {
+					return fmt.Errorf("Refund(%q, 40) returned error: %w", id, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RefundMoreThanCaptured",
+			Rule: "refunding more than was captured fails with ErrRefundExceedsCapture and changes nothing",
+			Run: func(newProcessor Factory) error {
+				p, id, err := paid(newProcessor)
+				if err != nil {
+					return err
+				}
+				if err := p.Refund(context.Background(), id, 100.01); !errors.Is(err, payment.ErrRefundExceedsCapture) {
+					return fmt.Errorf("Refund(%q, 100.01) error = %v, want %v", id, err, payment.ErrRefundExceedsCapture)
+				}
+				if err := p.Refund(context.Background(), id, 100); err != nil {
+					return fmt.Errorf("Refund(%q, 100) after rejected refund returned error: %w", id, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RefundPendingPayment",
+			Rule: "a pending payment cannot be refunded until it is captured",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				r, err := refunder(p)
+				if err != nil {
+					return err
+				}
+				result, err := pay(p, 100, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+				}
+				if result.Status != payment.StatusPending {
+					return ErrNotApplicable
+				}
+				if err := r.Refund(context.Background(), result.ID, 100); !errors.Is(err, payment.ErrNotCaptured) {
+					return fmt.Errorf("Refund(%q) of a pending payment error = %v, want %v", result.ID, err, payment.ErrNotCaptured)
+				}
+				// processors that cannot capture refund once the payment completes
+				err = settle(p, result)
+				if errors.Is(err, ErrNotApplicable) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if err := r.Refund(context.Background(), result.ID, 100); err != nil {
+					return fmt.Errorf("Refund(%q) after Capture returned error: %w", result.ID, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RefundInvalidAmount",
+			Rule: "refund amounts follow the same rules as payments",
+			Run: func(newProcessor Factory) error {
+				p, id, err := paid(newProcessor)
+				if err != nil {
+					return err
+				}
+				for _, amount := range []float64{0, -1, math.NaN()} {
+					if err := p.Refund(context.Background(), id, amount); !errors.Is(err, payment.ErrInvalidAmount) {
+						return fmt.Errorf("Refund(%q, %v) error = %v, want %v", id, amount, err, payment.ErrInvalidAmount)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RefundUnknownPayment",
+			Rule: "refunding a payment the processor never made fails with ErrPaymentNotFound",
+			Run: func(newProcessor Factory) error {
+				p, err := refunder(newProcessor())
+				if err != nil {
+					return err
+				}
+				if err := p.Refund(context.Background(), "unknown", 10); !errors.Is(err, payment.ErrPaymentNotFound) {
+					return fmt.Errorf("Refund(unknown) error = %v, want %v", err, payment.ErrPaymentNotFound)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RefundIsIdempotent",
+			Rule: "repeating a refund is a no-op, refunding again with another amount fails with ErrAlreadyRefunded",
+			Run: func(newProcessor Factory) error {
+				p, id, err := paid(newProcessor)
+				if err != nil {
+					return err
+				}
+				for i := 0; i < 3; i++ {
+					if err := p.Refund(context.Background(), id, 60); err != nil {
+						return fmt.Errorf("Refund(%q, 60) attempt %d returned error: %w", id, i+1, err)
+					}
+				}
+				if err := p.Refund(context.Background(), id, 40); !errors.Is(err, payment.ErrAlreadyRefunded) {
+					return fmt.Errorf("Refund(%q, 40) after refund error = %v, want %v", id, err, payment.ErrAlreadyRefunded)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "RefundUnsupported",
+			Rule: "a decorator of a processor that cannot refund answers ErrUnsupported for every payment instead of refunding some",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				r, ok := p.(payment.Refunder)
+				if !ok {
+					return ErrNotApplicable
+				}
+				if _, err := refunder(p); err == nil {
+					return ErrNotApplicable
+				}
+				result, err := pay(p, 100, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+				}
+				if err := r.Refund(context.Background(), result.ID, 100); !errors.Is(err, payment.ErrUnsupported) {
+					return fmt.Errorf("Refund(%q, 100) error = %v, want %v", result.ID, err, payment.ErrUnsupported)
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package paymenttest
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Clause is one check of a behavioral specification as it was executed
+type Clause struct {
+	Name string
+	Rule string
+	// Outcome is "holds", "fails" or "not applicable"
+	Outcome string
+	// Detail explains a failure
+	Detail string
+}
+
+// Spec is the behavioral specification of one processor, generated from
+// the checks it was run against rather than written by hand
+type Spec struct {
+	Processor string
+	Clauses   []Clause
+}
+
+// NewSpec builds the specification of processor from the results of Verify
+func NewSpec(processor string, results []Result) Spec {
+	spec := Spec{Processor: processor}
+	for _, r := range results {
+		clause := Clause{Name: r.Check.Name, Rule: r.Check.Rule, Outcome: "holds"}
+		switch {
+		case errors.Is(r.Err, ErrNotApplicable):
+			clause.Outcome = "not applicable"
+		case r.Err != nil:
+			clause.Outcome, clause.Detail = "fails", r.Err.Error()
+		}
+		spec.Clauses = append(spec.Clauses, clause)
+	}
+	return spec
+}
+
+// Markdown writes the specification as a markdown document
+func (s Spec) Markdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", s.Processor)
+	fmt.Fprintf(&b, "Behavior of the %s processor as verified by the conformance suite.\n\n", s.Processor)
+	b.WriteString("| Check | Rule | Outcome |\n|---|---|---|\n")
+	for _, c := range s.Clauses {
+		outcome := c.Outcome
+		if c.Detail != "" {
+			outcome += ": " + c.Detail
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", c.Name, markdownCell(c.Rule), markdownCell(outcome))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// HTML writes the specification as a standalone HTML page
+func (s Spec) HTML(w io.Writer) error {
+	return specPage.Execute(w, s)
+}
+
+// markdownCell keeps text from breaking out of a table cell
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
+
+var specPage = template.Must(template.New("spec").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Processor}}</title></head>
+<body>
+<h1>{{.Processor}}</h1>
+<p>Behavior of the {{.Processor}} processor as verified by the conformance suite.</p>
+<table>
+<tr><th>Check</th><th>Rule</th><th>Outcome</th></tr>
+{{- range .Clauses}}
+<tr><td>{{.Name}}</td><td>{{.Rule}}</td><td>{{.Outcome}}{{with .Detail}}: {{.}}{{end}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
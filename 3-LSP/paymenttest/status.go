@@ -0,0 +1,64 @@
+package paymenttest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// statusChecks hold every processor to the shared payment lifecycle
+func statusChecks() []Check {
+	return []Check{
+		{
+			Name: "LegalTransitions",
+			Rule: "the statuses a payment goes through on capture and refund are legal transitions, ending in refunded if it can be refunded",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				result, err := pay(p, 100, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+				}
+				statuses := []payment.Status{result.Status}
+				if c, err := capturer(p); err == nil {
+					captured, err := c.Capture(context.Background(), result.ID)
+					if err != nil {
+						return fmt.Errorf("Capture(%q) returned error: %w", result.ID, err)
+					}
+					statuses = append(statuses, captured.Status)
+				}
+				r, err := refunder(p)
+				if err != nil {
+					return check(statuses, result.ID)
+				}
+				if err := settle(p, result); err != nil {
+					return err
+				}
+				if err := r.Refund(context.Background(), result.ID, 100); err != nil {
+					return fmt.Errorf("Refund(%q, 100) returned error: %w", result.ID, err)
+				}
+				if s, ok := p.(payment.StatusChecker); ok {
+					status, err := s.Status(context.Background(), result.ID)
+					if err != nil {
+						return fmt.Errorf("Status(%q) returned error: %w", result.ID, err)
+					}
+					if status != payment.StatusRefunded {
+						return fmt.Errorf("Status(%q) after refund = %q, want %q", result.ID, status, payment.StatusRefunded)
+					}
+					statuses = append(statuses, status)
+				}
+				return check(statuses, result.ID)
+			},
+		},
+	}
+}
+
+// check fails unless every step between statuses is a legal transition
+func check(statuses []payment.Status, paymentID string) error {
+	for i := 1; i < len(statuses); i++ {
+		if !statuses[i-1].CanTransition(statuses[i]) {
+			return fmt.Errorf("payment %q went from %q to %q", paymentID, statuses[i-1], statuses[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// voider returns the processor as a Voider or ErrNotApplicable
+func voider(p payment.PaymentProcessor) (payment.Voider, error) {
+	v, ok := p.(payment.Voider)
+	if !ok {
+		return nil, ErrNotApplicable
+	}
+	return v, nil
+}
+
+// voidChecks covers the Voider capability for processors that have it
+func voidChecks() []Check {
+	return []Check{
+		{
+			Name: "VoidCapturedPayment",
+			Rule: "voiding a captured payment fails with ErrAlreadyCaptured and leaves it refundable",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				v, err := voider(p)
+				if err != nil {
+					return err
+				}
+				result, err := pay(p, 100, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+				}
+				if result.Status == payment.StatusCaptured {
+					if err := v.Void(context.Background(), result.ID); !errors.Is(err, payment.ErrAlreadyCaptured) {
+						return fmt.Errorf("Void(%q) error = %v, want %v", result.ID, err, payment.ErrAlreadyCaptured)
+					}
+				}
+				if err := settle(p, result); err != nil {
+					return err
+				}
+				if err := refund(p, result.ID, 100); err != nil {
+					return fmt.Errorf("Refund(%q, 100) returned error: %w", result.ID, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "VoidAuthorization",
+			Rule: "voiding an authorization is idempotent and stops it from being captured or refunded",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				v, err := voider(p)
+				if err != nil {
+					return err
+				}
+				a, err := authorizer(p)
+				if err != nil {
+					return err
+				}
+				authorized, err := a.Authorize(context.Background(), 100, firstCurrency(p), "")
+				if err != nil {
+					return fmt.Errorf("Authorize(100) returned error: %w", err)
+				}
+				if authorized.Status != payment.StatusAuthorized {
+					return ErrNotApplicable
+				}
+				for i := 0; i < 2; i++ {
+					if err := v.Void(context.Background(), authorized.ID); err != nil {
+						return fmt.Errorf("Void(%q) attempt %d returned error: %w", authorized.ID, i+1, err)
+					}
+				}
+				if _, err := a.Capture(context.Background(), authorized.ID); !errors.Is(err, payment.ErrPaymentVoided) {
+					return fmt.Errorf("Capture(%q) after void error = %v, want %v", authorized.ID, err, payment.ErrPaymentVoided)
+				}
+				if r, err := refunder(p); err == nil {
+					if err := r.Refund(context.Background(), authorized.ID, 100); !errors.Is(err, payment.ErrPaymentVoided) {
+						return fmt.Errorf("Refund(%q) after void error = %v, want %v", authorized.ID, err, payment.ErrPaymentVoided)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "VoidPendingPayment",
+			Rule: "voiding a pending payment succeeds and stops it from being captured or refunded",
+			Run: func(newProcessor Factory) error {
+				p := newProcessor()
+				v, err := voider(p)
+				if err != nil {
+					return err
+				}
+				result, err := pay(p, 100, "")
+				if err != nil {
+					return fmt.Errorf("ProcessPayment(100) returned error: %w", err)
+				}
+				if result.Status != payment.StatusPending {
+					return ErrNotApplicable
+				}
+				if err := v.Void(context.Background(), result.ID); err != nil {
+					return fmt.Errorf("Void(%q) of a pending payment returned error: %w", result.ID, err)
+				}
+				if s, ok := p.(payment.StatusChecker); ok {
+					status, err := s.Status(context.Background(), result.ID)
+					if err != nil {
+						return fmt.Errorf("Status(%q) after void returned error: %w", result.ID, err)
+					}
+					if status != payment.StatusVoided {
+						return fmt.Errorf("Status(%q) after void = %q, want %q", result.ID, status, payment.StatusVoided)
+					}
+				}
+				if c, err := capturer(p); err == nil {
+					if _, err := c.Capture(context.Background(), result.ID); !errors.Is(err, payment.ErrPaymentVoided) {
+						return fmt.Errorf("Capture(%q) after void error = %v, want %v", result.ID, err, payment.ErrPaymentVoided)
+					}
+				}
+				if r, err := refunder(p); err == nil {
+					if err := r.Refund(context.Background(), result.ID, 100); !errors.Is(err, payment.ErrPaymentVoided) {
+						return fmt.Errorf("Refund(%q) after void error = %v, want %v", result.ID, err, payment.ErrPaymentVoided)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "VoidUnknownPayment",
+			Rule: "voiding a payment the processor never made fails with ErrPaymentNotFound",
+			Run: func(newProcessor Factory) error {
+				v, err := voider(newProcessor())
+				if err != nil {
+					return err
+				}
+				if err := v.Void(context.Background(), "unknown"); !errors.Is(err, payment.ErrPaymentNotFound) {
+					return fmt.Errorf("Void(unknown) error = %v, want %v", err, payment.ErrPaymentNotFound)
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,93 @@
+// Package receipt renders receipts for captured payments. Receipts are
+// built from PaymentResult alone, so every processor gets the same receipt
+// without knowing how to print one.
+package receipt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// DATE_FORMAT is how text receipts print the payment time
+const DATE_FORMAT = "2006-01-02 15:04:05"
+
+// Receipt is what the customer is shown for one payment
+type Receipt struct {
+	PaymentID string           `json:"payment_id"`
+	Method    string           `json:"method"`
+	Amount    float64          `json:"amount"`
+	Fee       float64          `json:"fee"`
+	Net       float64          `json:"net"`
+	Currency  payment.Currency `json:"currency"`
+	Status    payment.Status   `json:"status"`
+	PaidAt    time.Time        `json:"paid_at"`
+}
+
+// New builds the receipt of a captured or refunded payment. Payments whose
+// funds were never taken have no receipt and fail with ErrNotCaptured.
+func New(result payment.PaymentResult) (Receipt, error) {
+	if result.Status != payment.StatusCaptured && result.Status != payment.StatusRefunded {
+		return Receipt{}, fmt.Errorf("receipt for %s payment %s: %w", result.Status, result.ID, payment.ErrNotCaptured)
+	}
+	return Receipt{
+		PaymentID: result.ID,
+		Method:    result.Method,
+		Amount:    result.Amount,
+		Fee:       result.Fee,
+		Net:       result.Net,
+		Currency:  result.Currency,
+		Status:    result.Status,
+		PaidAt:    result.Timestamp,
+	}, nil
+}
+
+// Renderer writes receipts in one format. A payment trail is several
+// receipts rendered in order.
+type Renderer interface {
+	Render(w io.Writer, receipts ...Receipt) error
+}
+
+// TextRenderer renders receipts for people
+type TextRenderer struct{}
+
+func (TextRenderer) Render(w io.Writer, receipts ...Receipt) error {
+	for _, r := range receipts {
+		_, err := fmt.Fprintf(w, "Receipt %s\n  Method: %s\n  Amount: %.2f %s\n  Fee:    %.2f %s\n  Net:    %.2f %s\n  Status: %s\n  Paid:   %s\n",
+			r.PaymentID, r.Method, r.Amount, r.Currency, r.Fee, r.Currency, r.Net, r.Currency, r.Status, r.PaidAt.Format(DATE_FORMAT))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSONRenderer renders receipts as a JSON array
+type JSONRenderer struct {
+	// Indent pretty-prints the output when set
+	Indent string
+}
+
+func (j JSONRenderer) Render(w io.Writer, receipts ...Receipt) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", j.Indent)
+	if receipts == nil {
+		receipts = []Receipt{}
+	}
+	return enc.Encode(receipts)
+}
+
+// Trail builds the receipts of every captured payment in results and skips
+// the rest, so a paid invoice can list what settled it
+func Trail(results ...payment.PaymentResult) []Receipt {
+	var receipts []Receipt
+	for _, result := range results {
+		if r, err := New(result); err == nil {
+			receipts = append(receipts, r)
+		}
+	}
+	return receipts
+}
@@ -0,0 +1,178 @@
+// Package settlement batches the captured payments of each processor by day.
+// It only relies on the PaymentProcessor abstraction, so any processor can
+// be settled without knowing what kind it is.
+package settlement
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+)
+
+// DAY_FORMAT is how batches name their day
+const DAY_FORMAT = "2006-01-02"
+
+// Batch is the captured payments of one processor on one day
+type Batch struct {
+	Processor string
+	Day       string
+	Payments  []payment.PaymentResult
+}
+
+// Totals sums the batch per currency
+func (b Batch) Totals() map[payment.Currency]float64 {
+	totals := make(map[payment.Currency]float64)
+	for _, p := range b.Payments {
+		totals[p.Currency] += p.Amount
+	}
+	return totals
+}
+
+// Settler turns a batch into whatever the acquirer or the books need
+type Settler interface {
+	Settle(ctx context.Context, batch Batch) error
+}
+
+// Collector records captured payments of the processors it wraps. The zero
+// value is ready to use.
+type Collector struct {
+	mu       sync.Mutex
+	payments map[string][]payment.PaymentResult
+}
+
+// Wrap returns p recording its captured payments under name. The result
+// behaves exactly like p otherwise.
+func (c *Collector) Wrap(name string, p payment.PaymentProcessor) payment.PaymentProcessor {
+	return recorder{PaymentProcessor: p, name: name, collector: c}
+}
+
+// Batches returns one batch per processor with payments on day, ordered by processor name
+func (c *Collector) Batches(day time.Time) []Batch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := day.Format(DAY_FORMAT)
+	var batches []Batch
+	for name, payments := range c.payments {
+		batch := Batch{Processor: name, Day: key}
+		for _, p := range payments {
+			if p.Timestamp.Format(DAY_FORMAT) == key {
+				batch.Payments = append(batch.Payments, p)
+			}
+		}
+		if len(batch.Payments) > 0 {
+			batches = append(batches, batch)
+		}
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].Processor < batches[j].Processor })
+	return batches
+}
+
+// SettleDay hands every batch of day to settler, stopping at the first error
+func (c *Collector) SettleDay(ctx context.Context, day time.Time, settler Settler) error {
+	for _, batch := range c.Batches(day) {
+		if err := settler.Settle(ctx, batch); err != nil {
+			return fmt.Errorf("settle %s %s: %w", batch.Processor, batch.Day, err)
+		}
+	}
+	return nil
+}
+
+func (c *Collector) add(name string, result payment.PaymentResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.payments == nil {
+		c.payments = make(map[string][]payment.PaymentResult)
+	}
+	for _, p := range c.payments[name] {
+		if p.ID == result.ID {
+			return // idempotent resubmission of a recorded payment
+		}
+	}
+	c.payments[name] = append(c.payments[name], result)
+}
+
+// recorder is the decorator returned by Collector.Wrap
+type recorder struct {
+	payment.PaymentProcessor
+	name      string
+	collector *Collector
+}
+
+func (r recorder) ProcessPayment(ctx context.Context, amount float64, currency payment.Currency, idempotencyKey string) (payment.PaymentResult, error) {
+	result, err := r.PaymentProcessor.ProcessPayment(ctx, amount, currency, idempotencyKey)
+	if err == nil && result.Status == payment.StatusCaptured {
+		r.collector.add(r.name, result)
+	}
+	return result, err
+}
+
+func (r recorder) Refund(ctx context.Context, paymentID string, amount float64) error {
+	return payment.Refund(ctx, r.PaymentProcessor, paymentID, amount)
+}
+
+func (r recorder) Unwrap() payment.PaymentProcessor {
+	return r.PaymentProcessor
+}
+
+// CSVSettler writes each batch to <Dir>/<processor>-<day>.csv
+type CSVSettler struct {
+	Dir string
+}
+
+func (s CSVSettler) Settle(ctx context.Context, batch Batch) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(s.Dir, fmt.Sprintf("%s-%s.csv", batch.Processor, batch.Day)))
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"id", "method", "amount", "currency", "timestamp"})
+	for _, p := range batch.Payments {
+		w.Write([]string{p.ID, p.Method, strconv.FormatFloat(p.Amount, 'f', -1, 64), string(p.Currency), p.Timestamp.Format(time.RFC3339)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SummarySettler writes a one line summary per batch and currency
+type SummarySettler struct {
+	W io.Writer
+}
+
+func (s SummarySettler) Settle(ctx context.Context, batch Batch) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	totals := batch.Totals()
+	counts := make(map[payment.Currency]int)
+	currencies := make([]string, 0, len(totals))
+	for _, p := range batch.Payments {
+		if counts[p.Currency]++; counts[p.Currency] == 1 {
+			currencies = append(currencies, string(p.Currency))
+		}
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		_, err := fmt.Fprintf(s.W, "%s %s: %d payments, %f %s\n",
+			batch.Day, batch.Processor, counts[payment.Currency(currency)], totals[payment.Currency(currency)], currency)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Command shapes shows the classic Rectangle/Square LSP pitfall and the
+// immutable design that avoids it.
+//
+// Run it with: go run ./3-LSP/shapes
+package main
+
+import "fmt"
+
+// Resizable is what callers of the mutable Rectangle rely on
+type Resizable interface {
+	SetWidth(width float64)
+	SetHeight(height float64)
+	Area() float64
+}
+
+// Rectangle is the mutable base type
+type Rectangle struct {
+	width, height float64
+}
+
+func (r *Rectangle) SetWidth(width float64)   { r.width = width }
+func (r *Rectangle) SetHeight(height float64) { r.height = height }
+func (r *Rectangle) Area() float64            { return r.width * r.height }
+
+// Square "is a" rectangle, so it reuses Rectangle and keeps its sides equal.
+// That silently changes what SetWidth and SetHeight promise.
+type Square struct {
+	Rectangle
+}
+
+func (s *Square) SetWidth(width float64) {
+	s.width, s.height = width, width
+}
+
+func (s *Square) SetHeight(height float64) {
+	s.width, s.height = height, height
+}
+
+// resizeProperty is the contract callers of Resizable assume: setting one
+// side leaves the other alone, so the area is their product
+func resizeProperty(r Resizable) error {
+	r.SetWidth(5)
+	r.SetHeight(4)
+	if area := r.Area(); area != 20 {
+		return fmt.Errorf("after SetWidth(5) and SetHeight(4) area = %v, want 20", area)
+	}
+	return nil
+}
+
+// Shape is the corrected abstraction: it only promises what every shape can keep
+type Shape interface {
+	Area() float64
+}
+
+// Rect is immutable; resizing returns a new value instead of changing this one
+type Rect struct {
+	Width, Height float64
+}
+
+func (r Rect) Area() float64                  { return r.Width * r.Height }
+func (r Rect) WithWidth(width float64) Rect   { return Rect{Width: width, Height: r.Height} }
+func (r Rect) WithHeight(height float64) Rect { return Rect{Width: r.Width, Height: height} }
+
+// Sq is its own shape rather than a Rect with extra rules
+type Sq struct {
+	Side float64
+}
+
+func (s Sq) Area() float64            { return s.Side * s.Side }
+func (s Sq) WithSide(side float64) Sq { return Sq{Side: side} }
+func (s Sq) AsRect() Rect             { return Rect{Width: s.Side, Height: s.Side} }
+
+// areaProperty holds for every Shape: the area is never negative and asking
+// twice gives the same answer
+func areaProperty(s Shape) error {
+	first := s.Area()
+	if first < 0 {
+		return fmt.Errorf("area = %v, want a non-negative value", first)
+	}
+	if again := s.Area(); again != first {
+		return fmt.Errorf("area changed from %v to %v", first, again)
+	}
+	return nil
+}
+
+func main() {
+	fmt.Println("Mutable design")
+	report("Rectangle", resizeProperty(&Rectangle{}))
+	report("Square", resizeProperty(&Square{}))
+
+	fmt.Println("Immutable design")
+	rect := Rect{Width: 5, Height: 3}.WithHeight(4)
+	square := Sq{Side: 3}.WithSide(4)
+	report(fmt.Sprintf("Rect area %v", rect.Area()), areaProperty(rect))
+	report(fmt.Sprintf("Sq area %v", square.Area()), areaProperty(square))
+
+	// Stretching a square is explicit: it becomes a Rect instead of breaking Sq
+	stretched := square.AsRect().WithWidth(5)
+	report(fmt.Sprintf("Sq stretched to Rect area %v", stretched.Area()), areaProperty(stretched))
+}
+
+func report(name string, err error) {
+	if err != nil {
+		fmt.Printf("  FAIL %s: %v\n", name, err)
+		return
+	}
+	fmt.Printf("  ok   %s\n", name)
+}
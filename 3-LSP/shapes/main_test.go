@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+// TestResizeProperty is the property test that exposes the pitfall: it
+// holds for Rectangle and fails for Square, its supposed subtype
+func TestResizeProperty(t *testing.T) {
+	if err := resizeProperty(&Rectangle{}); err != nil {
+		t.Errorf("Rectangle: %v", err)
+	}
+	err := resizeProperty(&Square{})
+	if err == nil {
+		t.Fatal("Square kept the resize property, want it to break it")
+	}
+	t.Logf("Square: %v", err)
+}
+
+// TestAreaProperty holds for every shape of the immutable design, resized
+// or not
+func TestAreaProperty(t *testing.T) {
+	shapes := map[string]Shape{
+		"Rect":              Rect{Width: 5, Height: 3}.WithHeight(4),
+		"Sq":                Sq{Side: 3}.WithSide(4),
+		"Sq stretched":      Sq{Side: 4}.AsRect().WithWidth(5),
+		"Rect with no area": Rect{},
+	}
+	for name, shape := range shapes {
+		if err := areaProperty(shape); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+// TestResizingReturnsNewValues shows the immutable design keeping the
+// promise Square broke: changing one side leaves the other alone
+func TestResizingReturnsNewValues(t *testing.T) {
+	rect := Rect{Width: 5, Height: 3}
+	if resized := rect.WithWidth(5).WithHeight(4); resized.Area() != 20 {
+		t.Errorf("Rect resized to 5x4 area = %v, want 20", resized.Area())
+	}
+	if rect.Area() != 15 {
+		t.Errorf("resizing changed the original Rect, area = %v, want 15", rect.Area())
+	}
+	if stretched := (Sq{Side: 4}).AsRect().WithWidth(5); stretched.Area() != 20 {
+		t.Errorf("Sq stretched to 5x4 area = %v, want 20", stretched.Area())
+	}
+}
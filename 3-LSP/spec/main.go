@@ -0,0 +1,58 @@
+// Command spec writes a behavioral specification for every registered
+// processor by running the conformance suite against it, so the documented
+// contract is whatever the code actually does.
+//
+// Run it with: go run ./3-LSP/spec -format html
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+)
+
+func main() {
+	dir := flag.String("dir", "docs/spec", "directory to write the specifications to")
+	format := flag.String("format", "markdown", "markdown or html")
+	flag.Parse()
+
+	ext := map[string]string{"markdown": ".md", "html": ".html"}[*format]
+	if ext == "" {
+		log.Fatalf("unknown format %q", *format)
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		log.Fatal(err)
+	}
+	for _, name := range payment.Names() {
+		spec := paymenttest.NewSpec(name, paymenttest.Verify(func() payment.PaymentProcessor {
+			p, _ := payment.New(name)
+			return p
+		}))
+		path := filepath.Join(*dir, name+ext)
+		if err := write(path, spec, *format); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("wrote", path)
+	}
+}
+
+func write(path string, spec paymenttest.Spec, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if format == "html" {
+		err = spec.HTML(f)
+	} else {
+		err = spec.Markdown(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+// Command streams shows LSP in the standard library: every io.Reader and
+// io.Writer promises the same rules, so io.Copy, bufio and json work with
+// any of them. The stdlib types hold the contract, a few of them in ways
+// that surprise; the custom types at the end break it.
+//
+// Run it with: go run ./3-LSP/streams
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing/iotest"
+
+	"github.com/imrancluster/go-solid/3-LSP/iocontract"
+)
+
+// endlessReader hands out its data but then returns 0, nil forever instead
+// of io.EOF, so io.ReadAll never returns
+type endlessReader struct {
+	data []byte
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// shortWriter keeps half of every write but reports no error, so callers
+// believe the rest arrived
+type shortWriter struct {
+	bytes.Buffer
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	return w.Buffer.Write(p[:(len(p)+1)/2])
+}
+
+// shoutingWriter upper-cases the caller's buffer in place before writing it
+type shoutingWriter struct {
+	bytes.Buffer
+}
+
+func (w *shoutingWriter) Write(p []byte) (int, error) {
+	copy(p, bytes.ToUpper(p))
+	return w.Buffer.Write(p)
+}
+
+func main() {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+
+	fmt.Println("Readers")
+	report("strings.Reader", iocontract.CheckReader(func(d []byte) io.Reader { return strings.NewReader(string(d)) }, data))
+	report("bytes.Reader", iocontract.CheckReader(func(d []byte) io.Reader { return bytes.NewReader(d) }, data))
+	report("bufio.Reader", iocontract.CheckReader(func(d []byte) io.Reader { return bufio.NewReader(bytes.NewReader(d)) }, data))
+	report("io.MultiReader", iocontract.CheckReader(func(d []byte) io.Reader {
+		return io.MultiReader(bytes.NewReader(d[:10]), bytes.NewReader(d[10:]))
+	}, data))
+	// Reads fewer bytes than asked for: allowed, callers must loop
+	report("iotest.HalfReader", iocontract.CheckReader(func(d []byte) io.Reader { return iotest.HalfReader(bytes.NewReader(d)) }, data))
+	// Returns the last bytes together with io.EOF: allowed, callers must
+	// use n before looking at err. It also never returns from a Read into
+	// an empty buffer, which io.Reader does not rule out, so CheckReader
+	// never tries one.
+	report("iotest.DataErrReader", iocontract.CheckReader(func(d []byte) io.Reader { return iotest.DataErrReader(bytes.NewReader(d)) }, data))
+	report("endlessReader", iocontract.CheckReader(func(d []byte) io.Reader { return &endlessReader{d} }, data))
+
+	fmt.Println("Writers")
+	var buf bytes.Buffer
+	report("bytes.Buffer", iocontract.CheckWriter(&buf, buf.Bytes, data))
+	// Holds data back until Flush: nothing is lost, but it has not arrived
+	// when Write returns
+	var dst bytes.Buffer
+	bw := bufio.NewWriter(&dst)
+	report("bufio.Writer", iocontract.CheckWriter(bw, func() []byte { bw.Flush(); return dst.Bytes() }, data))
+	report("io.Discard", iocontract.CheckWriter(io.Discard, nil, data))
+	short := &shortWriter{}
+	report("shortWriter", iocontract.CheckWriter(short, short.Bytes, data))
+	shouting := &shoutingWriter{}
+	report("shoutingWriter", iocontract.CheckWriter(shouting, shouting.Bytes, bytes.Clone(data)))
+}
+
+func report(name string, err error) {
+	if err != nil {
+		fmt.Printf("  FAIL %s: %v\n", name, err)
+		return
+	}
+	fmt.Printf("  ok   %s\n", name)
+}
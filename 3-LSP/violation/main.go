@@ -0,0 +1,86 @@
+// Command violation shows processors that look like valid substitutes but
+// break the PaymentProcessor contract, and which contract checks catch them.
+//
+//	MinimumAmountPayment  fails AcceptsPositiveAmounts (0.01 and 99 are rejected),
+//	                      the installment checks (installments of a plan fall below 100)
+//	                      and the error checks (its error matches no shared sentinel)
+//	PanickingPayment      fails AcceptsPositiveAmounts (1e9), RejectsNonPositiveAmounts
+//	                      (+Inf panics instead of failing), DoesNotPanic and
+//	                      ErrorsAreClassified
+//	FinalRefundPayment    fails the refund checks: it claims to be a Refunder but
+//	                      rejects every refund with an error of its own
+//
+// CryptoPayment cannot refund either, but says so by not being a Refunder,
+// so the refund checks do not apply to it and it passes the contract.
+//
+// Run it with: go run ./3-LSP/violation
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+)
+
+const MINIMUM_AMOUNT = 100
+
+// MinimumAmountPayment strengthens the precondition: callers that were fine
+// with any positive amount now get errors for small payments.
+type MinimumAmountPayment struct {
+	payment.CardPayment
+}
+
+func (m *MinimumAmountPayment) ProcessPayment(ctx context.Context, amount float64, currency payment.Currency, idempotencyKey string) (payment.PaymentResult, error) {
+	if amount > 0 && amount < MINIMUM_AMOUNT {
+		return payment.PaymentResult{}, errors.New("amount is below the minimum of 100")
+	}
+	return m.CardPayment.ProcessPayment(ctx, amount, currency, idempotencyKey)
+}
+
+// PanickingPayment panics where the contract expects a result or an error
+type PanickingPayment struct {
+	payment.CashPayment
+}
+
+func (p *PanickingPayment) ProcessPayment(ctx context.Context, amount float64, currency payment.Currency, idempotencyKey string) (payment.PaymentResult, error) {
+	if amount > 1e6 {
+		panic("large payments are not implemented")
+	}
+	return p.CashPayment.ProcessPayment(ctx, amount, currency, idempotencyKey)
+}
+
+// FinalRefundPayment adds Refund to a processor whose payments are final.
+// Callers that trust the Refunder capability find out only when it fails.
+type FinalRefundPayment struct {
+	payment.CryptoPayment
+}
+
+func (f *FinalRefundPayment) Refund(ctx context.Context, paymentID string, amount float64) error {
+	return errors.New("crypto payments cannot be refunded")
+}
+
+func main() {
+	report("CardPayment", func() payment.PaymentProcessor { return &payment.CardPayment{} })
+	report("MinimumAmountPayment", func() payment.PaymentProcessor { return &MinimumAmountPayment{} })
+	report("PanickingPayment", func() payment.PaymentProcessor { return &PanickingPayment{} })
+	report("CryptoPayment", func() payment.PaymentProcessor { return &payment.CryptoPayment{} })
+	report("FinalRefundPayment", func() payment.PaymentProcessor { return &FinalRefundPayment{} })
+}
+
+// report prints every contract check and whether the processor honors it
+func report(name string, newProcessor func() payment.PaymentProcessor) {
+	fmt.Println(name)
+	for _, result := range paymenttest.Verify(newProcessor) {
+		if errors.Is(result.Err, paymenttest.ErrNotApplicable) {
+			continue
+		}
+		if result.Err != nil {
+			fmt.Printf("  FAIL %s: %v\n", result.Check.Name, result.Err)
+			continue
+		}
+		fmt.Printf("  ok   %s\n", result.Check.Name)
+	}
+}
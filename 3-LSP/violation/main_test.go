@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/paymenttest"
+)
+
+func TestConformingProcessorsPass(t *testing.T) {
+	t.Run("CardPayment", func(t *testing.T) {
+		paymenttest.RunProcessorSuite(t, func() payment.PaymentProcessor { return &payment.CardPayment{} })
+	})
+	// CryptoPayment cannot refund but is no Refunder, so it is not a violation
+	t.Run("CryptoPayment", func(t *testing.T) {
+		paymenttest.RunProcessorSuite(t, func() payment.PaymentProcessor { return &payment.CryptoPayment{} })
+	})
+}
+
+func TestViolationsAreCaught(t *testing.T) {
+	tests := []struct {
+		name         string
+		newProcessor func() payment.PaymentProcessor
+		// failing are checks that must catch the violation
+		failing []string
+	}{
+		{
+			name:         "MinimumAmountPayment",
+			newProcessor: func() payment.PaymentProcessor { return &MinimumAmountPayment{} },
+			failing:      []string{"AcceptsPositiveAmounts", "InstallmentsCompletePlan", "ErrorsAreClassified"},
+		},
+		{
+			name:         "PanickingPayment",
+			newProcessor: func() payment.PaymentProcessor { return &PanickingPayment{} },
+			failing:      []string{"AcceptsPositiveAmounts", "RejectsNonPositiveAmounts", "DoesNotPanic", "ErrorsAreClassified"},
+		},
+		{
+			name:         "FinalRefundPayment",
+			newProcessor: func() payment.PaymentProcessor { return &FinalRefundPayment{} },
+			failing:      []string{"RefundFullAmount", "RefundMoreThanCaptured", "RefundIsIdempotent"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := make(map[string]error)
+			for _, result := range paymenttest.Verify(tt.newProcessor) {
+				errs[result.Check.Name] = result.Err
+			}
+			for _, name := range tt.failing {
+				err, ok := errs[name]
+				switch {
+				case !ok:
+					t.Errorf("%s is not a check of the contract", name)
+				case err == nil:
+					t.Errorf("%s passed, want it to catch the violation", name)
+				case errors.Is(err, paymenttest.ErrNotApplicable):
+					t.Errorf("%s did not apply, want it to catch the violation", name)
+				}
+			}
+		})
+	}
+}
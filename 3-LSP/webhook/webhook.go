@@ -0,0 +1,163 @@
+// Package webhook delivers asynchronous payment confirmations over HTTP.
+// A Simulator plays the processor side and posts an Event once a payment
+// settles; a Receiver is the merchant side endpoint that records it, so the
+// merchant's view of a payment becomes consistent eventually.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/clock"
+)
+
+const (
+	SIGNATURE_HEADER       = "X-Webhook-Signature"
+	DEFAULT_WATCH_INTERVAL = 10 * time.Millisecond
+)
+
+// ErrBadSignature is returned when an event was not signed with the shared secret
+var ErrBadSignature = errors.New("webhook: bad signature")
+
+// Event tells the merchant that a payment reached a new status
+type Event struct {
+	PaymentID string         `json:"payment_id"`
+	Status    payment.Status `json:"status"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// Sign returns the hex HMAC-SHA256 of body with secret
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Receiver is an http.Handler accepting signed events
+type Receiver struct {
+	Secret []byte
+	// OnEvent is called for every accepted event, it may be nil
+	OnEvent func(Event)
+
+	mu       sync.Mutex
+	statuses map[string]payment.Status
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, 1<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !hmac.Equal([]byte(req.Header.Get(SIGNATURE_HEADER)), []byte(Sign(r.Secret, body))) {
+		http.Error(w, ErrBadSignature.Error(), http.StatusUnauthorized)
+		return
+	}
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil || event.PaymentID == "" {
+		http.Error(w, "malformed event", http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	if r.statuses == nil {
+		r.statuses = make(map[string]payment.Status)
+	}
+	r.statuses[event.PaymentID] = event.Status
+	r.mu.Unlock()
+
+	if r.OnEvent != nil {
+		r.OnEvent(event)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Status is the last status the receiver heard for paymentID
+func (r *Receiver) Status(paymentID string) (payment.Status, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status, ok := r.statuses[paymentID]
+	return status, ok
+}
+
+// Simulator watches payments of an async processor and posts an Event to
+// URL when they complete, the way a real processor calls back a merchant
+type Simulator struct {
+	Processor payment.StatusChecker
+	URL       string
+	Secret    []byte
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+	// Interval defaults to DEFAULT_WATCH_INTERVAL
+	Interval time.Duration
+	// Clock stamps the events, it defaults to the real clock
+	Clock clock.Clock
+}
+
+// Watch polls paymentID until it is no longer pending and delivers the
+// event. It blocks, so callers usually run it in a goroutine.
+func (s Simulator) Watch(ctx context.Context, paymentID string) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DEFAULT_WATCH_INTERVAL
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status, err := s.Processor.Status(ctx, paymentID)
+		if err != nil {
+			return err
+		}
+		if status != payment.StatusPending {
+			return s.Deliver(ctx, Event{PaymentID: paymentID, Status: status, Timestamp: clock.Or(s.Clock).Now()})
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deliver posts a signed event to URL
+func (s Simulator) Deliver(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SIGNATURE_HEADER, Sign(s.Secret, body))
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook: %s answered %s", s.URL, resp.Status)
+	}
+	return nil
+}
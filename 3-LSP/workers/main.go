@@ -0,0 +1,130 @@
+// Command workers shows a subtype that cannot honor a method at all: a
+// Volunteer works like an Employee, but there is no salary to pay. The fix
+// is to pay only the workers that are payable.
+//
+// Run it with: go run ./3-LSP/workers
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Employee is the broken abstraction: it assumes everyone who works is paid
+type Employee interface {
+	Name() string
+	Work(hours float64)
+	Salary() (float64, error)
+}
+
+// Staff honors Employee
+type Staff struct {
+	name   string
+	rate   float64
+	worked float64
+}
+
+func (s *Staff) Name() string       { return s.name }
+func (s *Staff) Work(hours float64) { s.worked += hours }
+
+func (s *Staff) Salary() (float64, error) {
+	return s.rate * s.worked, nil
+}
+
+// Volunteer implements Employee so it fits the same rosters, and can only
+// refuse when asked for a salary
+type Volunteer struct {
+	name   string
+	worked float64
+}
+
+func (v *Volunteer) Name() string       { return v.name }
+func (v *Volunteer) Work(hours float64) { v.worked += hours }
+
+func (v *Volunteer) Salary() (float64, error) {
+	return 0, errors.New("volunteers are not paid")
+}
+
+// payrollProperty is the contract the payroll assumes: an employee who
+// worked is owed a positive salary
+func payrollProperty(e Employee) error {
+	e.Work(8)
+	salary, err := e.Salary()
+	if err != nil {
+		return fmt.Errorf("%s: Salary after 8 hours returned error: %w", e.Name(), err)
+	}
+	if salary <= 0 {
+		return fmt.Errorf("%s: Salary after 8 hours = %v, want a positive salary", e.Name(), salary)
+	}
+	return nil
+}
+
+// Worker is the corrected abstraction: everyone on the roster works
+type Worker interface {
+	Name() string
+	Work(hours float64)
+	Hours() float64
+}
+
+// Payable is a capability of its own, for the workers that are paid
+type Payable interface {
+	Salary() float64
+}
+
+// Contractor works and is paid by the hour
+type Contractor struct {
+	name   string
+	rate   float64
+	worked float64
+}
+
+func (c *Contractor) Name() string       { return c.name }
+func (c *Contractor) Work(hours float64) { c.worked += hours }
+func (c *Contractor) Hours() float64     { return c.worked }
+func (c *Contractor) Salary() float64    { return c.rate * c.worked }
+
+// Helper works for free and makes no promise about pay
+type Helper struct {
+	name   string
+	worked float64
+}
+
+func (h *Helper) Name() string       { return h.name }
+func (h *Helper) Work(hours float64) { h.worked += hours }
+func (h *Helper) Hours() float64     { return h.worked }
+
+// workProperty holds for every Worker: hours add up
+func workProperty(w Worker) error {
+	before := w.Hours()
+	w.Work(8)
+	if after := w.Hours(); after != before+8 {
+		return fmt.Errorf("%s: hours after Work(8) = %v, want %v", w.Name(), after, before+8)
+	}
+	return nil
+}
+
+func main() {
+	fmt.Println("Everyone is an employee")
+	report("Staff", payrollProperty(&Staff{name: "Ayesha", rate: 20}))
+	report("Volunteer", payrollProperty(&Volunteer{name: "Rahim"}))
+
+	fmt.Println("Pay is a capability")
+	roster := []Worker{&Contractor{name: "Karim", rate: 25}, &Helper{name: "Nadia"}}
+	var payroll float64
+	for _, worker := range roster {
+		report(worker.Name(), workProperty(worker))
+		// The payroll only pays the workers that can be paid
+		if payable, ok := worker.(Payable); ok {
+			payroll += payable.Salary()
+		}
+	}
+	fmt.Printf("  payroll for %d workers: %.2f\n", len(roster), payroll)
+}
+
+func report(name string, err error) {
+	if err != nil {
+		fmt.Printf("  FAIL %s: %v\n", name, err)
+		return
+	}
+	fmt.Printf("  ok   %s\n", name)
+}
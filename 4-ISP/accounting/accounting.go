@@ -0,0 +1,176 @@
+// Package accounting charges printed pages to the users who printed them
+// and stops users who reached their quota. Printing and accounting only
+// meet in Meter, so neither printers nor the ledger know about the other.
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+// ErrQuotaExceeded is returned for jobs that would take a user over quota
+var ErrQuotaExceeded = errors.New("accounting: quota exceeded")
+
+// Accounting decides whether a user may print and records what they did
+type Accounting interface {
+	// Authorize fails with ErrQuotaExceeded when pages more would take
+	// user over quota. It charges nothing.
+	Authorize(user string, pages int) error
+	// Charge records pages printed by user
+	Charge(user string, pages int) error
+}
+
+// UsageReporter is implemented by accounting that can report what every
+// user printed
+type UsageReporter interface {
+	Usage() ([]Usage, error)
+}
+
+// Usage is what one user printed
+type Usage struct {
+	User  string
+	Pages int
+	Jobs  int
+	// Rejected counts the jobs refused for quota
+	Rejected int
+	// Quota is zero for users without one
+	Quota int
+}
+
+// Remaining is the number of pages the user may still print, or -1 when
+// they have no quota
+func (u Usage) Remaining() int {
+	if u.Quota <= 0 {
+		return -1
+	}
+	return max(0, u.Quota-u.Pages)
+}
+
+// Ledger keeps page counts in memory. Users print without limit unless
+// they have a quota of their own or DefaultQuota is set. The zero value
+// is ready to use.
+type Ledger struct {
+	// DefaultQuota applies to users without a quota of their own
+	DefaultQuota int
+
+	mu     sync.Mutex
+	quotas map[string]int
+	usage  map[string]*Usage
+}
+
+// SetQuota limits user to pages, or lifts their limit when pages is zero
+func (l *Ledger) SetQuota(user string, pages int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.quotas == nil {
+		l.quotas = make(map[string]int)
+	}
+	l.quotas[user] = pages
+}
+
+func (l *Ledger) Authorize(user string, pages int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	u := l.user(user)
+	if u.Quota > 0 && u.Pages+pages > u.Quota {
+		u.Rejected++
+		return fmt.Errorf("%w: %s has %d of %d pages left, the job needs %d", ErrQuotaExceeded, user, u.Remaining(), u.Quota, pages)
+	}
+	return nil
+}
+
+func (l *Ledger) Charge(user string, pages int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	u := l.user(user)
+	u.Pages += pages
+	u.Jobs++
+	return nil
+}
+
+// Usage lists every user that printed or tried to, by name
+func (l *Ledger) Usage() ([]Usage, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	usage := make([]Usage, 0, len(l.usage))
+	for name := range l.usage {
+		usage = append(usage, *l.user(name))
+	}
+	slices.SortFunc(usage, func(a, b Usage) int { return strings.Compare(a.User, b.User) })
+	return usage, nil
+}
+
+// user returns the usage of name with its current quota
+func (l *Ledger) user(name string) *Usage {
+	if l.usage == nil {
+		l.usage = make(map[string]*Usage)
+	}
+	u, ok := l.usage[name]
+	if !ok {
+		u = &Usage{User: name}
+		l.usage[name] = u
+	}
+	u.Quota = l.DefaultQuota
+	if quota, ok := l.quotas[name]; ok {
+		u.Quota = quota
+	}
+	return u
+}
+
+// Meter prints on Printer and charges the pages to Accounting. A job is
+// authorized before it prints and charged only once it printed.
+type Meter struct {
+	Printer    device.Printer
+	Accounting Accounting
+}
+
+// PrintAs prints doc for user
+func (m Meter) PrintAs(user string, doc device.Document) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	pages := doc.Pages()
+	if err := m.Accounting.Authorize(user, pages); err != nil {
+		return err
+	}
+	if err := m.Printer.Print(doc); err != nil {
+		return err
+	}
+	return m.Accounting.Charge(user, pages)
+}
+
+// As returns a Printer that prints for user, for code that only knows
+// about printers
+func (m Meter) As(user string) device.Printer {
+	return userPrinter{m, user}
+}
+
+type userPrinter struct {
+	meter Meter
+	user  string
+}
+
+func (u userPrinter) Print(doc device.Document) error {
+	return u.meter.PrintAs(u.user, doc)
+}
+
+// Report writes usage as a table with a row per user
+func Report(w io.Writer, usage []Usage) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "USER\tJOBS\tPAGES\tQUOTA\tREMAINING\tREJECTED")
+	for _, u := range usage {
+		quota, remaining := "-", "-"
+		if u.Quota > 0 {
+			quota, remaining = fmt.Sprint(u.Quota), fmt.Sprint(u.Remaining())
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%d\n", u.User, u.Jobs, u.Pages, quota, remaining, u.Rejected)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,122 @@
+// Command consumer shows interfaces owned by the code that uses them.
+// Package device exports role interfaces for convenience, but nothing here
+// needs them: each function below declares the one or two methods it
+// calls, and every device that has those methods fits without importing,
+// naming or embedding anything. Go checks the fit structurally, so the
+// producer never has to know about its consumers.
+//
+// The assertions in the var block are the proof. They compile against
+// interfaces declared only in this file, for devices written long before
+// it, fakes from devicetest included.
+//
+// Run it with: go run ./4-ISP/consumer
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/devicetest"
+)
+
+// printer is all that publish calls. It is unexported: it belongs to this
+// package and changes only when this package needs it to.
+type printer interface {
+	Print(doc device.Document) error
+}
+
+// glass is what archive needs from a scanner, which is exactly the shape
+// of device.Scanner without depending on it
+type glass interface {
+	Scan(dst io.Writer) error
+}
+
+// healthChecker is the one method uptime calls
+type healthChecker interface {
+	Status() (device.Health, error)
+}
+
+var (
+	_ printer       = device.SimplePrinter{}
+	_ printer       = device.LabelPrinter{}
+	_ printer       = (*device.PDFPrinter)(nil)
+	_ printer       = (*devicetest.FakePrinter)(nil)
+	_ glass         = device.MultifunctionPrinter{}
+	_ glass         = (*devicetest.FakeScanner)(nil)
+	_ healthChecker = device.OfficeMFP{}
+	_ healthChecker = (*device.NetworkPrinter)(nil)
+	// The interfaces of both packages are the same method sets, so values
+	// convert freely in either direction
+	_ device.Printer = printer(nil)
+	_ printer        = device.Printer(nil)
+)
+
+// publish prints a notice on every printer it is given
+func publish(notice string, printers ...printer) {
+	for _, p := range printers {
+		if err := p.Print(device.Text("notice.txt", notice)); err != nil {
+			fmt.Printf("%T: %v\n", p, err)
+		}
+	}
+}
+
+// archive keeps every scan it is given. newArchive returns the concrete
+// type: callers get all of it, and can narrow it to an interface of their
+// own if they ever want to.
+type archive struct {
+	scans []string
+}
+
+func newArchive() *archive {
+	return &archive{}
+}
+
+func (a *archive) add(g glass) error {
+	var b bytes.Buffer
+	if err := g.Scan(&b); err != nil {
+		return err
+	}
+	a.scans = append(a.scans, b.String())
+	return nil
+}
+
+// uptime reports how many of the checked devices are online
+func uptime(checkers ...healthChecker) (online, total int) {
+	for _, c := range checkers {
+		if health, err := c.Status(); err == nil && health.State == device.StateOnline {
+			online++
+		}
+	}
+	return online, len(checkers)
+}
+
+// stdoutPrinter is declared here and never mentions package device's
+// interfaces, yet publish takes it like any device
+type stdoutPrinter struct{}
+
+func (stdoutPrinter) Print(doc device.Document) error {
+	_, err := fmt.Fprintf(os.Stdout, "stdout: %s\n", strings.ToUpper(string(doc.Content)))
+	return err
+}
+
+func main() {
+	fake := &devicetest.FakePrinter{}
+	publish("Fire drill at noon", device.SimplePrinter{}, device.LabelPrinter{}, fake, stdoutPrinter{})
+	fmt.Println("fake printer got", len(fake.Printed()), "document")
+
+	contract := device.Text("contract.txt", "Signed by both parties")
+	a := newArchive()
+	for _, g := range []glass{device.MultifunctionPrinter{Original: contract}, &devicetest.FakeScanner{Pages: [][]byte{[]byte("page one")}}} {
+		if err := a.add(g); err != nil {
+			fmt.Printf("%T: %v\n", g, err)
+		}
+	}
+	fmt.Printf("archived %q\n", a.scans)
+
+	online, total := uptime(device.OfficeMFP{}, device.OfficeMFP{Offline: true})
+	fmt.Printf("%d of %d devices online\n", online, total)
+}
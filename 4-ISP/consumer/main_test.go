@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/devicetest"
+)
+
+// The fakes of devicetest satisfy the interfaces of this package without
+// knowing about them, so these tests need no fakes of their own
+
+func TestPublishPrintsOnEveryPrinter(t *testing.T) {
+	first, second := &devicetest.FakePrinter{}, &devicetest.FakePrinter{}
+	failing := &devicetest.FakePrinter{Err: errors.New("paper jam")}
+	publish("Fire drill at noon", first, failing, second)
+	for i, p := range []*devicetest.FakePrinter{first, second} {
+		printed := p.Printed()
+		if len(printed) != 1 || string(printed[0].Content) != "Fire drill at noon" {
+			t.Errorf("printer %d printed %v, want the notice once, past the failing printer", i+1, printed)
+		}
+	}
+}
+
+func TestArchiveKeepsScans(t *testing.T) {
+	a := newArchive()
+	scanner := &devicetest.FakeScanner{Pages: [][]byte{[]byte("page one"), []byte("page two")}}
+	for range 2 {
+		if err := a.add(scanner); err != nil {
+			t.Fatalf("add returned error: %v", err)
+		}
+	}
+	if err := a.add(scanner); !errors.Is(err, device.ErrNothingToScan) {
+		t.Errorf("add of an empty scanner error = %v, want %v", err, device.ErrNothingToScan)
+	}
+	if len(a.scans) != 2 || a.scans[0] != "page one" || a.scans[1] != "page two" {
+		t.Errorf("archived %q, want both pages in order", a.scans)
+	}
+}
+
+func TestUptime(t *testing.T) {
+	online, total := uptime(device.OfficeMFP{}, device.OfficeMFP{Offline: true}, device.OfficeMFP{})
+	if online != 2 || total != 3 {
+		t.Errorf("uptime = %d of %d, want 2 of 3", online, total)
+	}
+	if online, total := uptime(); online != 0 || total != 0 {
+		t.Errorf("uptime of nothing = %d of %d, want 0 of 0", online, total)
+	}
+}
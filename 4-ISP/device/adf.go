@@ -0,0 +1,97 @@
+package device
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// BatchScanner is a scanner with an automatic document feeder. It is a
+// role of its own because a flatbed Scanner scans one original at a time
+// and has no stack of pages to work through.
+type BatchScanner interface {
+	// ScanAll scans the pages in the feeder one after the other. Stopping
+	// the loop early leaves the pages not yet scanned in the feeder. An
+	// error ends the batch, an empty feeder yields ErrNothingToScan.
+	ScanAll(ctx context.Context) iter.Seq2[Document, error]
+}
+
+// DocumentFeeder is the stack of originals in a feeder. It is shared by
+// every copy of the device value, so pages scanned once are gone.
+type DocumentFeeder struct {
+	mu    sync.Mutex
+	pages []Document
+}
+
+// NewDocumentFeeder loads pages, the first one is scanned first
+func NewDocumentFeeder(pages ...Document) *DocumentFeeder {
+	return &DocumentFeeder{pages: slices.Clone(pages)}
+}
+
+// Load puts pages at the bottom of the stack
+func (f *DocumentFeeder) Load(pages ...Document) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pages = append(f.pages, pages...)
+}
+
+// Len is the number of pages waiting. A nil DocumentFeeder is empty.
+func (f *DocumentFeeder) Len() int {
+	if f == nil {
+		return 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.pages)
+}
+
+// next takes the top page off the stack
+func (f *DocumentFeeder) next() (Document, bool) {
+	if f == nil {
+		return Document{}, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.pages) == 0 {
+		return Document{}, false
+	}
+	page := f.pages[0]
+	f.pages = f.pages[1:]
+	return page, true
+}
+
+// ScanAll scans every page in the Feeder field. Each page is named after
+// its place in the batch.
+func (o OfficeMFP) ScanAll(ctx context.Context) iter.Seq2[Document, error] {
+	return func(yield func(Document, error) bool) {
+		if o.Feeder.Len() == 0 {
+			yield(Document{}, ErrNothingToScan)
+			return
+		}
+		for i := 1; ; i++ {
+			if err := ctx.Err(); err != nil {
+				yield(Document{}, err)
+				return
+			}
+			if err := o.ready(); err != nil {
+				yield(Document{}, err)
+				return
+			}
+			original, ok := o.Feeder.next()
+			if !ok {
+				return
+			}
+			var scanned bytes.Buffer
+			if err := scan(&scanned, original); err != nil {
+				yield(Document{}, err)
+				return
+			}
+			if !yield(Document{Name: fmt.Sprintf("page-%03d", i), Content: scanned.Bytes(), Format: original.Format}, nil) {
+				return
+			}
+		}
+	}
+}
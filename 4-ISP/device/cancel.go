@@ -0,0 +1,24 @@
+package device
+
+import "context"
+
+// ContextPrinter is implemented by printers that can abandon a job half
+// way when ctx is done. Print alone cannot be told to stop once it has
+// started.
+type ContextPrinter interface {
+	Printer
+	PrintContext(ctx context.Context, doc Document) error
+}
+
+// PrintContext prints doc on p and returns ctx.Err() promptly once ctx is
+// done. A Printer that is not a ContextPrinter can only be stopped before
+// the job starts.
+func PrintContext(ctx context.Context, p Printer, doc Document) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c, ok := p.(ContextPrinter); ok {
+		return c.PrintContext(ctx, doc)
+	}
+	return p.Print(doc)
+}
@@ -0,0 +1,127 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/imrancluster/go-solid/4-ISP/notify"
+)
+
+// DEFAULT_LOW_SUPPLY is the level below which a supply counts as low
+const DEFAULT_LOW_SUPPLY = 0.15
+
+// SupplyKind is what a consumable is
+type SupplyKind string
+
+const (
+	SupplyToner SupplyKind = "toner"
+	SupplyInk   SupplyKind = "ink"
+	SupplyDrum  SupplyKind = "drum"
+)
+
+// Supply is one consumable and how much of it is left
+type Supply struct {
+	Name string
+	Kind SupplyKind
+	// Level goes from 0, empty, to 1, full
+	Level float64
+}
+
+// ConsumableReporter is implemented by devices that know their supply
+// levels. Plenty of printers cannot tell, so it is a role of its own.
+type ConsumableReporter interface {
+	Consumables() ([]Supply, error)
+}
+
+// Consumables reports the Supplies field
+func (o OfficeMFP) Consumables() ([]Supply, error) {
+	if o.Offline {
+		return nil, ErrOffline
+	}
+	return slices.Clone(o.Supplies), nil
+}
+
+// LowSupply is a supply of a registered device that runs low
+type LowSupply struct {
+	Device string
+	Supply
+}
+
+// SupplyAlerter notifies once when a supply of a registered device runs
+// low, and again only after it was refilled and ran low once more. The
+// zero value needs a Registry and a Notifier.
+type SupplyAlerter struct {
+	Registry *Registry
+	Notifier notify.Notifier
+	// Threshold defaults to DEFAULT_LOW_SUPPLY
+	Threshold float64
+
+	mu      sync.Mutex
+	alerted map[string]bool
+}
+
+// Check asks every ConsumableReporter for its levels and notifies about
+// the supplies that newly ran low. It returns every supply that is low,
+// alerted before or not, and the errors of devices that could not report.
+func (a *SupplyAlerter) Check(ctx context.Context) ([]LowSupply, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.alerted == nil {
+		a.alerted = make(map[string]bool)
+	}
+	threshold := a.Threshold
+	if threshold <= 0 {
+		threshold = DEFAULT_LOW_SUPPLY
+	}
+
+	var low []LowSupply
+	var errs []error
+	for _, name := range a.Registry.Names() {
+		dev, err := a.Registry.Lookup(name)
+		if err != nil {
+			continue
+		}
+		reporter, ok := dev.(ConsumableReporter)
+		if !ok {
+			continue
+		}
+		supplies, err := reporter.Consumables()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		for _, s := range supplies {
+			key := name + "/" + s.Name
+			if s.Level >= threshold {
+				delete(a.alerted, key)
+				continue
+			}
+			low = append(low, LowSupply{name, s})
+			if a.alerted[key] {
+				continue
+			}
+			if err := a.Notifier.Notify(ctx, lowSupplyNotification(name, s)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			a.alerted[key] = true
+		}
+	}
+	return low, errors.Join(errs...)
+}
+
+func lowSupplyNotification(device string, s Supply) notify.Notification {
+	n := notify.Notification{
+		Severity: notify.SeverityWarning,
+		Subject:  fmt.Sprintf("%s is low on %s", device, s.Kind),
+		Body:     fmt.Sprintf("%s is at %.0f%%", s.Name, s.Level*100),
+	}
+	if s.Level <= 0 {
+		n.Severity = notify.SeverityCritical
+		n.Subject = fmt.Sprintf("%s is out of %s", device, s.Kind)
+	}
+	return n
+}
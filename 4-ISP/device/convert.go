@@ -0,0 +1,193 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrNoConverter is returned when no chain of converters reaches a format
+// the device prints
+var ErrNoConverter = errors.New("device: no converter")
+
+// Converter turns a document into another format
+type Converter interface {
+	Convert(doc Document) (Document, error)
+}
+
+// ConverterFunc lets an ordinary function convert documents
+type ConverterFunc func(doc Document) (Document, error)
+
+func (f ConverterFunc) Convert(doc Document) (Document, error) {
+	return f(doc)
+}
+
+// FormatSupporter is a printer that tells which formats it prints.
+// Printers that do not are assumed to print every format.
+type FormatSupporter interface {
+	Printer
+	Formats() []Format
+}
+
+var (
+	convertersMu sync.RWMutex
+	// converters holds the converter from each format to each other format
+	converters = map[Format]map[Format]Converter{
+		FormatMarkdown: {
+			FormatText: ConverterFunc(func(doc Document) (Document, error) {
+				return Document{Name: doc.Name, Content: []byte(markdownText(string(doc.Content))), Format: FormatText}, nil
+			}),
+			FormatPDF: ConverterFunc(func(doc Document) (Document, error) {
+				return Document{Name: doc.Name, Content: renderPDF(markdownText(string(doc.Content))), Format: FormatPDF}, nil
+			}),
+		},
+		FormatText: {
+			FormatPDF: ConverterFunc(func(doc Document) (Document, error) {
+				return Document{Name: doc.Name, Content: renderPDF(string(doc.Content)), Format: FormatPDF}, nil
+			}),
+			FormatPostScript: ConverterFunc(func(doc Document) (Document, error) {
+				return Document{Name: doc.Name, Content: renderPostScript(string(doc.Content)), Format: FormatPostScript}, nil
+			}),
+		},
+	}
+)
+
+// RegisterConverter makes documents in format from convertible to format
+// to. It panics if converter is nil or the pair is already registered.
+func RegisterConverter(from, to Format, converter Converter) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	if converter == nil {
+		panic("device: RegisterConverter converter is nil")
+	}
+	if _, dup := converters[from][to]; dup {
+		panic(fmt.Sprintf("device: RegisterConverter called twice for %s to %s", from, to))
+	}
+	if converters[from] == nil {
+		converters[from] = make(map[Format]Converter)
+	}
+	converters[from][to] = converter
+}
+
+// Convert turns doc into one of formats through the shortest chain of
+// registered converters, markdown to PostScript going through text for
+// instance. A document already in one of formats is returned as it is.
+func Convert(doc Document, formats ...Format) (Document, error) {
+	chain, err := conversion(doc.Format, formats)
+	if err != nil {
+		return Document{}, fmt.Errorf("%w from %s to %v for %s", err, doc.Format, formats, doc.Name)
+	}
+	for _, converter := range chain {
+		if doc, err = converter.Convert(doc); err != nil {
+			return Document{}, err
+		}
+	}
+	return doc, nil
+}
+
+// conversion finds the shortest chain of converters from format to one of
+// formats with a breadth first search
+func conversion(format Format, formats []Format) ([]Converter, error) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	chains := map[Format][]Converter{format: nil}
+	for next := []Format{format}; len(next) > 0; {
+		current := next[0]
+		next = next[1:]
+		if slices.Contains(formats, current) {
+			return chains[current], nil
+		}
+		// visit the targets in a fixed order, so ties always pick the
+		// same chain
+		targets := make([]Format, 0, len(converters[current]))
+		for to := range converters[current] {
+			targets = append(targets, to)
+		}
+		slices.Sort(targets)
+		for _, to := range targets {
+			if _, seen := chains[to]; seen {
+				continue
+			}
+			chains[to] = append(slices.Clip(chains[current]), converters[current][to])
+			next = append(next, to)
+		}
+	}
+	return nil, ErrNoConverter
+}
+
+// ConvertingPrinter converts documents to a format Printer prints before
+// handing them over, so callers need not know what the device takes
+type ConvertingPrinter struct {
+	Printer Printer
+}
+
+func (c ConvertingPrinter) Print(doc Document) error {
+	supporter, ok := c.Printer.(FormatSupporter)
+	if !ok {
+		return c.Printer.Print(doc)
+	}
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	converted, err := Convert(doc, supporter.Formats()...)
+	if err != nil {
+		return err
+	}
+	return c.Printer.Print(converted)
+}
+
+var (
+	markdownLink = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)]*)\)`)
+	// markdownEmphasis are bold, italic and code spans, strongest first
+	markdownEmphasis = []*regexp.Regexp{
+		regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`),
+		regexp.MustCompile(`__(\S(?:.*?\S)?)__`),
+		regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`),
+		regexp.MustCompile("`([^`]+)`"),
+	}
+)
+
+// markdownText strips the markup of the common markdown constructs,
+// keeping the text a reader would see
+func markdownText(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			line = ""
+		case strings.HasPrefix(trimmed, "#"):
+			line = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		case strings.HasPrefix(trimmed, "> "):
+			line = strings.TrimPrefix(trimmed, "> ")
+		case strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ "):
+			line = line[:len(line)-len(trimmed)] + "- " + trimmed[2:]
+		}
+		line = markdownLink.ReplaceAllString(line, "$1 ($2)")
+		for _, emphasis := range markdownEmphasis {
+			line = emphasis.ReplaceAllString(line, "$1")
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPostScript typesets text on A4 pages in Helvetica like renderPDF
+func renderPostScript(text string) []byte {
+	pages := slices.Collect(slices.Chunk(wrap(text, PDF_LINE_WIDTH), PDF_LINES_PER_PAGE))
+	var b strings.Builder
+	fmt.Fprintf(&b, "%%!PS-Adobe-3.0\n%%%%Pages: %d\n%%%%EndComments\n", len(pages))
+	b.WriteString("/Helvetica findfont 11 scalefont setfont\n")
+	for i, lines := range pages {
+		fmt.Fprintf(&b, "%%%%Page: %d %d\n", i+1, i+1)
+		for j, line := range lines {
+			fmt.Fprintf(&b, "50 %d moveto (%s) show\n", 779-13*j, pdfString(line))
+		}
+		b.WriteString("showpage\n")
+	}
+	b.WriteString("%%EOF\n")
+	return []byte(b.String())
+}
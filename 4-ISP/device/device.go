@@ -0,0 +1,215 @@
+// Package device models office devices through small role interfaces.
+// Each device implements only the roles it can actually perform, so no
+// device has to stub out a method it does not support.
+package device
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Split the functionalities into smaller interfaces
+type Printer interface {
+	Print(doc Document) error
+}
+
+// Scanner streams the scanned pages to dst, so large scans never have to
+// fit in memory
+type Scanner interface {
+	Scan(dst io.Writer) error
+}
+
+type Faxer interface {
+	Fax(number string, doc Document) error
+}
+
+type Copier interface {
+	Copy(copies int) error
+}
+
+type Stapler interface {
+	Staple(doc Document) error
+}
+
+// MultiFunctionDevice composes the roles for the few callers that really
+// need all of them on one device. Everything else should ask for the
+// narrow role it uses, so simpler devices stay usable.
+type MultiFunctionDevice interface {
+	Printer
+	Scanner
+	Faxer
+}
+
+// Every device is checked at compile time against the roles it claims
+var (
+	_ Printer             = SimplePrinter{}
+	_ Printer             = MultifunctionPrinter{}
+	_ Scanner             = MultifunctionPrinter{}
+	_ ConfigurablePrinter = MultifunctionPrinter{}
+	_ ConfigurableScanner = MultifunctionPrinter{}
+	_ MultiFunctionDevice = OfficeMFP{}
+	_ Copier              = OfficeMFP{}
+	_ Stapler             = OfficeMFP{}
+	_ ConfigurablePrinter = OfficeMFP{}
+	_ ConfigurableScanner = OfficeMFP{}
+	_ StatusReporter      = OfficeMFP{}
+	_ TrayManager         = OfficeMFP{}
+	_ ConsumableReporter  = OfficeMFP{}
+	_ Maintainer          = OfficeMFP{}
+	_ BatchScanner        = OfficeMFP{}
+	_ FormatSupporter     = LabelPrinter{}
+	_ FormatSupporter     = ReceiptPrinter{}
+	_ ContextPrinter      = (*SimulatedMFP)(nil)
+	_ Scanner             = (*SimulatedMFP)(nil)
+	_ StatusReporter      = (*SimulatedMFP)(nil)
+	_ Maintainer          = (*SimulatedMFP)(nil)
+	_ Printer             = FaxMachine{}
+	_ Faxer               = FaxMachine{}
+	_ Printer             = (*FilePrinter)(nil)
+	_ FormatSupporter     = (*PDFPrinter)(nil)
+	_ Printer             = ConvertingPrinter{}
+	_ ContextPrinter      = (*NetworkPrinter)(nil)
+	_ StatusReporter      = (*NetworkPrinter)(nil)
+	_ Updatable           = (*NetworkPrinter)(nil)
+	_ Updatable           = (*Firmware)(nil)
+)
+
+// A normal printer implements only the Printer interface
+type SimplePrinter struct {
+	// Out is where printed pages go, it defaults to os.Stdout
+	Out io.Writer
+}
+
+func (p SimplePrinter) Print(doc Document) error {
+	return printDoc(p.Out, doc)
+}
+
+// A multifunction printer implements both Printer and Scanner
+type MultifunctionPrinter struct {
+	// Out is where printed pages go, it defaults to os.Stdout
+	Out io.Writer
+	// Original is what lies on the scanner glass
+	Original Document
+}
+
+func (m MultifunctionPrinter) Print(doc Document) error {
+	return printDoc(m.Out, doc)
+}
+
+func (m MultifunctionPrinter) Scan(dst io.Writer) error {
+	return scan(dst, m.Original)
+}
+
+// OfficeMFP is the big office machine that performs every role
+type OfficeMFP struct {
+	// Out is where printed pages go, it defaults to os.Stdout
+	Out io.Writer
+	// Original is what lies on the scanner glass
+	Original Document
+	// Offline machines refuse every job with ErrOffline
+	Offline bool
+	// Fault, such as "paper jam", makes every job fail with ErrDeviceFault
+	Fault string
+	// Paper is what is loaded in the trays, nil when nobody said
+	Paper *PaperTrays
+	// Supplies are the consumables and their levels
+	Supplies []Supply
+	// Feeder holds the originals for batch scans, nil when it is empty
+	Feeder *DocumentFeeder
+}
+
+func (o OfficeMFP) Print(doc Document) error {
+	if err := o.ready(); err != nil {
+		return err
+	}
+	return printDoc(o.Out, doc)
+}
+
+func (o OfficeMFP) Scan(dst io.Writer) error {
+	if err := o.ready(); err != nil {
+		return err
+	}
+	return scan(dst, o.Original)
+}
+
+func (o OfficeMFP) Fax(number string, doc Document) error {
+	if err := o.ready(); err != nil {
+		return err
+	}
+	return fax(o.Out, number, doc)
+}
+
+// Copy scans the original and prints it copies times
+func (o OfficeMFP) Copy(copies int) error {
+	doc, err := ScanToMemory(o, "copy of "+o.Original.Name, o.Original.Format)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < copies; i++ {
+		if err := o.Print(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o OfficeMFP) Staple(doc Document) error {
+	if err := o.ready(); err != nil {
+		return err
+	}
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(output(o.Out), "Stapling %s\n", doc.Name)
+	return err
+}
+
+// A fax machine sends faxes and prints the ones it receives, it cannot
+// scan, copy or staple
+type FaxMachine struct {
+	// Out is where printed pages go, it defaults to os.Stdout
+	Out io.Writer
+}
+
+func (f FaxMachine) Fax(number string, doc Document) error {
+	return fax(f.Out, number, doc)
+}
+
+func (f FaxMachine) Print(doc Document) error {
+	return printDoc(f.Out, doc)
+}
+
+func output(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+// printDoc writes doc to w the way every printer here does
+func printDoc(w io.Writer, doc Document) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(output(w), "Printing %s (%s, %d bytes)\n%s\n", doc.Name, doc.Format, len(doc.Content), doc.Content)
+	return err
+}
+
+// scan streams what lies on the glass to dst
+func scan(dst io.Writer, original Document) error {
+	if len(original.Content) == 0 {
+		return ErrNothingToScan
+	}
+	_, err := io.Copy(dst, bytes.NewReader(original.Content))
+	return err
+}
+
+func fax(w io.Writer, number string, doc Document) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(output(w), "Faxing %s to %s\n", doc.Name, number)
+	return err
+}
@@ -0,0 +1,70 @@
+package device
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrEmptyDocument is returned for documents without content
+	ErrEmptyDocument = errors.New("device: document is empty")
+	// ErrUnsupportedFormat is returned for a format the device cannot handle
+	ErrUnsupportedFormat = errors.New("device: unsupported document format")
+	// ErrNothingToScan is returned when a scanner has no original loaded
+	ErrNothingToScan = errors.New("device: nothing to scan")
+)
+
+// Format is the MIME type of a document's content
+type Format string
+
+const (
+	FormatText       Format = "text/plain"
+	FormatMarkdown   Format = "text/markdown"
+	FormatPDF        Format = "application/pdf"
+	FormatPostScript Format = "application/postscript"
+	FormatImage      Format = "image/png"
+)
+
+// FORMATS are the formats documents may come in
+var FORMATS = []Format{FormatText, FormatMarkdown, FormatPDF, FormatPostScript, FormatImage}
+
+// Document is what devices print, scan, fax and staple
+type Document struct {
+	Name    string
+	Content []byte
+	Format  Format
+}
+
+// Text returns a plain text document
+func Text(name, content string) Document {
+	return Document{Name: name, Content: []byte(content), Format: FormatText}
+}
+
+// Validate checks that doc has content in one of FORMATS
+func (d Document) Validate() error {
+	if len(d.Content) == 0 {
+		return fmt.Errorf("%w: %q", ErrEmptyDocument, d.Name)
+	}
+	if !slices.Contains(FORMATS, d.Format) {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, d.Format)
+	}
+	return nil
+}
+
+// Pages estimates how many pages doc prints on: text by its lines, a PDF
+// by its page objects, PostScript by its showpage operators and anything
+// else as a single page
+func (d Document) Pages() int {
+	switch d.Format {
+	case FormatText, FormatMarkdown:
+		lines := len(wrap(string(d.Content), PDF_LINE_WIDTH))
+		return max(1, (lines+PDF_LINES_PER_PAGE-1)/PDF_LINES_PER_PAGE)
+	case FormatPDF:
+		return max(1, bytes.Count(d.Content, []byte("/Type /Page"))-bytes.Count(d.Content, []byte("/Type /Pages")))
+	case FormatPostScript:
+		return max(1, bytes.Count(d.Content, []byte("showpage")))
+	}
+	return 1
+}
@@ -0,0 +1,86 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownScheme is returned by Open for URIs whose scheme has no driver
+var ErrUnknownScheme = errors.New("device: no driver for scheme")
+
+// Driver builds a printer from the URI it was opened with
+type Driver func(uri *url.URL) (Printer, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Driver{
+		"console": func(*url.URL) (Printer, error) { return SimplePrinter{}, nil },
+		"file": func(uri *url.URL) (Printer, error) {
+			if uri.Path == "" {
+				return nil, fmt.Errorf("device: file URI %q has no path", uri)
+			}
+			// FilePrinter appends plain text, which would not leave a PDF behind
+			if strings.EqualFold(path.Ext(uri.Path), ".pdf") {
+				return &PDFPrinter{Path: uri.Path}, nil
+			}
+			return &FilePrinter{Path: uri.Path}, nil
+		},
+		"pdf": func(uri *url.URL) (Printer, error) {
+			if uri.Path == "" {
+				return nil, fmt.Errorf("device: pdf URI %q has no directory", uri)
+			}
+			return &PDFPrinter{Dir: uri.Path}, nil
+		},
+		"ipp":  ippDriver,
+		"ipps": ippDriver,
+	}
+)
+
+// RegisterDriver makes a printer available by URI scheme, like
+// database/sql drivers. It panics if driver is nil or scheme is already
+// registered.
+func RegisterDriver(scheme string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if driver == nil {
+		panic("device: RegisterDriver driver is nil")
+	}
+	if _, dup := drivers[scheme]; dup {
+		panic("device: RegisterDriver called twice for scheme " + scheme)
+	}
+	drivers[scheme] = driver
+}
+
+// Open builds the printer uri points at, such as console: or
+// file:///tmp/out.txt. A file URI ending in .pdf renders PDFs to that file.
+func Open(uri string) (Printer, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	driversMu.RLock()
+	driver, ok := drivers[u.Scheme]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownScheme, u.Scheme)
+	}
+	return driver(u)
+}
+
+// Schemes lists the registered driver schemes in sorted order
+func Schemes() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	schemes := make([]string, 0, len(drivers))
+	for scheme := range drivers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return slices.Clip(schemes)
+}
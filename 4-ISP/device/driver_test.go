@@ -0,0 +1,65 @@
+package device
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want Printer
+	}{
+		{"console:", SimplePrinter{}},
+		{"file:///tmp/out.txt", &FilePrinter{Path: "/tmp/out.txt"}},
+		{"pdf:///tmp/prints", &PDFPrinter{Dir: "/tmp/prints"}},
+		{"file:///tmp/out.pdf", &PDFPrinter{Path: "/tmp/out.pdf"}},
+	}
+	for _, tt := range tests {
+		p, err := Open(tt.uri)
+		if err != nil {
+			t.Errorf("Open(%q) returned error: %v", tt.uri, err)
+			continue
+		}
+		if !reflect.DeepEqual(p, tt.want) {
+			t.Errorf("Open(%q) = %#v, want %#v", tt.uri, p, tt.want)
+		}
+	}
+}
+
+// TestOpenFilePDF makes sure a file URI ending in .pdf renders a PDF to
+// exactly that file instead of appending plain text to it
+func TestOpenFilePDF(t *testing.T) {
+	for _, name := range []string{"out.pdf", "OUT.PDF"} {
+		path := filepath.Join(t.TempDir(), name)
+		p, err := Open("file://" + path)
+		if err != nil {
+			t.Fatalf("Open(file://%s) returned error: %v", path, err)
+		}
+		for _, text := range []string{"first print", "second print"} {
+			if err := p.Print(Document{Name: "report.txt", Format: FormatText, Content: []byte(text)}); err != nil {
+				t.Fatalf("Print returned error: %v", err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("the print did not write %s: %v", path, err)
+			}
+			if !bytes.HasPrefix(content, []byte("%PDF-")) || !bytes.Contains(content, []byte(text)) {
+				t.Errorf("%s holds %q, want a PDF of %q", path, content, text)
+			}
+		}
+		if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+			t.Errorf("printing to %s left %d files, want 1", path, len(entries))
+		}
+	}
+}
+
+func TestOpenUnknownScheme(t *testing.T) {
+	if _, err := Open("lpd://printer"); !errors.Is(err, ErrUnknownScheme) {
+		t.Errorf("Open(lpd://printer) error = %v, want %v", err, ErrUnknownScheme)
+	}
+}
@@ -0,0 +1,23 @@
+package device
+
+import "os"
+
+// FilePrinter prints by appending documents to the file at Path
+type FilePrinter struct {
+	Path string
+}
+
+func (f *FilePrinter) Print(doc Document) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	err = printDoc(file, doc)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
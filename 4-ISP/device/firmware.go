@@ -0,0 +1,97 @@
+package device
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// FIRMWARE_MAGIC starts the first line of every firmware image, which is
+// followed by the version: "firmware 2.1.0"
+const FIRMWARE_MAGIC = "firmware "
+
+var (
+	// ErrBadFirmware is returned for images a device refuses to install
+	ErrBadFirmware = errors.New("device: bad firmware image")
+	// ErrNoRollback is returned when there is no earlier firmware to go
+	// back to
+	ErrNoRollback = errors.New("device: no firmware to roll back to")
+	// ErrNotUpdatable is returned by network printers whose server does
+	// not take firmware updates
+	ErrNotUpdatable = errors.New("device: firmware cannot be updated")
+)
+
+// Updatable is implemented by devices whose firmware can be replaced.
+// Devices keep the firmware they replaced, so an update that turns out
+// bad can be rolled back.
+type Updatable interface {
+	FirmwareVersion() (string, error)
+	// ApplyUpdate installs the image read from r. A device that refuses the
+	// image keeps the firmware it has.
+	ApplyUpdate(ctx context.Context, r io.Reader) error
+	// Rollback goes back to the firmware the last update replaced
+	Rollback(ctx context.Context) error
+}
+
+// Firmware is the firmware of a device, with a slot for the version it
+// replaced. It is safe for concurrent use.
+type Firmware struct {
+	mu       sync.Mutex
+	current  string
+	previous string
+}
+
+// NewFirmware returns firmware running version
+func NewFirmware(version string) *Firmware {
+	return &Firmware{current: version}
+}
+
+func (f *Firmware) FirmwareVersion() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.current, nil
+}
+
+// ApplyUpdate refuses images without a version or without a payload
+func (f *Firmware) ApplyUpdate(ctx context.Context, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	image := bufio.NewReader(r)
+	header, err := image.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	version, ok := strings.CutPrefix(strings.TrimSpace(header), FIRMWARE_MAGIC)
+	if !ok || version == "" {
+		return fmt.Errorf("%w: missing %q header", ErrBadFirmware, FIRMWARE_MAGIC)
+	}
+	n, err := io.Copy(io.Discard, image)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s has no payload", ErrBadFirmware, version)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.previous, f.current = f.current, version
+	return nil
+}
+
+func (f *Firmware) Rollback(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.previous == "" {
+		return ErrNoRollback
+	}
+	f.current, f.previous = f.previous, ""
+	return nil
+}
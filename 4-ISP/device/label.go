@@ -0,0 +1,75 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DEFAULT_LABEL_COLUMNS = 32
+	DEFAULT_LABEL_ROWS    = 4
+)
+
+// ErrDocumentTooLarge is returned for documents that do not fit the media
+var ErrDocumentTooLarge = errors.New("device: document too large")
+
+// LabelPrinter prints short plain text labels, such as addresses and
+// shelf tags. It is a Printer and nothing else: a device this narrow fits
+// the narrow role without stubbing anything.
+type LabelPrinter struct {
+	// Out is where printed labels go, it defaults to os.Stdout
+	Out io.Writer
+	// Columns and Rows are the size of a label in characters, they default
+	// to DEFAULT_LABEL_COLUMNS and DEFAULT_LABEL_ROWS
+	Columns int
+	Rows    int
+}
+
+func (l LabelPrinter) Formats() []Format {
+	return []Format{FormatText}
+}
+
+// Print rejects anything but plain text, and text that does not fit on a
+// label, rather than cutting it off
+func (l LabelPrinter) Print(doc Document) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	if doc.Format != FormatText {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, doc.Format)
+	}
+	columns, rows := l.size()
+	lines := strings.Split(strings.TrimRight(string(doc.Content), "\n"), "\n")
+	if len(lines) > rows {
+		return fmt.Errorf("%w: %q has %d lines, a label fits %d", ErrDocumentTooLarge, doc.Name, len(lines), rows)
+	}
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n > columns {
+			return fmt.Errorf("%w: %q has a line of %d characters, a label fits %d", ErrDocumentTooLarge, doc.Name, n, columns)
+		}
+	}
+
+	border := "+" + strings.Repeat("-", columns) + "+\n"
+	var b strings.Builder
+	b.WriteString(border)
+	for _, line := range lines {
+		fmt.Fprintf(&b, "|%-*s|\n", columns, line)
+	}
+	b.WriteString(border)
+	_, err := io.WriteString(output(l.Out), b.String())
+	return err
+}
+
+func (l LabelPrinter) size() (columns, rows int) {
+	columns, rows = l.Columns, l.Rows
+	if columns <= 0 {
+		columns = DEFAULT_LABEL_COLUMNS
+	}
+	if rows <= 0 {
+		rows = DEFAULT_LABEL_ROWS
+	}
+	return columns, rows
+}
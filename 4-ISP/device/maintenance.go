@@ -0,0 +1,158 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/imrancluster/go-solid/clock"
+)
+
+// ErrorCode is a fault as the device's service manual names it
+type ErrorCode struct {
+	Code    string
+	Message string
+}
+
+// FAULT_CODES map the faults of an OfficeMFP to their error codes. Faults
+// not listed here are reported as E-999.
+var FAULT_CODES = map[string]string{
+	"paper jam":     "E-201",
+	"door open":     "E-301",
+	"toner empty":   "E-401",
+	"fuser too hot": "E-501",
+}
+
+// Maintainer is implemented by devices that can look after themselves.
+// Only the service tools need it, which is why it is not part of any of
+// the roles that do the office work.
+type Maintainer interface {
+	// SelfTest runs the built-in test and returns why it failed
+	SelfTest(ctx context.Context) error
+	// CleanHeads runs the cleaning cycle of the print heads
+	CleanHeads(ctx context.Context) error
+	// ErrorCodes lists the faults the device currently has
+	ErrorCodes() ([]ErrorCode, error)
+}
+
+func (o OfficeMFP) SelfTest(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return o.ready()
+}
+
+func (o OfficeMFP) CleanHeads(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if o.Offline {
+		return ErrOffline
+	}
+	_, err := fmt.Fprintln(output(o.Out), "Cleaning print heads")
+	return err
+}
+
+func (o OfficeMFP) ErrorCodes() ([]ErrorCode, error) {
+	if o.Offline {
+		return nil, ErrOffline
+	}
+	if o.Fault == "" {
+		return nil, nil
+	}
+	code, ok := FAULT_CODES[o.Fault]
+	if !ok {
+		code = "E-999"
+	}
+	return []ErrorCode{{Code: code, Message: o.Fault}}, nil
+}
+
+// Diagnosis is what the diagnostics found on one device
+type Diagnosis struct {
+	Name       string
+	SelfTest   error
+	CleanHeads error
+	Codes      []ErrorCode
+	// CodesErr is why the error codes could not be read
+	CodesErr error
+}
+
+// Healthy reports whether every step passed and no error code is set
+func (d Diagnosis) Healthy() bool {
+	return d.SelfTest == nil && d.CleanHeads == nil && d.CodesErr == nil && len(d.Codes) == 0
+}
+
+// DiagnosticsReport is the outcome of one diagnostics run
+type DiagnosticsReport struct {
+	Time    time.Time
+	Devices []Diagnosis
+}
+
+// Healthy reports whether every device passed
+func (r DiagnosticsReport) Healthy() bool {
+	for _, d := range r.Devices {
+		if !d.Healthy() {
+			return false
+		}
+	}
+	return true
+}
+
+// Render writes the report as a table with a row per device
+func (r DiagnosticsReport) Render(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEVICE\tSELF-TEST\tCLEAN HEADS\tERROR CODES")
+	for _, d := range r.Devices {
+		codes := "-"
+		switch {
+		case d.CodesErr != nil:
+			codes = d.CodesErr.Error()
+		case len(d.Codes) > 0:
+			var list []string
+			for _, c := range d.Codes {
+				list = append(list, c.Code+" "+c.Message)
+			}
+			codes = strings.Join(list, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.Name, outcome(d.SelfTest), outcome(d.CleanHeads), codes)
+	}
+	return tw.Flush()
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+// Diagnostics runs the maintenance routines of every registered Maintainer.
+// Devices that cannot maintain themselves are left out of the report.
+type Diagnostics struct {
+	Registry *Registry
+	// Clock stamps the reports, it defaults to the real clock
+	Clock clock.Clock
+}
+
+// Run self-tests every Maintainer, cleans its heads and reads its error
+// codes. A failing device does not stop the run.
+func (d Diagnostics) Run(ctx context.Context) DiagnosticsReport {
+	report := DiagnosticsReport{Time: clock.Or(d.Clock).Now()}
+	for _, name := range d.Registry.Names() {
+		dev, err := d.Registry.Lookup(name)
+		if err != nil {
+			continue
+		}
+		m, ok := dev.(Maintainer)
+		if !ok {
+			continue
+		}
+		diagnosis := Diagnosis{Name: name, SelfTest: m.SelfTest(ctx), CleanHeads: m.CleanHeads(ctx)}
+		diagnosis.Codes, diagnosis.CodesErr = m.ErrorCodes()
+		report.Devices = append(report.Devices, diagnosis)
+	}
+	return report
+}
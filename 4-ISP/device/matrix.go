@@ -0,0 +1,117 @@
+package device
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ROLES are every role Roles discovers, in the columns of a Matrix
+var ROLES = []Role{
+	RolePrint, RoleScan, RoleFax, RoleCopy, RoleStaple, RoleStatus, RoleOptions,
+	RoleScanOptions, RoleTrays, RoleConsumables, RoleMaintenance, RoleFirmware,
+	RoleFormats, RoleBatchScan,
+}
+
+// roleInterfaces names the interface a device is asserted to for each role
+var roleInterfaces = map[Role]string{
+	RolePrint:       "Printer",
+	RoleScan:        "Scanner",
+	RoleFax:         "Faxer",
+	RoleCopy:        "Copier",
+	RoleStaple:      "Stapler",
+	RoleStatus:      "StatusReporter",
+	RoleOptions:     "ConfigurablePrinter",
+	RoleScanOptions: "ConfigurableScanner",
+	RoleTrays:       "TrayManager",
+	RoleConsumables: "ConsumableReporter",
+	RoleMaintenance: "Maintainer",
+	RoleFirmware:    "Updatable",
+	RoleFormats:     "FormatSupporter",
+	RoleBatchScan:   "BatchScanner",
+}
+
+// MatrixRow is one device of a Matrix
+type MatrixRow struct {
+	Name string
+	// Type is the concrete type of the device
+	Type  string
+	Roles []Role
+}
+
+// Has reports whether the device plays role
+func (r MatrixRow) Has(role Role) bool {
+	return slices.Contains(r.Roles, role)
+}
+
+// Matrix is a table of devices against the role interfaces they implement
+type Matrix struct {
+	Rows []MatrixRow
+}
+
+// NewMatrix inspects every device of r, in the order they were registered
+func NewMatrix(r *Registry) Matrix {
+	var m Matrix
+	for _, name := range r.Names() {
+		dev, err := r.Lookup(name)
+		if err != nil {
+			continue
+		}
+		m.Rows = append(m.Rows, MatrixRow{Name: name, Type: fmt.Sprintf("%T", dev), Roles: Roles(dev)})
+	}
+	return m
+}
+
+// Markdown writes the matrix as a markdown table followed by the type
+// assertion that discovers each role
+func (m Matrix) Markdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Device | Type |")
+	for _, role := range ROLES {
+		fmt.Fprintf(&b, " %s |", role)
+	}
+	b.WriteString("\n|---|---|" + strings.Repeat("---|", len(ROLES)) + "\n")
+	for _, row := range m.Rows {
+		fmt.Fprintf(&b, "| %s | `%s` |", markdownCell(row.Name), row.Type)
+		for _, role := range ROLES {
+			if row.Has(role) {
+				b.WriteString(" yes |")
+			} else {
+				b.WriteString(" - |")
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nEach role is found with a type assertion, devices never declare it:\n\n")
+	for _, role := range ROLES {
+		fmt.Fprintf(&b, "- %s: `dev.(device.%s)`\n", role, roleInterfaces[role])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// CSV writes the matrix with a header row and true or false per role
+func (m Matrix) CSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"device", "type"}
+	for _, role := range ROLES {
+		header = append(header, string(role))
+	}
+	cw.Write(header)
+	for _, row := range m.Rows {
+		record := []string{row.Name, row.Type}
+		for _, role := range ROLES {
+			record = append(record, fmt.Sprint(row.Has(role)))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// markdownCell keeps text from breaking out of a table cell
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
@@ -0,0 +1,264 @@
+package device
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	NAME_HEADER      = "X-Document-Name"
+	DEFAULT_IPP_PORT = "631"
+	MAX_JOB_SIZE     = 32 << 20
+	// FIRMWARE_PATH is where a print server takes firmware, below the URL
+	// of its printer
+	FIRMWARE_PATH = "/firmware"
+)
+
+// ErrJobRejected is returned when a network printer refuses a job
+var ErrJobRejected = errors.New("device: print job rejected")
+
+// NetworkPrinter submits documents to a print server over HTTP, the
+// transport IPP runs on. Callers only see a Printer: the protocol, the
+// connection and the server's errors all stay behind Print.
+type NetworkPrinter struct {
+	URL string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+func (n *NetworkPrinter) Print(doc Document) error {
+	return n.PrintContext(context.Background(), doc)
+}
+
+// PrintContext abandons the request when ctx is done
+func (n *NetworkPrinter) PrintContext(ctx context.Context, doc Document) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(doc.Content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", string(doc.Format))
+	req.Header.Set(NAME_HEADER, doc.Name)
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s: %s", ErrJobRejected, resp.Status, strings.TrimSpace(string(reason)))
+	}
+	return nil
+}
+
+// Status asks the print server for its health. A server that cannot be
+// reached is reported offline.
+func (n *NetworkPrinter) Status() (Health, error) {
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(n.URL)
+	if err != nil {
+		return Health{State: StateOffline, Message: err.Error()}, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Health{}, fmt.Errorf("device: status request failed: %s", resp.Status)
+	}
+	var health Health
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return Health{}, err
+	}
+	return health, nil
+}
+
+// FirmwareVersion asks the print server which firmware it runs
+func (n *NetworkPrinter) FirmwareVersion() (string, error) {
+	var version struct {
+		Version string `json:"version"`
+	}
+	resp, err := n.firmware(context.Background(), http.MethodGet, "", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return "", err
+	}
+	return version.Version, nil
+}
+
+// ApplyUpdate uploads the image to the print server
+func (n *NetworkPrinter) ApplyUpdate(ctx context.Context, r io.Reader) error {
+	resp, err := n.firmware(ctx, http.MethodPut, "", r)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (n *NetworkPrinter) Rollback(ctx context.Context) error {
+	resp, err := n.firmware(ctx, http.MethodPost, "/rollback", nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// firmware sends a request below FIRMWARE_PATH and turns the server's
+// refusals back into the errors the firmware returned
+func (n *NetworkPrinter) firmware(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(n.URL, "/")+FIRMWARE_PATH+path, body)
+	if err != nil {
+		return nil, err
+	}
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	var sentinel error
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		sentinel = ErrNotUpdatable
+	case http.StatusBadRequest:
+		sentinel = ErrBadFirmware
+	case http.StatusConflict:
+		sentinel = ErrNoRollback
+	default:
+		return nil, fmt.Errorf("device: firmware request failed: %s: %s", resp.Status, strings.TrimSpace(string(reason)))
+	}
+	// The server sends the error it got, which already names the sentinel
+	detail := strings.TrimPrefix(strings.TrimSpace(string(reason)), sentinel.Error())
+	if detail = strings.TrimPrefix(detail, ": "); detail == "" {
+		return nil, sentinel
+	}
+	return nil, fmt.Errorf("%w: %s", sentinel, detail)
+}
+
+// PrintServer is the other end of a NetworkPrinter: an http.Handler that
+// hands every job it accepts to Printer. With Firmware set it also takes
+// firmware updates below FIRMWARE_PATH.
+type PrintServer struct {
+	Printer  Printer
+	Firmware Updatable
+}
+
+func (s PrintServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, after, ok := strings.Cut(r.URL.Path, FIRMWARE_PATH); ok && (after == "" || after == "/rollback") {
+		s.serveFirmware(w, r, after == "/rollback")
+		return
+	}
+	if r.Method == http.MethodGet {
+		s.serveStatus(w)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MAX_JOB_SIZE))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	doc := Document{Name: r.Header.Get(NAME_HEADER), Content: content, Format: Format(r.Header.Get("Content-Type"))}
+	switch err := s.Printer.Print(doc); {
+	case errors.Is(err, ErrUnsupportedFormat):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	case errors.Is(err, ErrEmptyDocument):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// serveStatus answers with the health of Printer, which is online unless
+// it reports otherwise
+func (s PrintServer) serveStatus(w http.ResponseWriter) {
+	health := Health{State: StateOnline}
+	if reporter, ok := s.Printer.(StatusReporter); ok {
+		var err error
+		if health, err = reporter.Status(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(health)
+}
+
+// serveFirmware reports the firmware version, installs an image or rolls
+// back
+func (s PrintServer) serveFirmware(w http.ResponseWriter, r *http.Request, rollback bool) {
+	if s.Firmware == nil {
+		http.Error(w, ErrNotUpdatable.Error(), http.StatusNotFound)
+		return
+	}
+	var err error
+	switch {
+	case rollback && r.Method == http.MethodPost:
+		err = s.Firmware.Rollback(r.Context())
+	case !rollback && r.Method == http.MethodPut:
+		err = s.Firmware.ApplyUpdate(r.Context(), http.MaxBytesReader(w, r.Body, MAX_JOB_SIZE))
+	case !rollback && r.Method == http.MethodGet:
+		var version string
+		if version, err = s.Firmware.FirmwareVersion(); err == nil {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"version": version})
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch {
+	case errors.Is(err, ErrBadFirmware):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNoRollback):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ippDriver opens ipp://host/path as a NetworkPrinter posting to
+// http://host:631/path, and ipps:// the same over https
+func ippDriver(uri *url.URL) (Printer, error) {
+	target := *uri
+	target.Scheme = "http"
+	if uri.Scheme == "ipps" {
+		target.Scheme = "https"
+	}
+	if target.Port() == "" {
+		target.Host += ":" + DEFAULT_IPP_PORT
+	}
+	return &NetworkPrinter{URL: target.String()}, nil
+}
@@ -0,0 +1,108 @@
+package device_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/devicetest"
+)
+
+// newServer serves a PrintServer in front of printer for the test
+func newServer(t *testing.T, printer device.Printer) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(device.PrintServer{Printer: printer})
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNetworkPrinterPrints(t *testing.T) {
+	fake := &devicetest.FakePrinter{}
+	server := newServer(t, fake)
+	p := &device.NetworkPrinter{URL: server.URL, Client: server.Client()}
+
+	doc := device.Document{Name: "notes.md", Content: []byte("# Notes"), Format: device.FormatMarkdown}
+	if err := p.Print(doc); err != nil {
+		t.Fatalf("Print returned error: %v", err)
+	}
+	printed := fake.Printed()
+	if len(printed) != 1 {
+		t.Fatalf("the server printed %d documents, want 1", len(printed))
+	}
+	if got := printed[0]; got.Name != doc.Name || got.Format != doc.Format || string(got.Content) != string(doc.Content) {
+		t.Errorf("the server printed %+v, want %+v", got, doc)
+	}
+}
+
+// TestNetworkPrinterRejected makes sure the server's refusal comes back as
+// ErrJobRejected with the reason it gave
+func TestNetworkPrinterRejected(t *testing.T) {
+	server := newServer(t, &devicetest.FakePrinter{Err: device.ErrUnsupportedFormat})
+	p := &device.NetworkPrinter{URL: server.URL, Client: server.Client()}
+
+	err := p.Print(device.Text("notes.txt", "hello"))
+	if !errors.Is(err, device.ErrJobRejected) {
+		t.Fatalf("Print error = %v, want %v", err, device.ErrJobRejected)
+	}
+	if !strings.Contains(err.Error(), "415") || !strings.Contains(err.Error(), device.ErrUnsupportedFormat.Error()) {
+		t.Errorf("Print error = %v, want the status and the reason of the server", err)
+	}
+}
+
+func TestNetworkPrinterCanceled(t *testing.T) {
+	server := newServer(t, &devicetest.FakePrinter{Delay: 200 * time.Millisecond})
+	p := &device.NetworkPrinter{URL: server.URL, Client: server.Client()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := p.PrintContext(ctx, device.Text("notes.txt", "hello")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("PrintContext error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("PrintContext returned after %v, want it to give up with its context", elapsed)
+	}
+}
+
+func TestNetworkPrinterStatus(t *testing.T) {
+	server := newServer(t, &devicetest.FakePrinter{})
+	p := &device.NetworkPrinter{URL: server.URL, Client: server.Client()}
+
+	health, err := p.Status()
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+	if health.State != device.StateOnline {
+		t.Errorf("Status state = %q, want %q", health.State, device.StateOnline)
+	}
+
+	server.Close()
+	health, err = p.Status()
+	if err != nil {
+		t.Fatalf("Status of a server that is gone returned error: %v", err)
+	}
+	if health.State != device.StateOffline {
+		t.Errorf("Status state of a server that is gone = %q, want %q", health.State, device.StateOffline)
+	}
+}
+
+func TestOpenIPP(t *testing.T) {
+	tests := map[string]string{
+		"ipp://printer/queues/a":     "http://printer:631/queues/a",
+		"ipps://printer:8631/queues": "https://printer:8631/queues",
+	}
+	for uri, want := range tests {
+		p, err := device.Open(uri)
+		if err != nil {
+			t.Fatalf("Open(%q) returned error: %v", uri, err)
+		}
+		n, ok := p.(*device.NetworkPrinter)
+		if !ok || n.URL != want {
+			t.Errorf("Open(%q) = %#v, want a NetworkPrinter posting to %s", uri, p, want)
+		}
+	}
+}
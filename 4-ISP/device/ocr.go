@@ -0,0 +1,97 @@
+package device
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrNoText is returned when OCR finds no text in a document
+var ErrNoText = errors.New("device: no text recognized")
+
+// OCR turns a scanned document into text. It is a role of its own rather
+// than part of Scanner, so scanners stay simple and any OCR engine can be
+// put behind any scanner.
+type OCR interface {
+	Recognize(ctx context.Context, doc Document) (string, error)
+}
+
+// NaiveOCR "recognizes" the runs of readable characters in a document. It
+// is good enough for the text-based scans of the example devices.
+type NaiveOCR struct {
+	// MinRun is the shortest run of readable characters kept, it
+	// defaults to 3
+	MinRun int
+}
+
+func (n NaiveOCR) Recognize(ctx context.Context, doc Document) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	minRun := n.MinRun
+	if minRun <= 0 {
+		minRun = 3
+	}
+	var words []string
+	var run []rune
+	flush := func() {
+		if len(run) >= minRun {
+			words = append(words, strings.TrimSpace(string(run)))
+		}
+		run = run[:0]
+	}
+	for content := doc.Content; len(content) > 0; {
+		r, size := utf8.DecodeRune(content)
+		content = content[size:]
+		if r != utf8.RuneError && (unicode.IsPrint(r) || r == '\n') {
+			run = append(run, r)
+			continue
+		}
+		flush()
+	}
+	flush()
+	text := strings.TrimSpace(strings.Join(words, " "))
+	if text == "" {
+		return "", fmt.Errorf("%w in %s", ErrNoText, doc.Name)
+	}
+	return text, nil
+}
+
+// CommandOCR runs an external OCR engine, such as tesseract, with the
+// document on stdin and reads the text from stdout:
+//
+//	CommandOCR{Command: "tesseract", Args: []string{"stdin", "stdout"}}
+type CommandOCR struct {
+	Command string
+	Args    []string
+}
+
+func (c CommandOCR) Recognize(ctx context.Context, doc Document) (string, error) {
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	cmd.Stdin = bytes.NewReader(doc.Content)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("device: %s: %w: %s", c.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	text := strings.TrimSpace(string(out))
+	if text == "" {
+		return "", fmt.Errorf("%w in %s", ErrNoText, doc.Name)
+	}
+	return text, nil
+}
+
+// ScanText scans with s and recognizes the text with ocr
+func ScanText(ctx context.Context, s Scanner, ocr OCR) (string, error) {
+	doc, err := ScanToMemory(s, "scan", FormatImage)
+	if err != nil {
+		return "", err
+	}
+	return ocr.Recognize(ctx, doc)
+}
@@ -0,0 +1,116 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrUnsupportedOption is returned for print or scan options a device
+// cannot honor
+var ErrUnsupportedOption = errors.New("device: unsupported option")
+
+// PrintOptions are how a document should be printed. The zero value is a
+// single one-sided black and white copy, which every printer can do.
+type PrintOptions struct {
+	Duplex bool
+	Color  bool
+	// Copies below 1 mean one copy
+	Copies int
+}
+
+// PrintCapabilities are the options a printer supports
+type PrintCapabilities struct {
+	Duplex    bool
+	Color     bool
+	MaxCopies int
+}
+
+// Check returns ErrUnsupportedOption for options caps cannot honor
+func (o PrintOptions) Check(caps PrintCapabilities) error {
+	var unsupported []string
+	if o.Duplex && !caps.Duplex {
+		unsupported = append(unsupported, "duplex")
+	}
+	if o.Color && !caps.Color {
+		unsupported = append(unsupported, "color")
+	}
+	if o.Copies > max(caps.MaxCopies, 1) {
+		unsupported = append(unsupported, fmt.Sprintf("%d copies", o.Copies))
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedOption, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+func (o PrintOptions) copies() int {
+	return max(o.Copies, 1)
+}
+
+// ConfigurablePrinter is implemented by printers that take options. Callers
+// ask PrintCapabilities before submitting instead of finding out from an
+// error.
+type ConfigurablePrinter interface {
+	Printer
+	PrintCapabilities() PrintCapabilities
+	PrintWithOptions(doc Document, opts PrintOptions) error
+}
+
+// PrintWith prints doc with opts on any printer. A plain Printer can only
+// make the default single copy, so other options fail with
+// ErrUnsupportedOption instead of being dropped silently.
+func PrintWith(p Printer, doc Document, opts PrintOptions) error {
+	if c, ok := p.(ConfigurablePrinter); ok {
+		return c.PrintWithOptions(doc, opts)
+	}
+	if err := opts.Check(PrintCapabilities{}); err != nil {
+		return err
+	}
+	return p.Print(doc)
+}
+
+func (m MultifunctionPrinter) PrintCapabilities() PrintCapabilities {
+	return PrintCapabilities{MaxCopies: 99}
+}
+
+func (m MultifunctionPrinter) PrintWithOptions(doc Document, opts PrintOptions) error {
+	return printWithOptions(m.Out, doc, opts, m.PrintCapabilities())
+}
+
+func (o OfficeMFP) PrintCapabilities() PrintCapabilities {
+	return PrintCapabilities{Duplex: true, Color: true, MaxCopies: 999}
+}
+
+func (o OfficeMFP) PrintWithOptions(doc Document, opts PrintOptions) error {
+	if err := o.ready(); err != nil {
+		return err
+	}
+	return printWithOptions(o.Out, doc, opts, o.PrintCapabilities())
+}
+
+func printWithOptions(w io.Writer, doc Document, opts PrintOptions, caps PrintCapabilities) error {
+	if err := opts.Check(caps); err != nil {
+		return err
+	}
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	sides, color := "one-sided", "black and white"
+	if opts.Duplex {
+		sides = "duplex"
+	}
+	if opts.Color {
+		color = "color"
+	}
+	for i := 0; i < opts.copies(); i++ {
+		if _, err := fmt.Fprintf(output(w), "[%s, %s, copy %d of %d] ", sides, color, i+1, opts.copies()); err != nil {
+			return err
+		}
+		if err := printDoc(w, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
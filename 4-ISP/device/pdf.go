@@ -0,0 +1,183 @@
+package device
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	PDF_LINES_PER_PAGE = 60
+	PDF_LINE_WIDTH     = 90
+)
+
+// PDFPrinter is a virtual printer that renders every document to a PDF
+// file in Dir, or to Path when it is set. Text and markdown are typeset as
+// plain text, PDFs are written as they are and other formats are rejected.
+type PDFPrinter struct {
+	Dir string
+	// Path is the file every print writes, replacing the one before
+	Path string
+}
+
+func (p *PDFPrinter) Formats() []Format {
+	return []Format{FormatPDF, FormatText, FormatMarkdown}
+}
+
+func (p *PDFPrinter) Print(doc Document) error {
+	_, err := p.Render(doc)
+	return err
+}
+
+// Render prints doc and returns the path of the file it wrote. Without a
+// Path, files are named after the document and never overwrite an earlier
+// print.
+func (p *PDFPrinter) Render(doc Document) (string, error) {
+	if err := doc.Validate(); err != nil {
+		return "", err
+	}
+	var content []byte
+	switch doc.Format {
+	case FormatPDF:
+		content = doc.Content
+	case FormatText, FormatMarkdown:
+		content = renderPDF(string(doc.Content))
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, doc.Format)
+	}
+	file, err := p.create(doc.Name)
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(content)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return file.Name(), err
+}
+
+// create opens Path, or a new file for name numbered when the name is
+// taken
+func (p *PDFPrinter) create(name string) (*os.File, error) {
+	if p.Path != "" {
+		return os.Create(p.Path)
+	}
+	base := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	base = strings.Map(func(r rune) rune {
+		if r == ' ' || r == os.PathSeparator {
+			return '-'
+		}
+		return r
+	}, base)
+	if base == "" || base == "." {
+		base = "document"
+	}
+	for i := 1; ; i++ {
+		path := filepath.Join(p.Dir, base+".pdf")
+		if i > 1 {
+			path = filepath.Join(p.Dir, fmt.Sprintf("%s-%d.pdf", base, i))
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if !errors.Is(err, fs.ErrExist) {
+			return file, err
+		}
+	}
+}
+
+// renderPDF typesets text on A4 pages in Helvetica, the one font every
+// PDF reader has
+func renderPDF(text string) []byte {
+	pages := slices.Collect(slices.Chunk(wrap(text, PDF_LINE_WIDTH), PDF_LINES_PER_PAGE))
+	if len(pages) == 0 {
+		pages = [][]string{nil}
+	}
+
+	// Objects 1 to 3 are the catalog, the page tree and the font, then
+	// every page is followed by its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	for i, lines := range pages {
+		var stream strings.Builder
+		stream.WriteString("BT /F1 11 Tf 13 TL 50 792 Td\n")
+		for _, line := range lines {
+			fmt.Fprintf(&stream, "(%s) '\n", pdfString(line))
+		}
+		stream.WriteString("ET")
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", stream.Len(), stream.String()),
+		)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// wrap splits text into lines of at most width characters, breaking long
+// lines at spaces where it can
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		runes := []rune(strings.TrimRight(line, "\r"))
+		for len(runes) > width {
+			cut := width
+			for i := width; i > 0; i-- {
+				if runes[i] == ' ' {
+					cut = i
+					break
+				}
+			}
+			lines = append(lines, string(runes[:cut]))
+			for len(runes[cut:]) > 0 && runes[cut] == ' ' {
+				cut++
+			}
+			runes = runes[cut:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+// pdfString escapes line for a PDF string literal. Only printable ASCII
+// is written as it is, anything else prints as a question mark.
+func pdfString(line string) string {
+	var b strings.Builder
+	for _, r := range line {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString("    ")
+		case r < ' ' || r > '~':
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
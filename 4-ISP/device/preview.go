@@ -0,0 +1,193 @@
+package device
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// DEFAULT_MEDIA is the paper a preview is laid out on when none is given
+const DEFAULT_MEDIA = MediaA4
+
+// PREVIEW_SHEETS_PER_ROW is how many sheets a PNG preview shows side by
+// side before starting another row
+const PREVIEW_SHEETS_PER_ROW = 4
+
+// mediaPoints is the width and height of each media size in points. A4
+// holds PDF_LINES_PER_PAGE lines of PDF_LINE_WIDTH characters, the other
+// sizes hold as much more or less as their area allows.
+var mediaPoints = map[MediaSize][2]int{
+	MediaA4:     {595, 842},
+	MediaA3:     {842, 1191},
+	MediaLetter: {612, 792},
+	MediaLegal:  {612, 1008},
+}
+
+// Preview is what a document will look like on paper, laid out without
+// printing anything. Pages holds the lines of each page of one copy.
+type Preview struct {
+	Document string
+	Media    MediaSize
+	Options  PrintOptions
+	Pages    [][]string
+}
+
+// NewPreview lays doc out for p with opts on media, which defaults to
+// DEFAULT_MEDIA. It fails like printing would for options p cannot honor,
+// paper none of its trays hold and formats it cannot be converted to.
+// Text and markdown are laid out line by line, other formats show as
+// placeholder pages.
+func NewPreview(p Printer, doc Document, opts PrintOptions, media MediaSize) (Preview, error) {
+	if err := doc.Validate(); err != nil {
+		return Preview{}, err
+	}
+	caps := PrintCapabilities{}
+	if c, ok := p.(ConfigurablePrinter); ok {
+		caps = c.PrintCapabilities()
+	}
+	if err := opts.Check(caps); err != nil {
+		return Preview{}, err
+	}
+	if media == "" {
+		media = DEFAULT_MEDIA
+	}
+	if _, ok := mediaPoints[media]; !ok {
+		return Preview{}, fmt.Errorf("%w: %s paper", ErrUnsupportedOption, media)
+	}
+	if t, ok := p.(TrayManager); ok && !slices.ContainsFunc(t.Trays(), func(t Tray) bool { return t.Media == media }) {
+		return Preview{}, fmt.Errorf("%w: no tray holds %s paper", ErrUnsupportedOption, media)
+	}
+	if f, ok := p.(FormatSupporter); ok {
+		if _, err := conversion(doc.Format, f.Formats()); err != nil {
+			return Preview{}, fmt.Errorf("%w from %s to %v for %s", err, doc.Format, f.Formats(), doc.Name)
+		}
+	}
+
+	preview := Preview{Document: doc.Name, Media: media, Options: opts}
+	columns, rows := layout(media)
+	switch doc.Format {
+	case FormatText, FormatMarkdown:
+		text := string(doc.Content)
+		if doc.Format == FormatMarkdown {
+			text = markdownText(text)
+		}
+		lines := wrap(text, columns)
+		for len(lines) > 0 {
+			n := min(rows, len(lines))
+			preview.Pages = append(preview.Pages, lines[:n])
+			lines = lines[n:]
+		}
+	default:
+		for i := range doc.Pages() {
+			preview.Pages = append(preview.Pages, []string{fmt.Sprintf("[%s page %d]", doc.Format, i+1)})
+		}
+	}
+	return preview, nil
+}
+
+// Sheets is how many sheets of paper printing takes, all copies included
+func (p Preview) Sheets() int {
+	sheets := len(p.Pages)
+	if p.Options.Duplex {
+		sheets = (sheets + 1) / 2
+	}
+	return sheets * p.Options.copies()
+}
+
+// Text writes every page of one copy as a framed block of text, each page
+// labeled with the side of the sheet it lands on
+func (p Preview) Text(w io.Writer) error {
+	columns, _ := layout(p.Media)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s on %s: %d pages, %d sheets, %s\n", p.Document, p.Media, len(p.Pages), p.Sheets(), p.describe())
+	border := "+" + strings.Repeat("-", columns) + "+\n"
+	for i, lines := range p.Pages {
+		sheet, side := p.side(i)
+		fmt.Fprintf(&b, "page %d, sheet %d %s\n%s", i+1, sheet+1, side, border)
+		for _, line := range lines {
+			fmt.Fprintf(&b, "|%s%s|\n", line, strings.Repeat(" ", columns-utf8.RuneCountInString(line)))
+		}
+		b.WriteString(border)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PNG draws one copy as thumbnails, a quarter of a pixel per point, with
+// every line of text as a bar. Duplex pages share a sheet: the back of a
+// sheet is drawn right next to its front.
+func (p Preview) PNG(w io.Writer) error {
+	const scale, gap, margin = 4, 6, 12
+	width, height := mediaPoints[p.Media][0]/scale, mediaPoints[p.Media][1]/scale
+	sides := 1
+	if p.Options.Duplex {
+		sides = 2
+	}
+	sheets := max(1, (len(p.Pages)+sides-1)/sides)
+	perRow := min(sheets, PREVIEW_SHEETS_PER_ROW)
+	sheetWidth := sides*width + (sides-1)*gap/2
+	img := image.NewRGBA(image.Rect(0, 0,
+		perRow*sheetWidth+(perRow+1)*gap,
+		(sheets+perRow-1)/perRow*(height+gap)+gap))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{0xc8}), image.Point{}, draw.Src)
+
+	ink := color.Color(color.Gray{0x40})
+	if p.Options.Color {
+		ink = color.RGBA{0x20, 0x50, 0xb0, 0xff}
+	}
+	columns, rows := layout(p.Media)
+	lineHeight := max(1, (height-2*margin)/rows)
+	for i, lines := range p.Pages {
+		sheet, side := i/sides, i%sides
+		x := gap + sheet%perRow*(sheetWidth+gap) + side*(width+gap/2)
+		y := gap + sheet/perRow*(height+gap)
+		page := image.Rect(x, y, x+width, y+height)
+		draw.Draw(img, page, image.NewUniform(color.White), image.Point{}, draw.Src)
+		for j, line := range lines {
+			length := utf8.RuneCountInString(strings.TrimSpace(line))
+			if length == 0 {
+				continue
+			}
+			top := y + margin + j*lineHeight
+			bar := image.Rect(x+margin, top, x+margin+max(1, length*(width-2*margin)/columns), top+max(1, lineHeight*2/3))
+			draw.Draw(img, bar.Intersect(page), image.NewUniform(ink), image.Point{}, draw.Src)
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// side is the sheet page i lands on and which side of it
+func (p Preview) side(i int) (int, string) {
+	if !p.Options.Duplex {
+		return i, "front"
+	}
+	if i%2 == 1 {
+		return i / 2, "back"
+	}
+	return i / 2, "front"
+}
+
+func (p Preview) describe() string {
+	sides, mode := "one-sided", "black and white"
+	if p.Options.Duplex {
+		sides = "duplex"
+	}
+	if p.Options.Color {
+		mode = "color"
+	}
+	return fmt.Sprintf("%s, %s, %d copies", sides, mode, p.Options.copies())
+}
+
+// layout is how many characters fit on a line of media and how many lines
+// on a page
+func layout(media MediaSize) (columns, rows int) {
+	size := mediaPoints[media]
+	a4 := mediaPoints[MediaA4]
+	return PDF_LINE_WIDTH * size[0] / a4[0], PDF_LINES_PER_PAGE * size[1] / a4[1]
+}
@@ -0,0 +1,43 @@
+package device
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const DEFAULT_RECEIPT_WIDTH = 42
+
+// ReceiptPrinter is a thermal printer on a paper roll. The roll is as long
+// as it needs to be, so unlike a LabelPrinter it wraps wide lines instead
+// of refusing them, and it cuts the paper after every document.
+type ReceiptPrinter struct {
+	// Out is where printed receipts go, it defaults to os.Stdout
+	Out io.Writer
+	// Width is the characters per line, it defaults to DEFAULT_RECEIPT_WIDTH
+	Width int
+}
+
+func (r ReceiptPrinter) Formats() []Format {
+	return []Format{FormatText}
+}
+
+func (r ReceiptPrinter) Print(doc Document) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	if doc.Format != FormatText {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, doc.Format)
+	}
+	width := r.Width
+	if width <= 0 {
+		width = DEFAULT_RECEIPT_WIDTH
+	}
+	var b strings.Builder
+	for _, line := range wrap(string(doc.Content), width) {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(strings.Repeat("- ", width/2) + "cut\n")
+	_, err := io.WriteString(output(r.Out), b.String())
+	return err
+}
@@ -0,0 +1,163 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrDuplicateDevice is returned when registering a name twice
+	ErrDuplicateDevice = errors.New("device: device already registered")
+	// ErrUnknownDevice is returned for names nobody registered
+	ErrUnknownDevice = errors.New("device: unknown device")
+)
+
+// Role names one of the role interfaces a device may implement
+type Role string
+
+const (
+	RolePrint  Role = "print"
+	RoleScan   Role = "scan"
+	RoleFax    Role = "fax"
+	RoleCopy   Role = "copy"
+	RoleStaple Role = "staple"
+	RoleStatus Role = "status"
+	// RoleOptions is a printer that takes PrintOptions
+	RoleOptions Role = "options"
+	// RoleScanOptions is a scanner that takes ScanOptions
+	RoleScanOptions Role = "scan-options"
+	// RoleTrays is a printer that reports its paper trays
+	RoleTrays Role = "trays"
+	// RoleConsumables is a device that reports its supply levels
+	RoleConsumables Role = "consumables"
+	// RoleMaintenance is a device that runs its own maintenance
+	RoleMaintenance Role = "maintenance"
+	// RoleFirmware is a device whose firmware can be updated
+	RoleFirmware Role = "firmware"
+	// RoleFormats is a printer that tells which formats it prints
+	RoleFormats Role = "formats"
+	// RoleBatchScan is a scanner with an automatic document feeder
+	RoleBatchScan Role = "batch-scan"
+)
+
+// Roles reports the role interfaces dev implements. Devices never declare
+// their roles, they are discovered with type assertions.
+func Roles(dev any) []Role {
+	var roles []Role
+	if _, ok := dev.(Printer); ok {
+		roles = append(roles, RolePrint)
+	}
+	if _, ok := dev.(Scanner); ok {
+		roles = append(roles, RoleScan)
+	}
+	if _, ok := dev.(Faxer); ok {
+		roles = append(roles, RoleFax)
+	}
+	if _, ok := dev.(Copier); ok {
+		roles = append(roles, RoleCopy)
+	}
+	if _, ok := dev.(Stapler); ok {
+		roles = append(roles, RoleStaple)
+	}
+	if _, ok := dev.(StatusReporter); ok {
+		roles = append(roles, RoleStatus)
+	}
+	if _, ok := dev.(ConfigurablePrinter); ok {
+		roles = append(roles, RoleOptions)
+	}
+	if _, ok := dev.(ConfigurableScanner); ok {
+		roles = append(roles, RoleScanOptions)
+	}
+	if _, ok := dev.(TrayManager); ok {
+		roles = append(roles, RoleTrays)
+	}
+	if _, ok := dev.(ConsumableReporter); ok {
+		roles = append(roles, RoleConsumables)
+	}
+	if _, ok := dev.(Maintainer); ok {
+		roles = append(roles, RoleMaintenance)
+	}
+	if _, ok := dev.(Updatable); ok {
+		roles = append(roles, RoleFirmware)
+	}
+	if _, ok := dev.(FormatSupporter); ok {
+		roles = append(roles, RoleFormats)
+	}
+	if _, ok := dev.(BatchScanner); ok {
+		roles = append(roles, RoleBatchScan)
+	}
+	return roles
+}
+
+// Registry holds the devices of an office by name, so workflows can ask
+// for every device that plays a role instead of naming concrete devices.
+// The zero value is ready to use.
+type Registry struct {
+	mu      sync.RWMutex
+	names   []string
+	devices map[string]any
+}
+
+// Register adds dev under name
+func (r *Registry) Register(name string, dev any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.devices[name]; dup {
+		return fmt.Errorf("%w: %q", ErrDuplicateDevice, name)
+	}
+	if r.devices == nil {
+		r.devices = make(map[string]any)
+	}
+	r.names = append(r.names, name)
+	r.devices[name] = dev
+	return nil
+}
+
+// Lookup returns the device registered under name
+func (r *Registry) Lookup(name string) (any, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	dev, ok := r.devices[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDevice, name)
+	}
+	return dev, nil
+}
+
+// Names lists the registered devices in the order they were registered
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.names)
+}
+
+// Can lists the names of the devices that play role
+func (r *Registry) Can(role Role) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names []string
+	for _, name := range r.names {
+		if slices.Contains(Roles(r.devices[name]), role) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// All returns every registered device that implements the role interface
+// T, in the order they were registered:
+//
+//	for _, s := range device.All[device.Scanner](registry) { s.Scan() }
+func All[T any](r *Registry) []T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var found []T
+	for _, name := range r.names {
+		if dev, ok := r.devices[name].(T); ok {
+			found = append(found, dev)
+		}
+	}
+	return found
+}
@@ -0,0 +1,33 @@
+package device
+
+import (
+	"bytes"
+	"os"
+)
+
+// ScanToMemory collects a scan in memory, for scans small enough to keep
+// around as a Document
+func ScanToMemory(s Scanner, name string, format Format) (Document, error) {
+	var buf bytes.Buffer
+	if err := s.Scan(&buf); err != nil {
+		return Document{}, err
+	}
+	return Document{Name: name, Content: buf.Bytes(), Format: format}, nil
+}
+
+// ScanToFile streams a scan into the file at path without holding it in
+// memory. A failed scan leaves no file behind.
+func ScanToFile(s Scanner, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = s.Scan(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package device
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const DEFAULT_RESOLUTION = 300
+
+// ColorMode is how a scanner captures color
+type ColorMode string
+
+const (
+	ColorModeBW    ColorMode = "bw"
+	ColorModeGray  ColorMode = "gray"
+	ColorModeColor ColorMode = "color"
+)
+
+// ScanOptions are how a document should be scanned. The zero value is a
+// black and white scan at DEFAULT_RESOLUTION, which every scanner can do.
+type ScanOptions struct {
+	// Resolution in dots per inch, it defaults to DEFAULT_RESOLUTION
+	Resolution int
+	// ColorMode defaults to ColorModeBW
+	ColorMode ColorMode
+}
+
+// ScanCapabilities are the options a scanner supports
+type ScanCapabilities struct {
+	Resolutions []int
+	ColorModes  []ColorMode
+	// MaxColorResolution caps the resolution of color scans, zero means
+	// no cap
+	MaxColorResolution int
+}
+
+func (o ScanOptions) resolution() int {
+	if o.Resolution <= 0 {
+		return DEFAULT_RESOLUTION
+	}
+	return o.Resolution
+}
+
+func (o ScanOptions) colorMode() ColorMode {
+	if o.ColorMode == "" {
+		return ColorModeBW
+	}
+	return o.ColorMode
+}
+
+// Check returns ErrUnsupportedOption for options caps cannot honor,
+// including combinations such as a color scan above MaxColorResolution
+func (o ScanOptions) Check(caps ScanCapabilities) error {
+	var unsupported []string
+	resolution, mode := o.resolution(), o.colorMode()
+	if !slices.Contains(caps.Resolutions, resolution) {
+		unsupported = append(unsupported, fmt.Sprintf("%d dpi", resolution))
+	}
+	if !slices.Contains(caps.ColorModes, mode) {
+		unsupported = append(unsupported, string(mode))
+	}
+	if mode == ColorModeColor && caps.MaxColorResolution > 0 && resolution > caps.MaxColorResolution {
+		unsupported = append(unsupported, fmt.Sprintf("color above %d dpi", caps.MaxColorResolution))
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedOption, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// ConfigurableScanner is implemented by scanners that take options per job
+type ConfigurableScanner interface {
+	Scanner
+	ScanCapabilities() ScanCapabilities
+	ScanWithOptions(dst io.Writer, opts ScanOptions) error
+}
+
+// BASIC_SCAN is what a scanner without options does
+var BASIC_SCAN = ScanCapabilities{Resolutions: []int{DEFAULT_RESOLUTION}, ColorModes: []ColorMode{ColorModeBW}}
+
+// ScanWith scans with opts on any scanner. A plain Scanner only does
+// BASIC_SCAN, so other options fail with ErrUnsupportedOption.
+func ScanWith(s Scanner, dst io.Writer, opts ScanOptions) error {
+	if c, ok := s.(ConfigurableScanner); ok {
+		return c.ScanWithOptions(dst, opts)
+	}
+	if err := opts.Check(BASIC_SCAN); err != nil {
+		return err
+	}
+	return s.Scan(dst)
+}
+
+func (m MultifunctionPrinter) ScanCapabilities() ScanCapabilities {
+	return ScanCapabilities{Resolutions: []int{150, 300}, ColorModes: []ColorMode{ColorModeBW, ColorModeGray}}
+}
+
+func (m MultifunctionPrinter) ScanWithOptions(dst io.Writer, opts ScanOptions) error {
+	if err := opts.Check(m.ScanCapabilities()); err != nil {
+		return err
+	}
+	return m.Scan(dst)
+}
+
+func (o OfficeMFP) ScanCapabilities() ScanCapabilities {
+	return ScanCapabilities{
+		Resolutions:        []int{150, 300, 600, 1200},
+		ColorModes:         []ColorMode{ColorModeBW, ColorModeGray, ColorModeColor},
+		MaxColorResolution: 600,
+	}
+}
+
+func (o OfficeMFP) ScanWithOptions(dst io.Writer, opts ScanOptions) error {
+	if err := opts.Check(o.ScanCapabilities()); err != nil {
+		return err
+	}
+	return o.Scan(dst)
+}
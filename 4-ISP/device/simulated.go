@@ -0,0 +1,162 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// DEFAULT_SIMULATED_FAULTS are what a SimulatedMFP jams with when it was
+// given no faults of its own
+var DEFAULT_SIMULATED_FAULTS = []string{"paper jam"}
+
+// SimulatedMFP behaves like a real multifunction printer whose speed and
+// failures are configurable, for demos and for exercising the failure
+// paths of queues, pools and monitors. The zero value never fails and
+// answers at once. A jam sticks: every job fails with ErrDeviceFault and
+// Status reports the fault until Clear is called, as on a real device.
+type SimulatedMFP struct {
+	// Out is where printed pages go, it defaults to os.Stdout
+	Out io.Writer
+	// Original is what lies on the scanner glass
+	Original Document
+	// PrintLatency and ScanLatency are how long each job takes, Jitter
+	// adds up to as much again at random
+	PrintLatency time.Duration
+	ScanLatency  time.Duration
+	Jitter       time.Duration
+	// JamRate is the share of jobs, from 0 to 1, that jam
+	JamRate float64
+	// Faults are picked at random for each jam, they default to
+	// DEFAULT_SIMULATED_FAULTS. Their error codes come from FAULT_CODES.
+	Faults []string
+	// Seed makes the sequence of jams and delays repeatable
+	Seed uint64
+
+	mu    sync.Mutex
+	rng   *rand.Rand
+	fault string
+}
+
+func (s *SimulatedMFP) Print(doc Document) error {
+	return s.PrintContext(context.Background(), doc)
+}
+
+// PrintContext gives up waiting for the job when ctx is done
+func (s *SimulatedMFP) PrintContext(ctx context.Context, doc Document) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	if err := s.run(ctx, s.PrintLatency); err != nil {
+		return err
+	}
+	return printDoc(s.Out, doc)
+}
+
+func (s *SimulatedMFP) Scan(dst io.Writer) error {
+	if err := s.run(context.Background(), s.ScanLatency); err != nil {
+		return err
+	}
+	return scan(dst, s.Original)
+}
+
+func (s *SimulatedMFP) Status() (Health, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fault != "" {
+		return Health{State: StateError, Message: s.fault}, nil
+	}
+	return Health{State: StateOnline}, nil
+}
+
+// Clear clears a jam, like opening the printer and pulling out the paper
+func (s *SimulatedMFP) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = ""
+}
+
+func (s *SimulatedMFP) SelfTest(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.ready()
+}
+
+func (s *SimulatedMFP) CleanHeads(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(output(s.Out), "Cleaning print heads")
+	return err
+}
+
+func (s *SimulatedMFP) ErrorCodes() ([]ErrorCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fault == "" {
+		return nil, nil
+	}
+	code, ok := FAULT_CODES[s.fault]
+	if !ok {
+		code = "E-999"
+	}
+	return []ErrorCode{{Code: code, Message: s.fault}}, nil
+}
+
+// run waits for a job that takes latency, then rolls whether it jammed
+func (s *SimulatedMFP) run(ctx context.Context, latency time.Duration) error {
+	if err := s.ready(); err != nil {
+		return err
+	}
+	delay, jam := s.roll(latency)
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if jam == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = jam
+	return fmt.Errorf("%w: %s", ErrDeviceFault, jam)
+}
+
+func (s *SimulatedMFP) ready() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fault != "" {
+		return fmt.Errorf("%w: %s", ErrDeviceFault, s.fault)
+	}
+	return nil
+}
+
+// roll draws the delay of the next job and the fault it jams with, if any
+func (s *SimulatedMFP) roll(latency time.Duration) (time.Duration, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rng == nil {
+		s.rng = rand.New(rand.NewPCG(s.Seed, s.Seed))
+	}
+	delay := latency
+	if s.Jitter > 0 {
+		delay += time.Duration(s.rng.Int64N(int64(s.Jitter)))
+	}
+	if s.rng.Float64() >= s.JamRate {
+		return delay, ""
+	}
+	faults := s.Faults
+	if len(faults) == 0 {
+		faults = DEFAULT_SIMULATED_FAULTS
+	}
+	return delay, faults[s.rng.IntN(len(faults))]
+}
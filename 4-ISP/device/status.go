@@ -0,0 +1,137 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imrancluster/go-solid/clock"
+)
+
+const DEFAULT_POLL_INTERVAL = time.Second
+
+var (
+	// ErrOffline is returned by devices that are switched off or unreachable
+	ErrOffline = errors.New("device: offline")
+	// ErrDeviceFault is returned by devices in an error state
+	ErrDeviceFault = errors.New("device: fault")
+)
+
+// State is the overall condition of a device
+type State string
+
+const (
+	StateOnline  State = "online"
+	StateOffline State = "offline"
+	StateError   State = "error"
+	// StateUnknown is what the monitor reports when asking a device failed
+	StateUnknown State = "unknown"
+)
+
+// Health is what a device reports about itself
+type Health struct {
+	State State `json:"state"`
+	// Message explains an error state
+	Message string `json:"message,omitempty"`
+	// QueueDepth is the number of jobs waiting on the device
+	QueueDepth int `json:"queue_depth"`
+}
+
+// StatusReporter is implemented by devices that can report their health.
+// An error means the health could not be determined, not that the device
+// is unhealthy.
+type StatusReporter interface {
+	Status() (Health, error)
+}
+
+// Status reports the Offline and Fault fields
+func (o OfficeMFP) Status() (Health, error) {
+	switch {
+	case o.Offline:
+		return Health{State: StateOffline}, nil
+	case o.Fault != "":
+		return Health{State: StateError, Message: o.Fault}, nil
+	}
+	return Health{State: StateOnline}, nil
+}
+
+// ready fails jobs the way Status says they would
+func (o OfficeMFP) ready() error {
+	switch {
+	case o.Offline:
+		return ErrOffline
+	case o.Fault != "":
+		return fmt.Errorf("%w: %s", ErrDeviceFault, o.Fault)
+	}
+	return nil
+}
+
+// DeviceHealth is the health of one registered device
+type DeviceHealth struct {
+	Name string
+	Health
+}
+
+// HealthReport aggregates the health of every device that reports it
+type HealthReport struct {
+	Time    time.Time
+	Devices []DeviceHealth
+	// Counts has the number of devices in each state
+	Counts map[State]int
+	// QueueDepth is the sum over all devices
+	QueueDepth int
+}
+
+// Healthy reports whether every device is online
+func (r HealthReport) Healthy() bool {
+	return r.Counts[StateOnline] == len(r.Devices)
+}
+
+// Monitor polls the StatusReporters of a registry. Devices that cannot
+// report their status are left out rather than assumed healthy.
+type Monitor struct {
+	Registry *Registry
+	// Interval defaults to DEFAULT_POLL_INTERVAL
+	Interval time.Duration
+	// Clock stamps the reports, it defaults to the real clock
+	Clock clock.Clock
+}
+
+// Poll asks every StatusReporter once
+func (m Monitor) Poll() HealthReport {
+	report := HealthReport{Time: clock.Or(m.Clock).Now(), Counts: make(map[State]int)}
+	for _, name := range m.Registry.Can(RoleStatus) {
+		dev, err := m.Registry.Lookup(name)
+		if err != nil {
+			continue
+		}
+		health, err := dev.(StatusReporter).Status()
+		if err != nil {
+			health = Health{State: StateUnknown, Message: err.Error()}
+		}
+		report.Devices = append(report.Devices, DeviceHealth{name, health})
+		report.Counts[health.State]++
+		report.QueueDepth += health.QueueDepth
+	}
+	return report
+}
+
+// Run polls every Interval and hands each report to onReport until ctx is
+// done
+func (m Monitor) Run(ctx context.Context, onReport func(HealthReport)) error {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DEFAULT_POLL_INTERVAL
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		onReport(m.Poll())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package device_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/clock"
+)
+
+// TestMonitorReportsJam polls a registry whose seeded SimulatedMFP jams on
+// its fifth job, and again once the jam is cleared
+func TestMonitorReportsJam(t *testing.T) {
+	now := clock.NewFrozen(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	flaky := &device.SimulatedMFP{Out: io.Discard, JamRate: 0.3, Seed: 3}
+	registry := &device.Registry{}
+	registry.Register("flaky", flaky)
+	registry.Register("healthy", &device.SimulatedMFP{Out: io.Discard})
+	monitor := device.Monitor{Registry: registry, Clock: now}
+
+	if report := monitor.Poll(); !report.Healthy() {
+		t.Fatalf("Poll before printing = %+v, want it healthy", report)
+	}
+	for i := range 5 {
+		err := flaky.Print(device.Text("memo.txt", "hello"))
+		if jammed := err != nil; jammed != (i == 4) {
+			t.Fatalf("Print %d error = %v, want the jam on the fifth job only", i, err)
+		}
+	}
+
+	report := monitor.Poll()
+	if report.Healthy() {
+		t.Error("Healthy() with a jammed printer = true, want false")
+	}
+	if !report.Time.Equal(now.Now()) {
+		t.Errorf("report time = %v, want %v", report.Time, now.Now())
+	}
+	if report.Counts[device.StateError] != 1 || report.Counts[device.StateOnline] != 1 {
+		t.Errorf("Counts = %v, want one in error and one online", report.Counts)
+	}
+	if got := report.Devices[0]; got.Name != "flaky" || got.State != device.StateError || got.Message != "paper jam" {
+		t.Errorf("Devices[0] = %+v, want flaky in error with a paper jam", got)
+	}
+	codes, err := flaky.ErrorCodes()
+	if err != nil || len(codes) != 1 || codes[0].Code != device.FAULT_CODES["paper jam"] {
+		t.Errorf("ErrorCodes() = %v, %v, want the code of a paper jam", codes, err)
+	}
+
+	flaky.Clear()
+	now.Advance(time.Minute)
+	if report := monitor.Poll(); !report.Healthy() || report.Counts[device.StateOnline] != 2 {
+		t.Errorf("Poll after Clear = %+v, want every device online", report)
+	}
+}
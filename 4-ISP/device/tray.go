@@ -0,0 +1,183 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrUnknownTray is returned for trays a device does not have
+	ErrUnknownTray = errors.New("device: unknown tray")
+	// ErrOutOfPaper is returned when printing from an empty tray
+	ErrOutOfPaper = errors.New("device: out of paper")
+	// ErrNoRoute is returned when no device has the paper a job needs
+	ErrNoRoute = errors.New("device: no device has the requested paper")
+)
+
+// MediaSize is the size of a sheet of paper
+type MediaSize string
+
+const (
+	MediaA4     MediaSize = "A4"
+	MediaA3     MediaSize = "A3"
+	MediaLetter MediaSize = "letter"
+	MediaLegal  MediaSize = "legal"
+)
+
+// Tray is a paper tray and what is loaded in it
+type Tray struct {
+	Name  string
+	Media MediaSize
+	// Sheets is how many sheets are left, Capacity how many fit
+	Sheets   int
+	Capacity int
+}
+
+// TrayManager is implemented by printers with paper trays. Printers with
+// a single fixed paper path have nothing to report and do not implement it.
+type TrayManager interface {
+	Printer
+	Trays() []Tray
+	// PrintFromTray prints doc on paper from the named tray, one sheet per
+	// document
+	PrintFromTray(tray string, doc Document) error
+}
+
+// PaperTrays is the paper loaded in a device. It is shared by every copy
+// of the device value, so sheets used by one job are gone for the next.
+type PaperTrays struct {
+	mu    sync.Mutex
+	trays []Tray
+}
+
+// NewPaperTrays loads trays
+func NewPaperTrays(trays ...Tray) *PaperTrays {
+	return &PaperTrays{trays: slices.Clone(trays)}
+}
+
+// List returns the trays in the order they were loaded. A nil PaperTrays
+// has none.
+func (p *PaperTrays) List() []Tray {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.trays)
+}
+
+// Load fills the named tray with sheets of media, up to its capacity
+func (p *PaperTrays) Load(name string, media MediaSize, sheets int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i, err := p.find(name)
+	if err != nil {
+		return err
+	}
+	if p.trays[i].Capacity > 0 {
+		sheets = min(sheets, p.trays[i].Capacity)
+	}
+	p.trays[i].Media, p.trays[i].Sheets = media, sheets
+	return nil
+}
+
+// take uses up one sheet of the named tray
+func (p *PaperTrays) take(name string) (Tray, error) {
+	if p == nil {
+		return Tray{}, fmt.Errorf("%w: %q", ErrUnknownTray, name)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i, err := p.find(name)
+	if err != nil {
+		return Tray{}, err
+	}
+	if p.trays[i].Sheets <= 0 {
+		return Tray{}, fmt.Errorf("%w: tray %q", ErrOutOfPaper, name)
+	}
+	p.trays[i].Sheets--
+	return p.trays[i], nil
+}
+
+func (p *PaperTrays) find(name string) (int, error) {
+	i := slices.IndexFunc(p.trays, func(t Tray) bool { return t.Name == name })
+	if i < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownTray, name)
+	}
+	return i, nil
+}
+
+// Trays reports the paper loaded in the Paper field
+func (o OfficeMFP) Trays() []Tray {
+	return o.Paper.List()
+}
+
+func (o OfficeMFP) PrintFromTray(tray string, doc Document) error {
+	if err := o.ready(); err != nil {
+		return err
+	}
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	t, err := o.Paper.take(tray)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(output(o.Out), "[tray %s, %s] ", t.Name, t.Media); err != nil {
+		return err
+	}
+	return printDoc(o.Out, doc)
+}
+
+// Route is the device and tray a job was sent to
+type Route struct {
+	Device string
+	Tray   Tray
+}
+
+// Router sends jobs to whichever registered device has the paper they
+// need. Only devices that implement TrayManager are considered, since
+// nothing is known about the paper in the others.
+type Router struct {
+	Registry *Registry
+}
+
+// Route picks the tray with the most sheets of media across all devices.
+// Ties go to the device registered first.
+func (r Router) Route(media MediaSize) (Route, error) {
+	var best Route
+	for _, name := range r.Registry.Names() {
+		dev, err := r.Registry.Lookup(name)
+		if err != nil {
+			continue
+		}
+		trays, ok := dev.(TrayManager)
+		if !ok {
+			continue
+		}
+		for _, t := range trays.Trays() {
+			if t.Media == media && t.Sheets > best.Tray.Sheets {
+				best = Route{Device: name, Tray: t}
+			}
+		}
+	}
+	if best.Device == "" {
+		return Route{}, fmt.Errorf("%w: %s", ErrNoRoute, media)
+	}
+	return best, nil
+}
+
+// Print prints doc on media wherever Route finds it
+func (r Router) Print(doc Document, media MediaSize) (Route, error) {
+	route, err := r.Route(media)
+	if err != nil {
+		return Route{}, err
+	}
+	dev, err := r.Registry.Lookup(route.Device)
+	if err != nil {
+		return Route{}, err
+	}
+	return route, dev.(TrayManager).PrintFromTray(route.Tray.Name, doc)
+}
@@ -0,0 +1,111 @@
+package device
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUpdateFailed is returned when an update did not leave the device
+// running the wanted version
+var ErrUpdateFailed = errors.New("device: firmware update failed")
+
+// UpdateResult is what an update did to one device
+type UpdateResult struct {
+	Name   string
+	Before string
+	After  string
+	// RolledBack is set when the device was put back on Before
+	RolledBack bool
+	Err        error
+}
+
+// Updater installs firmware and checks the device afterwards. A device
+// that fails the check is rolled back to the firmware it had.
+type Updater struct {
+	// Verify checks a device after its update. It defaults to checking
+	// that a StatusReporter is online.
+	Verify func(ctx context.Context, dev Updatable) error
+}
+
+// Update installs image on dev and expects it to run version want after.
+// If installing fails after the version moved, or the device fails the
+// check, it is rolled back. A device already running want is left alone.
+func (u Updater) Update(ctx context.Context, dev Updatable, want string, image io.Reader) UpdateResult {
+	var result UpdateResult
+	before, err := dev.FirmwareVersion()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Before, result.After = before, before
+	if before == want {
+		return result
+	}
+
+	err = dev.ApplyUpdate(ctx, image)
+	after, verr := dev.FirmwareVersion()
+	switch {
+	case verr != nil:
+		err = errors.Join(err, verr)
+	case err == nil && after != want:
+		err = fmt.Errorf("running %s after the update", after)
+	case err == nil:
+		err = u.verify(ctx, dev)
+	}
+	if err == nil {
+		result.After = after
+		return result
+	}
+	result.Err = fmt.Errorf("%w: %s to %s: %w", ErrUpdateFailed, before, want, err)
+	if verr == nil && after == before {
+		return result
+	}
+	if rerr := dev.Rollback(ctx); rerr != nil {
+		result.Err = errors.Join(result.Err, fmt.Errorf("rolling back: %w", rerr))
+		result.After = after
+		return result
+	}
+	result.RolledBack = true
+	return result
+}
+
+// UpdateAll updates every registered Updatable to version want, one
+// device at a time. A failed device does not stop the others.
+func (u Updater) UpdateAll(ctx context.Context, registry *Registry, want string, image []byte) []UpdateResult {
+	var results []UpdateResult
+	for _, name := range registry.Names() {
+		dev, err := registry.Lookup(name)
+		if err != nil {
+			continue
+		}
+		updatable, ok := dev.(Updatable)
+		if !ok {
+			continue
+		}
+		result := u.Update(ctx, updatable, want, bytes.NewReader(image))
+		result.Name = name
+		results = append(results, result)
+	}
+	return results
+}
+
+func (u Updater) verify(ctx context.Context, dev Updatable) error {
+	if u.Verify != nil {
+		return u.Verify(ctx, dev)
+	}
+	reporter, ok := dev.(StatusReporter)
+	if !ok {
+		return nil
+	}
+	health, err := reporter.Status()
+	if err != nil {
+		return err
+	}
+	if health.State != StateOnline {
+		return fmt.Errorf("%s after the update", health.State)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Command devices registers a sample office and prints what each device
+// can do, discovered only through the registry and type assertions.
+// Printers opened by URI can be added with -uri, and printers on the local
+// network found with -discover. With -format markdown or csv it writes the
+// full matrix of devices against role interfaces instead.
+//
+// Run it with: go run ./4-ISP/devices -uri console: -uri file:///tmp/out.txt -discover
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/discovery"
+)
+
+// uris collects repeated -uri flags
+type uris []string
+
+func (u *uris) String() string     { return strings.Join(*u, ",") }
+func (u *uris) Set(s string) error { *u = append(*u, s); return nil }
+
+func main() {
+	var opened uris
+	flag.Var(&opened, "uri", "printer URI to open and register, may be repeated")
+	discover := flag.Bool("discover", false, "register the printers found with multicast DNS")
+	format := flag.String("format", "table", "table, markdown or csv")
+	flag.Parse()
+
+	var registry device.Registry
+	registry.Register("front-desk", device.SimplePrinter{})
+	registry.Register("workroom", device.MultifunctionPrinter{})
+	registry.Register("office", device.OfficeMFP{})
+	registry.Register("fax", device.FaxMachine{})
+	for _, uri := range opened {
+		p, err := device.Open(uri)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := registry.Register(uri, p); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *discover {
+		if _, err := (discovery.Discoverer{Registry: &registry}).Discover(context.Background()); err != nil {
+			log.Print(err)
+		}
+	}
+
+	switch *format {
+	case "table":
+	case "markdown":
+		if err := device.NewMatrix(&registry).Markdown(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	case "csv":
+		if err := device.NewMatrix(&registry).CSV(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	roles := []device.Role{device.RolePrint, device.RoleScan, device.RoleFax, device.RoleCopy, device.RoleStaple, device.RoleStatus}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "DEVICE\tTYPE")
+	for _, role := range roles {
+		fmt.Fprintf(tw, "\t%s", strings.ToUpper(string(role)))
+	}
+	fmt.Fprintln(tw, "\tCOLOR\tDUPLEX\tCOPIES")
+	for _, name := range registry.Names() {
+		dev, _ := registry.Lookup(name)
+		fmt.Fprintf(tw, "%s\t%T", name, dev)
+		has := device.Roles(dev)
+		for _, role := range roles {
+			fmt.Fprintf(tw, "\t%s", mark(slices.Contains(has, role)))
+		}
+		caps := device.PrintCapabilities{MaxCopies: 1}
+		if c, ok := dev.(device.ConfigurablePrinter); ok {
+			caps = c.PrintCapabilities()
+		}
+		if _, ok := dev.(device.Printer); !ok {
+			caps.MaxCopies = 0
+		}
+		fmt.Fprintf(tw, "\t%s\t%s\t%d\n", mark(caps.Color), mark(caps.Duplex), caps.MaxCopies)
+	}
+	tw.Flush()
+}
+
+func mark(ok bool) string {
+	if ok {
+		return "yes"
+	}
+	return "-"
+}
@@ -0,0 +1,183 @@
+// Package devicetest provides recording fakes for the device roles, so code
+// that consumes a Printer or a Scanner can be tested without a device. Each
+// fake records what it was asked to do and fails with Err when it is set.
+// The zero values are ready to use and safe for concurrent use.
+package devicetest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+var (
+	_ device.ContextPrinter = (*FakePrinter)(nil)
+	_ device.Scanner        = (*FakeScanner)(nil)
+	_ device.Faxer          = (*FakeFaxer)(nil)
+	_ device.Copier         = (*FakeCopier)(nil)
+	_ device.Stapler        = (*FakeStapler)(nil)
+)
+
+// FakePrinter captures printed documents. With a Delay it behaves like a
+// slow device, so cancelation can be tested half way through a job.
+type FakePrinter struct {
+	Err error
+	// Delay is how long each job takes
+	Delay time.Duration
+
+	mu      sync.Mutex
+	printed []device.Document
+}
+
+func (p *FakePrinter) Print(doc device.Document) error {
+	return p.PrintContext(context.Background(), doc)
+}
+
+// PrintContext records nothing when ctx is done before Delay passed
+func (p *FakePrinter) PrintContext(ctx context.Context, doc device.Document) error {
+	if p.Delay > 0 {
+		timer := time.NewTimer(p.Delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if p.Err != nil {
+		return p.Err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	doc.Content = bytes.Clone(doc.Content)
+	p.printed = append(p.printed, doc)
+	return nil
+}
+
+// Printed returns the documents printed so far, in order
+func (p *FakePrinter) Printed() []device.Document {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.printed)
+}
+
+// FakeScanner returns canned pages, one per scan, and fails with
+// device.ErrNothingToScan once they run out
+type FakeScanner struct {
+	Pages [][]byte
+	Err   error
+
+	mu    sync.Mutex
+	scans int
+}
+
+func (s *FakeScanner) Scan(dst io.Writer) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	s.mu.Lock()
+	if s.scans >= len(s.Pages) {
+		s.mu.Unlock()
+		return device.ErrNothingToScan
+	}
+	page := s.Pages[s.scans]
+	s.scans++
+	s.mu.Unlock()
+	_, err := dst.Write(page)
+	return err
+}
+
+// Scans is how many pages were scanned
+func (s *FakeScanner) Scans() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scans
+}
+
+// Fax is one fax a FakeFaxer sent
+type Fax struct {
+	Number   string
+	Document device.Document
+}
+
+// FakeFaxer captures sent faxes
+type FakeFaxer struct {
+	Err error
+
+	mu   sync.Mutex
+	sent []Fax
+}
+
+func (f *FakeFaxer) Fax(number string, doc device.Document) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	doc.Content = bytes.Clone(doc.Content)
+	f.sent = append(f.sent, Fax{number, doc})
+	return nil
+}
+
+// Sent returns the faxes sent so far, in order
+func (f *FakeFaxer) Sent() []Fax {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.sent)
+}
+
+// FakeCopier captures how many copies each call asked for
+type FakeCopier struct {
+	Err error
+
+	mu     sync.Mutex
+	copies []int
+}
+
+func (c *FakeCopier) Copy(copies int) error {
+	if c.Err != nil {
+		return c.Err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.copies = append(c.copies, copies)
+	return nil
+}
+
+// Copies returns the copies asked for by each call, in order
+func (c *FakeCopier) Copies() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.copies)
+}
+
+// FakeStapler captures stapled documents
+type FakeStapler struct {
+	Err error
+
+	mu      sync.Mutex
+	stapled []device.Document
+}
+
+func (s *FakeStapler) Staple(doc device.Document) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc.Content = bytes.Clone(doc.Content)
+	s.stapled = append(s.stapled, doc)
+	return nil
+}
+
+// Stapled returns the documents stapled so far, in order
+func (s *FakeStapler) Stapled() []device.Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.stapled)
+}
@@ -0,0 +1,117 @@
+// Package discovery finds printers on the network with DNS service
+// discovery over multicast DNS, the way operating systems find AirPrint
+// and IPP Everywhere printers, and registers them in a device.Registry.
+// Browsing goes through a Transport, so it can be replaced without a
+// network.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+const (
+	IPP_SERVICE  = "_ipp._tcp.local."
+	IPPS_SERVICE = "_ipps._tcp.local."
+)
+
+// SERVICES are what a Discoverer browses for when given none
+var SERVICES = []string{IPP_SERVICE, IPPS_SERVICE}
+
+// Service is one instance of a service found on the network
+type Service struct {
+	// Instance is the name people see, such as "Office MFP"
+	Instance string
+	// Service is the type browsed for, such as IPP_SERVICE
+	Service string
+	Host    string
+	Port    int
+	// Text are the key=value pairs of its TXT record
+	Text map[string]string
+}
+
+// URI is where the printer of an IPP service can be opened. The resource
+// path comes from the "rp" key of the TXT record, as IPP Everywhere
+// printers advertise it.
+func (s Service) URI() string {
+	scheme := "ipp"
+	if s.Service == IPPS_SERVICE {
+		scheme = "ipps"
+	}
+	u := url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(s.Host, strconv.Itoa(s.Port)),
+		Path:   "/" + strings.TrimPrefix(s.Text["rp"], "/"),
+	}
+	return u.String()
+}
+
+// Transport browses the network for instances of a service
+type Transport interface {
+	Browse(ctx context.Context, service string) ([]Service, error)
+}
+
+// TransportFunc lets an ordinary function browse, which is all a test or
+// a demo needs to stand in for the network
+type TransportFunc func(ctx context.Context, service string) ([]Service, error)
+
+func (f TransportFunc) Browse(ctx context.Context, service string) ([]Service, error) {
+	return f(ctx, service)
+}
+
+// Discoverer registers the printers it finds under their instance names
+type Discoverer struct {
+	// Transport defaults to MDNSTransport
+	Transport Transport
+	Registry  *device.Registry
+	// Services default to SERVICES
+	Services []string
+}
+
+// Discover browses once and registers every printer not registered yet.
+// It returns the names it registered. Instances that cannot be opened are
+// reported in the error but do not stop the others.
+func (d Discoverer) Discover(ctx context.Context) ([]string, error) {
+	transport := d.Transport
+	if transport == nil {
+		transport = MDNSTransport{}
+	}
+	services := d.Services
+	if len(services) == 0 {
+		services = SERVICES
+	}
+
+	var found []string
+	var errs []error
+	for _, service := range services {
+		instances, err := transport.Browse(ctx, service)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("browsing %s: %w", service, err))
+			continue
+		}
+		for _, instance := range instances {
+			p, err := device.Open(instance.URI())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", instance.Instance, err))
+				continue
+			}
+			err = d.Registry.Register(instance.Instance, p)
+			if errors.Is(err, device.ErrDuplicateDevice) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			found = append(found, instance.Instance)
+		}
+	}
+	return found, errors.Join(errs...)
+}
@@ -0,0 +1,250 @@
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	MDNS_ADDRESS        = "224.0.0.251:5353"
+	DEFAULT_BROWSE_TIME = 2 * time.Second
+)
+
+// DNS record types used by service discovery
+const (
+	typeA   = 1
+	typePTR = 12
+	typeTXT = 16
+	typeSRV = 33
+)
+
+// errMalformed is returned for DNS messages that cannot be parsed
+var errMalformed = errors.New("discovery: malformed DNS message")
+
+// MDNSTransport browses with a multicast DNS query and collects the
+// answers that arrive within Timeout. It asks as a one-shot querier, so
+// responders answer straight to its port.
+type MDNSTransport struct {
+	// Timeout defaults to DEFAULT_BROWSE_TIME
+	Timeout time.Duration
+}
+
+func (m MDNSTransport) Browse(ctx context.Context, service string) ([]Service, error) {
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = DEFAULT_BROWSE_TIME
+	}
+	group, err := net.ResolveUDPAddr("udp4", MDNS_ADDRESS)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if _, err := conn.WriteToUDP(query(service), group); err != nil {
+		return nil, err
+	}
+	var records []record
+	buf := make([]byte, 9000)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, net.ErrClosed) || isTimeout(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rs, err := parse(buf[:n]); err == nil {
+			records = append(records, rs...)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return services(service, records), nil
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// record is a resource record, with the data of the types browsing needs
+type record struct {
+	name   string
+	rtype  uint16
+	target string // PTR and SRV
+	port   int    // SRV
+	text   map[string]string
+	ip     net.IP
+}
+
+// services puts the records of all answers together into the instances
+// of service
+func services(service string, records []record) []Service {
+	var found []Service
+	seen := make(map[string]bool)
+	for _, ptr := range records {
+		if ptr.rtype != typePTR || !strings.EqualFold(ptr.name, service) || seen[ptr.target] {
+			continue
+		}
+		seen[ptr.target] = true
+		s := Service{Instance: strings.TrimSuffix(ptr.target, "."+service), Service: service}
+		for _, r := range records {
+			if !strings.EqualFold(r.name, ptr.target) {
+				continue
+			}
+			switch r.rtype {
+			case typeSRV:
+				s.Host, s.Port = r.target, r.port
+			case typeTXT:
+				s.Text = r.text
+			}
+		}
+		if s.Host == "" {
+			continue
+		}
+		for _, r := range records {
+			if r.rtype == typeA && strings.EqualFold(r.name, s.Host) {
+				s.Host = r.ip.String()
+				break
+			}
+		}
+		s.Host = strings.TrimSuffix(s.Host, ".")
+		found = append(found, s)
+	}
+	return found
+}
+
+// query builds a DNS query for the PTR records of service
+func query(service string) []byte {
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[4:], 1) // one question
+	msg = appendName(msg, service)
+	return binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(msg, typePTR), 1)
+}
+
+func appendName(msg []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	return append(msg, 0)
+}
+
+// parse reads the answer, authority and additional records of msg
+func parse(msg []byte) ([]record, error) {
+	if len(msg) < 12 {
+		return nil, errMalformed
+	}
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	count := int(binary.BigEndian.Uint16(msg[6:])) + int(binary.BigEndian.Uint16(msg[8:])) + int(binary.BigEndian.Uint16(msg[10:]))
+	off := 12
+	for range questions {
+		_, next, err := readName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = next + 4
+	}
+	var records []record
+	for range count {
+		name, next, err := readName(msg, off)
+		if err != nil || next+10 > len(msg) {
+			return nil, errMalformed
+		}
+		r := record{name: name, rtype: binary.BigEndian.Uint16(msg[next:])}
+		length := int(binary.BigEndian.Uint16(msg[next+8:]))
+		start, end := next+10, next+10+length
+		if end > len(msg) {
+			return nil, errMalformed
+		}
+		data := msg[start:end]
+		switch r.rtype {
+		case typePTR:
+			r.target, _, err = readName(msg, start)
+		case typeSRV:
+			if len(data) < 6 {
+				return nil, errMalformed
+			}
+			r.port = int(binary.BigEndian.Uint16(data[4:]))
+			r.target, _, err = readName(msg, start+6)
+		case typeTXT:
+			r.text = parseText(data)
+		case typeA:
+			if len(data) == 4 {
+				r.ip = net.IP(data)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+		off = end
+	}
+	return records, nil
+}
+
+// readName reads the possibly compressed name at off and returns it with
+// the offset right after it
+func readName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errMalformed
+		}
+		length := int(msg[off])
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = off + 1
+			}
+			return strings.Join(labels, ".") + ".", next, nil
+		case length&0xc0 == 0xc0:
+			if off+1 >= len(msg) || jumps > 16 {
+				return "", 0, errMalformed
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3fff)
+			jumps++
+		default:
+			if off+1+length > len(msg) {
+				return "", 0, errMalformed
+			}
+			labels = append(labels, string(msg[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
+
+// parseText reads the key=value strings of a TXT record
+func parseText(data []byte) map[string]string {
+	text := make(map[string]string)
+	for len(data) > 0 {
+		length := int(data[0])
+		if 1+length > len(data) {
+			break
+		}
+		key, value, _ := strings.Cut(string(data[1:1+length]), "=")
+		text[key] = value
+		data = data[1+length:]
+	}
+	return text
+}
@@ -0,0 +1,113 @@
+// Command generics runs the device roles through type parameters. Role
+// interfaces make good constraints: a function asks for exactly the
+// methods it calls, and a device without them does not compile, just as
+// with interface parameters.
+//
+// What generics add is that the concrete type survives the call. Submit
+// returns the device it was given as its own type, a Fleet holds devices
+// of one type, and a constraint can combine roles for a single call
+// without a named interface for every combination.
+//
+// What they do not add is capability discovery. Inside a generic function
+// a T can still only be asked for optional roles at run time, with a type
+// assertion on any(dev), so segregated optional roles work the same way
+// with or without type parameters.
+//
+// Run it with: go run ./4-ISP/generics
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+// Submit prints doc and hands back the device as the type it came in
+func Submit[T device.Printer](dev T, doc device.Document) (T, error) {
+	return dev, dev.Print(doc)
+}
+
+// CopyOn needs two roles for one call. The constraint combines them in
+// place, where an interface parameter would need a named PrintScanner.
+func CopyOn[T interface {
+	device.Printer
+	device.Scanner
+}](dev T, name string) error {
+	doc, err := device.ScanToMemory(dev, name, device.FormatText)
+	if err != nil {
+		return err
+	}
+	return dev.Print(doc)
+}
+
+// Fleet is a set of devices of one concrete type, so reading one back
+// needs no type assertion
+type Fleet[T device.Printer] struct {
+	devices map[string]T
+}
+
+func (f *Fleet[T]) Add(name string, dev T) {
+	if f.devices == nil {
+		f.devices = make(map[string]T)
+	}
+	f.devices[name] = dev
+}
+
+func (f *Fleet[T]) Get(name string) (T, bool) {
+	dev, ok := f.devices[name]
+	return dev, ok
+}
+
+// PrintAll prints doc on every device of the fleet
+func (f *Fleet[T]) PrintAll(doc device.Document) error {
+	for _, dev := range f.devices {
+		if err := dev.Print(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Describe shows that optional roles are still found at run time: T is
+// only known to print, so staplers have to be asked for
+func Describe[T device.Printer](dev T) string {
+	if _, ok := any(dev).(device.Stapler); ok {
+		return fmt.Sprintf("%T prints and staples", dev)
+	}
+	return fmt.Sprintf("%T prints", dev)
+}
+
+func main() {
+	var out bytes.Buffer
+	memo := device.Text("memo.txt", "Office closed on Friday")
+
+	// The result is a LabelPrinter, not a Printer, so its fields are there
+	labels, err := Submit(device.LabelPrinter{Out: &out, Columns: 40}, memo)
+	fmt.Println("label width:", labels.Columns, "error:", err)
+
+	// CopyOn takes any device that prints and scans. Handing it a
+	// SimplePrinter does not compile:
+	//
+	//	CopyOn(device.SimplePrinter{}, "copy") // does not satisfy ... (missing method Scan)
+	mfp := device.MultifunctionPrinter{Out: &out, Original: memo}
+	fmt.Println("copy:", CopyOn(mfp, "copy of memo.txt"))
+
+	var offices Fleet[device.OfficeMFP]
+	offices.Add("first floor", device.OfficeMFP{Out: &out})
+	offices.Add("second floor", device.OfficeMFP{Out: &out, Offline: true})
+	if office, ok := offices.Get("second floor"); ok {
+		fmt.Println("second floor offline:", office.Offline)
+	}
+	fmt.Println("print all:", offices.PrintAll(memo))
+
+	fmt.Println(Describe(device.SimplePrinter{}))
+	fmt.Println(Describe(device.OfficeMFP{}))
+
+	// The registry's All is the same idea across many types: it returns
+	// the devices that play a role as that role
+	var registry device.Registry
+	registry.Register("workroom", mfp)
+	registry.Register("office", device.OfficeMFP{})
+	fmt.Println("staplers:", len(device.All[device.Stapler](&registry)))
+}
@@ -0,0 +1,85 @@
+// Package ioroles is a walkthrough of the standard library's own interface
+// segregation. Package io defines one-method roles and composes them only
+// where a caller really needs more than one:
+//
+//	io.Reader           Read(p []byte) (n int, err error)
+//	io.Writer           Write(p []byte) (n int, err error)
+//	io.Closer           Close() error
+//	io.ReadWriteCloser  all three, for the few callers that use all three
+//
+// Each utility here accepts the narrowest of them that it can work with.
+// CountLines reads and never closes, so it takes an io.Reader and works
+// with a strings.Reader, a file, a gzip stream or an HTTP body alike. Had
+// it asked for an io.ReadCloser, a strings.Reader would need a wrapper
+// just to satisfy a Close the function never calls.
+package ioroles
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CountLines counts the lines r yields. It only reads.
+func CountLines(r io.Reader) (int, error) {
+	lines := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines, scanner.Err()
+}
+
+// Checksum returns the SHA-256 of everything r yields. It only reads.
+func Checksum(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteLines writes each line followed by a newline. It only writes, so
+// the caller keeps deciding when w is flushed or closed.
+func WriteLines(w io.Writer, lines ...string) error {
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloseAll closes every closer and reports all the failures. It never
+// reads or writes, so it takes files, connections and response bodies in
+// one call.
+func CloseAll(closers ...io.Closer) error {
+	var errs []error
+	for _, c := range closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Deliver copies src to dst and closes dst, since it owns dst once it is
+// handed over. It needs dst to write and close, and src only to read.
+func Deliver(dst io.WriteCloser, src io.Reader) error {
+	_, err := io.Copy(dst, src)
+	return errors.Join(err, dst.Close())
+}
+
+// Echo answers every line read from conn with the same line until conn
+// ends, then closes it. It really uses all three roles of one value,
+// which is what io.ReadWriteCloser is for.
+func Echo(conn io.ReadWriteCloser) error {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if _, err := fmt.Fprintln(conn, scanner.Text()); err != nil {
+			return errors.Join(err, conn.Close())
+		}
+	}
+	return errors.Join(scanner.Err(), conn.Close())
+}
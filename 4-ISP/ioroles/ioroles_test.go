@@ -0,0 +1,145 @@
+package ioroles_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/imrancluster/go-solid/4-ISP/ioroles"
+)
+
+const TEXT = "first line\nsecond line\nthird line\n"
+
+// closer records whether it was closed and fails with Err
+type closer struct {
+	Err    error
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return c.Err
+}
+
+// buffer is a bytes.Buffer that can be closed
+type buffer struct {
+	bytes.Buffer
+	closer
+}
+
+func TestCountLines(t *testing.T) {
+	for name, r := range map[string]io.Reader{
+		"strings.Reader":  strings.NewReader(TEXT),
+		"one byte a time": iotest.OneByteReader(strings.NewReader(TEXT)),
+		"no last newline": strings.NewReader(strings.TrimSuffix(TEXT, "\n")),
+	} {
+		if lines, err := ioroles.CountLines(r); lines != 3 || err != nil {
+			t.Errorf("CountLines(%s) = %d, %v, want 3, nil", name, lines, err)
+		}
+	}
+	broken := errors.New("broken")
+	if _, err := ioroles.CountLines(iotest.ErrReader(broken)); !errors.Is(err, broken) {
+		t.Errorf("CountLines of a failing reader error = %v, want %v", err, broken)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte(TEXT))
+	got, err := ioroles.Checksum(iotest.HalfReader(strings.NewReader(TEXT)))
+	if err != nil || got != hex.EncodeToString(sum[:]) {
+		t.Errorf("Checksum = %s, %v, want %x", got, err, sum)
+	}
+}
+
+func TestWriteLines(t *testing.T) {
+	var b bytes.Buffer
+	if err := ioroles.WriteLines(&b, "first line", "second line", "third line"); err != nil {
+		t.Fatalf("WriteLines returned error: %v", err)
+	}
+	if b.String() != TEXT {
+		t.Errorf("WriteLines wrote %q, want %q", b.String(), TEXT)
+	}
+	full := errors.New("disk full")
+	if err := ioroles.WriteLines(failingWriter{full}, "line"); !errors.Is(err, full) {
+		t.Errorf("WriteLines to a failing writer error = %v, want %v", err, full)
+	}
+}
+
+// TestCloseAll makes sure every closer is closed even after one failed
+func TestCloseAll(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+	closers := []*closer{{Err: first}, {}, {Err: second}}
+	err := ioroles.CloseAll(closers[0], closers[1], closers[2])
+	if !errors.Is(err, first) || !errors.Is(err, second) {
+		t.Errorf("CloseAll error = %v, want both failures", err)
+	}
+	for i, c := range closers {
+		if !c.closed {
+			t.Errorf("closer %d was not closed", i)
+		}
+	}
+	if err := ioroles.CloseAll(); err != nil {
+		t.Errorf("CloseAll() = %v, want nil", err)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	dst := &buffer{}
+	if err := ioroles.Deliver(dst, strings.NewReader(TEXT)); err != nil {
+		t.Fatalf("Deliver returned error: %v", err)
+	}
+	if dst.String() != TEXT || !dst.closed {
+		t.Errorf("Deliver left %q, closed %v, want %q and closed", dst.String(), dst.closed, TEXT)
+	}
+
+	// dst is closed even when reading src fails
+	broken := errors.New("broken")
+	dst = &buffer{}
+	if err := ioroles.Deliver(dst, iotest.ErrReader(broken)); !errors.Is(err, broken) {
+		t.Errorf("Deliver from a failing reader error = %v, want %v", err, broken)
+	}
+	if !dst.closed {
+		t.Error("Deliver from a failing reader left dst open")
+	}
+}
+
+func TestEcho(t *testing.T) {
+	client, server := net.Pipe()
+	echoed := make(chan error, 1)
+	go func() { echoed <- ioroles.Echo(server) }()
+
+	for _, line := range []string{"ping", "pong"} {
+		if _, err := io.WriteString(client, line+"\n"); err != nil {
+			t.Fatalf("writing %q returned error: %v", line, err)
+		}
+		reply := make([]byte, len(line)+1)
+		if _, err := io.ReadFull(client, reply); err != nil {
+			t.Fatalf("reading the reply to %q returned error: %v", line, err)
+		}
+		if string(reply) != line+"\n" {
+			t.Errorf("Echo answered %q with %q", line, reply)
+		}
+	}
+	client.Close()
+	if err := <-echoed; err != nil {
+		t.Errorf("Echo returned error: %v", err)
+	}
+	// Echo closed its end
+	if _, err := server.Write([]byte("x")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("writing after Echo returned error = %v, want %v", err, io.ErrClosedPipe)
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
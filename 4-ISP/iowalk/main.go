@@ -0,0 +1,78 @@
+// Command iowalk runs the utilities of package ioroles against the
+// standard library's readers, writers and closers, to show why each one
+// asks for the narrowest io interface it can.
+//
+// Run it with: go run ./4-ISP/iowalk
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/imrancluster/go-solid/4-ISP/ioroles"
+)
+
+func main() {
+	text := "first line\nsecond line\nthird line\n"
+
+	// io.Reader: anything that yields bytes will do
+	var zipped bytes.Buffer
+	gz := gzip.NewWriter(&zipped)
+	io.WriteString(gz, text)
+	gz.Close()
+	unzipped, _ := gzip.NewReader(&zipped)
+	recorder := httptest.NewRecorder()
+	io.WriteString(recorder, text)
+	for _, r := range []io.Reader{
+		strings.NewReader(text),
+		bytes.NewBufferString(text),
+		unzipped,
+		recorder.Result().Body,
+		io.MultiReader(strings.NewReader("first line\n"), strings.NewReader("second line\nthird line\n")),
+	} {
+		lines, err := ioroles.CountLines(r)
+		fmt.Printf("%-22T %d lines, %v\n", r, lines, err)
+	}
+	sum, _ := ioroles.Checksum(io.LimitReader(strings.NewReader(text), 10))
+	fmt.Println("checksum of the first 10 bytes:", sum[:16])
+
+	// io.Writer: the caller keeps the buffering and the closing
+	var buf bytes.Buffer
+	ioroles.WriteLines(io.MultiWriter(&buf, os.Stdout), "to stdout and", "a buffer at once")
+	fmt.Printf("buffer holds %d bytes\n", buf.Len())
+
+	// io.Closer: files and readers with nothing in common but Close
+	path := filepath.Join(os.TempDir(), "iowalk.txt")
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("close all:", ioroles.CloseAll(file, io.NopCloser(strings.NewReader(text)), recorder.Result().Body))
+	fmt.Println("closing twice:", ioroles.CloseAll(file))
+
+	// io.WriteCloser: Deliver owns dst and closes it
+	file, _ = os.Create(path)
+	fmt.Println("deliver:", ioroles.Deliver(file, strings.NewReader(text)))
+
+	// io.ReadWriteCloser: only Echo uses all three roles of one value
+	client, server := net.Pipe()
+	go ioroles.Echo(server)
+	io.WriteString(client, "ping\n")
+	reply := make([]byte, 5)
+	io.ReadFull(client, reply)
+	fmt.Printf("echo: %q\n", reply)
+	client.Close()
+
+	// A strings.Reader cannot be passed to Echo, nor to a CountLines that
+	// asked for more than it uses:
+	//
+	//	ioroles.Echo(strings.NewReader(text)) // missing method Close
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestWalk runs the walkthrough and checks what it reports for each role
+func TestWalk(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	out := capture(t, main)
+	for _, want := range []string{
+		"*strings.Reader        3 lines, <nil>",
+		"*gzip.Reader           3 lines, <nil>",
+		"*io.multiReader        3 lines, <nil>",
+		"buffer holds 31 bytes",
+		"close all: <nil>",
+		"file already closed",
+		"deliver: <nil>",
+		`echo: "ping\n"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+// capture returns what f writes to os.Stdout
+func capture(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	f()
+	w.Close()
+	return <-out
+}
@@ -0,0 +1,71 @@
+// Package mail is the small mail abstraction the office workflows share.
+// Receiving and sending are separate roles, so a workflow that only reads
+// mail never depends on how mail is sent.
+package mail
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Attachment is a file sent along with a message
+type Attachment struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+// Message is one email
+type Message struct {
+	From        string
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// MailSource hands out messages that have not been fetched before
+type MailSource interface {
+	Fetch(ctx context.Context) ([]Message, error)
+}
+
+// Mailer sends messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Mailbox keeps messages in memory. Deliver puts mail in it, Fetch takes
+// the unread messages out. The zero value is ready to use.
+type Mailbox struct {
+	mu     sync.Mutex
+	unread []Message
+}
+
+// Send delivers msg to the mailbox, so a Mailbox can stand in for a mail
+// server on both ends
+func (m *Mailbox) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.Deliver(msg)
+	return nil
+}
+
+// Deliver adds messages to the mailbox
+func (m *Mailbox) Deliver(messages ...Message) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unread = append(m.unread, messages...)
+}
+
+func (m *Mailbox) Fetch(ctx context.Context) ([]Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	messages := slices.Clip(m.unread)
+	m.unread = nil
+	return messages, nil
+}
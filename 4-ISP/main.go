@@ -1,39 +1,433 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
-// Split the functionalities into smaller interfaces
-type Printer interface {
-	Print()
-}
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/4-ISP/accounting"
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/discovery"
+	"github.com/imrancluster/go-solid/4-ISP/mail"
+	"github.com/imrancluster/go-solid/4-ISP/notify"
+	"github.com/imrancluster/go-solid/4-ISP/pool"
+	"github.com/imrancluster/go-solid/4-ISP/spool"
+	"github.com/imrancluster/go-solid/4-ISP/workflow"
+)
 
-type Scanner interface {
-	Scan()
+// Each workflow asks only for the role it needs
+func printAll(doc device.Document, printers ...device.Printer) {
+	for _, p := range printers {
+		if err := p.Print(doc); err != nil {
+			fmt.Println("Printing failed:", err)
+		}
+	}
 }
 
-// A normal printer implements only the Printer interface
-type SimplePrinter struct{}
+func faxAll(number string, doc device.Document, faxers ...device.Faxer) {
+	for _, f := range faxers {
+		if err := f.Fax(number, doc); err != nil {
+			fmt.Println("Faxing failed:", err)
+		}
+	}
+}
 
-func (p SimplePrinter) Print() {
-	fmt.Println("Printing document")
+// sendAndConfirm scans the original, faxes it and prints a confirmation,
+// so it needs a device that does all three. A FaxMachine cannot scan and
+// does not compile as an argument.
+func sendAndConfirm(mfd device.MultiFunctionDevice, number string) error {
+	doc, err := device.ScanToMemory(mfd, "outgoing fax", device.FormatText)
+	if err != nil {
+		return err
+	}
+	if err := mfd.Fax(number, doc); err != nil {
+		return err
+	}
+	return mfd.Print(device.Text("confirmation.txt", "Fax sent to "+number))
 }
 
-// A multifunction printer implements both Printer and Scanner
-type MultifunctionPrinter struct{}
+func main() {
+	memo := device.Text("memo.txt", "Office closed on Friday")
+	contract := device.Text("contract.txt", "Signed by both parties")
 
-func (m MultifunctionPrinter) Print() {
-	fmt.Println("Printing document")
-}
+	printer := device.SimplePrinter{}
+	printer.Print(memo)
 
-func (m MultifunctionPrinter) Scan() {
-	fmt.Println("Scanning document")
-}
+	mfp := device.MultifunctionPrinter{Original: contract}
+	scanned, err := device.ScanToMemory(mfp, "scan of contract.txt", device.FormatText)
+	if err != nil {
+		fmt.Println("Scanning failed:", err)
+		return
+	}
+	mfp.Print(scanned)
 
-func main() {
-	printer := SimplePrinter{}
-	printer.Print()
+	office := device.OfficeMFP{Original: contract, Paper: device.NewPaperTrays(
+		device.Tray{Name: "1", Media: device.MediaA4, Sheets: 250, Capacity: 250},
+		device.Tray{Name: "2", Media: device.MediaLetter, Sheets: 1, Capacity: 250},
+	), Supplies: []device.Supply{
+		{Name: "black toner", Kind: device.SupplyToner, Level: 0.08},
+		{Name: "drum unit", Kind: device.SupplyDrum, Level: 0.6},
+	}}
+	fax := device.FaxMachine{}
+	printAll(memo, printer, mfp, office, fax)
+	faxAll("+1-555-0100", scanned, office, fax)
+	if err := sendAndConfirm(office, "+1-555-0199"); err != nil {
+		fmt.Println("Sending fax failed:", err)
+	}
+	office.Copy(2)
+	office.Staple(memo)
+
+	// A label printer is just a Printer, it only refuses what does not fit
+	labels := device.LabelPrinter{}
+	printAll(device.Text("address.txt", "Jane Doe\n12 Main Street\nSpringfield"), labels)
+	printAll(device.Text("terms.txt", "These terms apply to every order placed in the shop"), labels)
+
+	// Documents in a format the device does not print are converted first
+	shelf := device.Document{Name: "shelf.md", Content: []byte("**Aisle 4**\nPaper and toner"), Format: device.FormatMarkdown}
+	printAll(shelf, device.ConvertingPrinter{Printer: labels})
+	if ps, err := device.Convert(shelf, device.FormatPostScript); err == nil {
+		fmt.Printf("Converted %s to %d pages of %s\n", shelf.Name, ps.Pages(), ps.Format)
+	}
+
+	// Receipts from the payment module print on any Printer, the till's
+	// receipt printer included
+	till := workflow.ReceiptPrinting{Printer: device.ReceiptPrinter{}}
+	card := &payment.CardPayment{}
+	invoice := workflow.Invoice{ID: 7, Amount: 120}
+	for _, amount := range []float64{100, 20} {
+		result, err := card.ProcessPayment(context.Background(), amount, payment.USD, "")
+		if err != nil {
+			fmt.Println("Payment failed:", err)
+			break
+		}
+		invoice.Payments = append(invoice.Payments, result)
+	}
+	for _, result := range invoice.Payments[:min(1, len(invoice.Payments))] {
+		if err := till.PrintReceipt(result); err != nil {
+			fmt.Println("Printing receipt failed:", err)
+		}
+	}
+	if err := till.PrintInvoice(invoice); err != nil {
+		fmt.Println("Printing invoice failed:", err)
+	}
+
+	// Devices report what they cannot do instead of printing nonsense
+	printAll(device.Document{Name: "blank.txt", Format: device.FormatText}, printer)
+	if err := (device.MultifunctionPrinter{}).Scan(io.Discard); err != nil {
+		fmt.Println("Scanning failed:", err)
+	}
+
+	// The spooler only needs a Printer to drain its queue, subscribers
+	// follow its jobs without polling
+	var queue spool.Queue
+	queue.Subscribe(spool.EventLogger{Out: os.Stdout})
+	queue.Enqueue(device.Text("newsletter.txt", "Monthly newsletter"), spool.PriorityLow)
+	urgent, _ := queue.Enqueue(device.Text("invoice.txt", "Invoice due today"), spool.PriorityHigh)
+	queue.Enqueue(memo, spool.PriorityNormal)
+	spool.Worker{Queue: &queue, Printer: office}.Drain(context.Background())
+	if job, err := queue.Job(urgent); err == nil {
+		fmt.Println(job.Document.Name, "is", job.Status)
+	}
+
+	// Jobs can be canceled while queued or half way through a slow device
+	slow := &device.SimulatedMFP{PrintLatency: time.Minute}
+	var slowQueue spool.Queue
+	poster, _ := slowQueue.Enqueue(device.Text("poster.txt", "Big poster"), spool.PriorityNormal)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		slowQueue.Cancel(poster)
+	}()
+	spool.Worker{Queue: &slowQueue, Printer: slow}.Drain(context.Background())
+	if job, err := slowQueue.Job(poster); err == nil {
+		fmt.Println(job.Document.Name, "is", job.Status)
+	}
+
+	// A queue with a store picks up where a stopped one left off
+	store := &spool.FileStore{Path: filepath.Join(os.TempDir(), "spool-jobs.json")}
+	os.Remove(store.Path)
+	stopped := spool.Queue{Store: store}
+	stopped.Enqueue(device.Text("minutes.txt", "Meeting minutes"), spool.PriorityNormal)
+	restarted := spool.Queue{Store: store}
+	if n, err := restarted.Recover(); err == nil {
+		fmt.Println("Recovered", n, "queued jobs")
+		spool.Worker{Queue: &restarted, Printer: printer}.Drain(context.Background())
+	}
+
+	// Workers print several jobs at once, a bounded queue holds the
+	// producer back until they catch up
+	bounded := &spool.Queue{Capacity: 2}
+	busy := &device.SimulatedMFP{Out: io.Discard, PrintLatency: 5 * time.Millisecond}
+	running, stop := context.WithCancel(context.Background())
+	finished := make(chan error, 1)
+	go func() {
+		finished <- spool.Workers{Queue: bounded, Printer: busy, Concurrency: 3}.Run(running)
+	}()
+	for i := range 6 {
+		bounded.EnqueueWait(context.Background(), device.Text(fmt.Sprintf("report-%d.txt", i+1), "Weekly report"), spool.PriorityNormal)
+	}
+	stop()
+	<-finished
+	spool.Workers{Queue: bounded, Printer: busy}.Drain(context.Background())
+	fmt.Println("Printed 6 reports through a queue of", bounded.Capacity)
+
+	// Ask a printer what it supports before submitting with options
+	brochure := device.PrintOptions{Duplex: true, Color: true, Copies: 2}
+	for _, p := range []device.Printer{printer, mfp, office} {
+		if c, ok := p.(device.ConfigurablePrinter); ok {
+			fmt.Printf("%T supports %+v\n", p, c.PrintCapabilities())
+		}
+		if err := device.PrintWith(p, memo, brochure); err != nil {
+			fmt.Println("Printing brochure failed:", err)
+		}
+	}
+
+	// A preview shows the layout for a device's options without printing
+	if preview, err := device.NewPreview(office, memo, device.PrintOptions{Duplex: true, Copies: 3}, device.MediaA4); err == nil {
+		preview.Text(os.Stdout)
+	}
+
+	// A pool spreads jobs over its printers, color jobs only go to the
+	// printers that can do color
+	printers := &pool.Pool{
+		Members: []pool.Member{
+			{Name: "front-desk", Printer: printer},
+			{Name: "workroom", Printer: mfp},
+			{Name: "office", Printer: office},
+		},
+		Strategy: pool.CapabilityAware{Next: &pool.RoundRobin{}},
+	}
+	for i := range 4 {
+		printers.Print(device.Text(fmt.Sprintf("handout-%d.txt", i+1), "Welcome"))
+	}
+	printers.PrintWith(device.Text("chart.txt", "Sales by region"), device.PrintOptions{Color: true})
+	for _, u := range printers.Metrics() {
+		fmt.Printf("%s printed %d jobs (%.0f%%)\n", u.Name, u.Jobs, u.Share*100)
+	}
+
+	// Pages are charged to whoever printed them, up to their quota
+	var ledger accounting.Ledger
+	ledger.SetQuota("bob", 2)
+	meter := accounting.Meter{Printer: device.SimplePrinter{Out: io.Discard}, Accounting: &ledger}
+	long := device.Text("thesis.txt", strings.Repeat("A line of the thesis\n", 150))
+	for _, job := range []struct {
+		user string
+		doc  device.Document
+	}{{"alice", long}, {"bob", memo}, {"bob", long}, {"alice", memo}} {
+		if err := meter.PrintAs(job.user, job.doc); err != nil {
+			fmt.Println("Printing failed:", err)
+		}
+	}
+	if usage, err := ledger.Usage(); err == nil {
+		accounting.Report(os.Stdout, usage)
+	}
+
+	// Mail in, paper out: the bridge needs a MailSource and a Printer only
+	var inbox mail.Mailbox
+	inbox.Deliver(mail.Message{
+		From:    "alice@example.com",
+		Subject: "Please print",
+		Attachments: []mail.Attachment{
+			{Name: "agenda.txt", ContentType: "text/plain", Content: []byte("1. Budget 2. Hiring")},
+			{Name: "song.mp3", ContentType: "audio/mpeg", Content: []byte{0x49, 0x44, 0x33}},
+		},
+	})
+	if _, err := (workflow.EmailToPrint{Source: &inbox, Printer: office}).Run(context.Background()); err != nil {
+		fmt.Println("Email to print:", err)
+	}
+
+	// Paper in, mail out: scan on any Scanner, send with any Mailer. The
+	// outbox is a mailbox too, so the mail can be read back.
+	var outbox mail.Mailbox
+	scanToEmail := workflow.ScanToEmail{Scanner: mfp, Mailer: &outbox, From: "scanner@example.com"}
+	if err := scanToEmail.Send(context.Background(), "contract.txt", device.FormatText, "bob@example.com"); err != nil {
+		fmt.Println("Scan to email failed:", err)
+	}
+	sent, _ := outbox.Fetch(context.Background())
+	for _, msg := range sent {
+		fmt.Printf("Mail to %v: %s\n", msg.To, msg.Subject)
+	}
+
+	// Scan settings are validated per device before anything is scanned
+	photo := device.ScanOptions{Resolution: 1200, ColorMode: device.ColorModeColor}
+	for _, s := range []device.Scanner{mfp, office} {
+		if err := device.ScanWith(s, io.Discard, photo); err != nil {
+			fmt.Printf("%T cannot scan %+v: %v\n", s, photo, err)
+		}
+	}
+
+	// OCR is a role of its own layered on any scanner; tr stands in for an
+	// external engine such as tesseract
+	for _, ocr := range []device.OCR{device.NaiveOCR{}, device.CommandOCR{Command: "tr", Args: []string{"a-z", "A-Z"}}} {
+		text, err := device.ScanText(context.Background(), office, ocr)
+		if err != nil {
+			fmt.Println("OCR failed:", err)
+			continue
+		}
+		fmt.Printf("%T read %q\n", ocr, text)
+	}
+
+	// A stack of originals in the feeder is split into pages, each read
+	// with OCR and mailed to whoever it is for
+	feeding := office
+	feeding.Feeder = device.NewDocumentFeeder(
+		device.Text("invoice-17.txt", "INVOICE 17 for toner"),
+		device.Text("letter.txt", "Dear front desk, thanks"),
+	)
+	splitter := workflow.ScanSplitter{
+		Scanner: feeding,
+		OCR:     device.NaiveOCR{},
+		Mailer:  &outbox,
+		From:    "scanner@example.com",
+		To:      []string{"frontdesk@example.com"},
+		Route: func(text string) []string {
+			if strings.Contains(text, "INVOICE") {
+				return []string{"accounts@example.com"}
+			}
+			return nil
+		},
+	}
+	if pages, err := splitter.Run(context.Background()); err == nil {
+		for _, page := range pages {
+			fmt.Printf("Mailed %s to %v\n", page.Document.Name, page.To)
+		}
+	}
+
+	// Printers are opened by URI, the driver picks the implementation
+	fmt.Println("Drivers:", device.Schemes())
+	for _, uri := range []string{"console:", "file://" + filepath.Join(os.TempDir(), "printed.txt"), "lpd://printer"} {
+		p, err := device.Open(uri)
+		if err != nil {
+			fmt.Println("Opening", uri, "failed:", err)
+			continue
+		}
+		p.Print(memo)
+	}
+
+	// The virtual PDF printer leaves a file that can be opened
+	pdf := &device.PDFPrinter{Dir: os.TempDir()}
+	if path, err := pdf.Render(memo); err == nil {
+		fmt.Println("Printed to", path)
+	}
+
+	// A whole protocol hides behind Print: the network printer talks HTTP to
+	// a print server that hands jobs to the office machine
+	server := httptest.NewServer(device.PrintServer{Printer: office, Firmware: device.NewFirmware("1.0.0")})
+	network := &device.NetworkPrinter{URL: server.URL}
+	printAll(device.Text("report.txt", "Quarterly report"), network)
+	printAll(device.Document{Name: "photo.raw", Content: []byte{0xff}, Format: "image/raw"}, network)
+
+	// Register devices once, then bind workflows to roles
+	var registry device.Registry
+	registry.Register("front-desk", printer)
+	registry.Register("workroom", mfp)
+	registry.Register("office", office)
+	registry.Register("fax", fax)
+
+	// Network printers register themselves when they are discovered. The
+	// transport stands in for multicast DNS and finds the print server.
+	addr := server.Listener.Addr().(*net.TCPAddr)
+	discoverer := discovery.Discoverer{Registry: &registry, Transport: discovery.TransportFunc(
+		func(ctx context.Context, service string) ([]discovery.Service, error) {
+			if service != discovery.IPP_SERVICE {
+				return nil, nil
+			}
+			return []discovery.Service{{Instance: "print-room", Service: service, Host: addr.IP.String(), Port: addr.Port}}, nil
+		},
+	)}
+	if found, err := discoverer.Discover(context.Background()); err == nil {
+		fmt.Println("Discovered:", found)
+	}
+	for _, name := range registry.Names() {
+		dev, _ := registry.Lookup(name)
+		fmt.Printf("%s can %v\n", name, device.Roles(dev))
+	}
+	fmt.Println("Scanners:", registry.Can(device.RoleScan))
+
+	// Jobs are routed to whichever tray holds the paper they need
+	router := device.Router{Registry: &registry}
+	for _, media := range []device.MediaSize{device.MediaLetter, device.MediaLetter, device.MediaA3} {
+		route, err := router.Print(device.Text("poster.txt", "Team offsite"), media)
+		if err != nil {
+			fmt.Printf("Printing on %s failed: %v\n", media, err)
+			continue
+		}
+		fmt.Printf("Printed on %s from %s tray %s\n", media, route.Device, route.Tray.Name)
+	}
+
+	// Low supplies are reported through whatever notifier is plugged in,
+	// and only once until they are refilled
+	alerter := device.SupplyAlerter{Registry: &registry, Notifier: notify.WriterNotifier{Out: os.Stdout}}
+	for range 2 {
+		low, _ := alerter.Check(context.Background())
+		fmt.Println("Low supplies:", len(low))
+	}
+
+	// A simulated device fails the same way every run, so the monitor and
+	// the diagnostics below have a jam to report
+	flaky := &device.SimulatedMFP{Out: io.Discard, JamRate: 0.5, Seed: 1}
+	for i := range 3 {
+		if err := flaky.Print(device.Text(fmt.Sprintf("page-%d.txt", i+1), "Draft")); err != nil {
+			fmt.Println("Simulated device:", err)
+			break
+		}
+	}
+	registry.Register("simulated", flaky)
+
+	// The monitor polls every device that reports its health
+	jammed := device.OfficeMFP{Fault: "paper jam"}
+	spooled := spool.SpooledPrinter{Queue: &spool.Queue{}, Device: jammed}
+	spooled.Print(memo)
+	spooled.Print(contract)
+	registry.Register("network", network)
+	registry.Register("basement", spooled)
+	health := device.Monitor{Registry: &registry}.Poll()
+	for _, d := range health.Devices {
+		state := string(d.State)
+		if d.Message != "" {
+			state += ": " + d.Message
+		}
+		fmt.Printf("%s is %s, %d queued\n", d.Name, state, d.QueueDepth)
+	}
+	fmt.Println("All healthy:", health.Healthy(), "- jobs waiting:", health.QueueDepth)
+
+	// Diagnostics exercise only the devices that can maintain themselves
+	registry.Register("jammed", jammed)
+	diagnostics := device.Diagnostics{Registry: &registry}.Run(context.Background())
+	diagnostics.Render(os.Stdout)
 
-	mfp := MultifunctionPrinter{}
-	mfp.Print()
-	mfp.Scan()
+	// Firmware goes to every device that takes it, and a device that does
+	// not come back on the wanted version is rolled back
+	var updater device.Updater
+	for _, update := range []struct{ want, image string }{
+		{"1.1.0", "firmware 1.1.0\nfixes"},
+		{"1.2.0", "firmware 1.2.0-beta\nexperiments"},
+	} {
+		for _, r := range updater.UpdateAll(context.Background(), &registry, update.want, []byte(update.image)) {
+			switch {
+			case r.RolledBack:
+				fmt.Printf("%s rolled back to %s: %v\n", r.Name, r.After, r.Err)
+			case r.Err != nil:
+				fmt.Printf("%s not updated: %v\n", r.Name, r.Err)
+			case r.Before == r.After:
+				fmt.Printf("%s already runs %s\n", r.Name, r.After)
+			default:
+				fmt.Printf("%s updated from %s to %s\n", r.Name, r.Before, r.After)
+			}
+		}
+	}
+	server.Close()
+	for i, s := range device.All[device.Scanner](&registry) {
+		// Large scans stream straight to disk
+		path := filepath.Join(os.TempDir(), fmt.Sprintf("scan-%d.txt", i+1))
+		if err := device.ScanToFile(s, path); err == nil {
+			fmt.Println("Scanned to", path)
+		}
+	}
 }
@@ -0,0 +1,88 @@
+// Package notify is how office components tell people that something
+// needs attention. Components depend on Notifier alone, so an alert can go
+// to mail, a log or anywhere else without the component knowing.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/imrancluster/go-solid/4-ISP/mail"
+)
+
+// Severity is how urgent a notification is
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Notification is one thing somebody should know about
+type Notification struct {
+	Severity Severity
+	Subject  string
+	Body     string
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifierFunc lets an ordinary function deliver notifications
+type NotifierFunc func(ctx context.Context, n Notification) error
+
+func (f NotifierFunc) Notify(ctx context.Context, n Notification) error {
+	return f(ctx, n)
+}
+
+// WriterNotifier writes one line per notification
+type WriterNotifier struct {
+	// Out defaults to os.Stderr
+	Out io.Writer
+}
+
+func (w WriterNotifier) Notify(ctx context.Context, n Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	out := w.Out
+	if out == nil {
+		out = os.Stderr
+	}
+	_, err := fmt.Fprintf(out, "[%s] %s: %s\n", n.Severity, n.Subject, n.Body)
+	return err
+}
+
+// MailNotifier mails notifications to To
+type MailNotifier struct {
+	Mailer mail.Mailer
+	From   string
+	To     []string
+}
+
+func (m MailNotifier) Notify(ctx context.Context, n Notification) error {
+	return m.Mailer.Send(ctx, mail.Message{
+		From:    m.From,
+		To:      m.To,
+		Subject: fmt.Sprintf("[%s] %s", n.Severity, n.Subject),
+		Body:    n.Body,
+	})
+}
+
+// Multi delivers every notification to each of its notifiers, even when
+// some of them fail
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range m {
+		errs = append(errs, notifier.Notify(ctx, n))
+	}
+	return errors.Join(errs...)
+}
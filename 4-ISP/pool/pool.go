@@ -0,0 +1,153 @@
+// Package pool spreads print jobs over several printers. Which printer
+// gets a job is up to a Strategy, and the pool keeps utilization figures
+// for every member. A Pool is a Printer itself, so whatever prints to one
+// printer can print to a pool.
+package pool
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+// ErrNoPrinter is returned when no member of the pool can take a job
+var ErrNoPrinter = errors.New("pool: no printer can take the job")
+
+// Member is a printer of the pool
+type Member struct {
+	Name    string
+	Printer device.Printer
+}
+
+// Members returns every Printer of registry, in the order they were
+// registered
+func Members(registry *device.Registry) []Member {
+	var members []Member
+	for _, name := range registry.Names() {
+		dev, err := registry.Lookup(name)
+		if err != nil {
+			continue
+		}
+		if p, ok := dev.(device.Printer); ok {
+			members = append(members, Member{name, p})
+		}
+	}
+	return members
+}
+
+// Utilization is how much a member of the pool was used
+type Utilization struct {
+	Name   string
+	Jobs   int
+	Failed int
+	// InFlight is the number of jobs printing right now
+	InFlight int
+	// Busy is the time spent printing
+	Busy time.Duration
+	// Share is this member's part of all jobs, from 0 to 1
+	Share float64
+}
+
+// Pool prints each job on one of Members. Set Members and Strategy before
+// the first job and leave them alone after.
+type Pool struct {
+	Members []Member
+	// Strategy defaults to RoundRobin
+	Strategy Strategy
+
+	mu    sync.Mutex
+	usage map[string]*Utilization
+	// fallback is the round robin used when Strategy is nil
+	fallback RoundRobin
+}
+
+func (p *Pool) Print(doc device.Document) error {
+	return p.PrintWith(doc, device.PrintOptions{})
+}
+
+// PrintWith prints doc with opts on the member the strategy picks
+func (p *Pool) PrintWith(doc device.Document, opts device.PrintOptions) error {
+	job := Job{Document: doc, Options: opts}
+	member, err := p.pick(job)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = device.PrintWith(member.Printer, doc, opts)
+	p.done(member.Name, time.Since(start), err)
+	return err
+}
+
+// Metrics returns the utilization of every member, in the order of
+// Members
+func (p *Pool) Metrics() []Utilization {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	total := 0
+	for _, u := range p.usage {
+		total += u.Jobs
+	}
+	metrics := make([]Utilization, len(p.Members))
+	for i, m := range p.Members {
+		metrics[i] = Utilization{Name: m.Name}
+		if u, ok := p.usage[m.Name]; ok {
+			metrics[i] = *u
+		}
+		if total > 0 {
+			metrics[i].Share = float64(metrics[i].Jobs) / float64(total)
+		}
+	}
+	return metrics
+}
+
+// pick asks the strategy for a member and counts the job in flight on it.
+// The strategy runs without the lock held, since it may ask devices for
+// their status.
+func (p *Pool) pick(job Job) (Member, error) {
+	p.mu.Lock()
+	candidates := make([]Candidate, len(p.Members))
+	for i, m := range p.Members {
+		candidates[i] = Candidate{Member: m}
+		if u, ok := p.usage[m.Name]; ok {
+			candidates[i].InFlight = u.InFlight
+		}
+	}
+	p.mu.Unlock()
+
+	strategy := p.Strategy
+	if strategy == nil {
+		strategy = &p.fallback
+	}
+	i, err := strategy.Pick(job, candidates)
+	if err != nil {
+		return Member{}, err
+	}
+	member := p.Members[i]
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.usage == nil {
+		p.usage = make(map[string]*Utilization)
+	}
+	u, ok := p.usage[member.Name]
+	if !ok {
+		u = &Utilization{Name: member.Name}
+		p.usage[member.Name] = u
+	}
+	u.InFlight++
+	return member, nil
+}
+
+func (p *Pool) done(name string, busy time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	u := p.usage[name]
+	u.InFlight--
+	u.Jobs++
+	u.Busy += busy
+	if err != nil {
+		u.Failed++
+	}
+}
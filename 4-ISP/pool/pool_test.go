@@ -0,0 +1,88 @@
+package pool_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/pool"
+)
+
+// JAM_SEED makes a SimulatedMFP with a JamRate of 0.3 print four jobs and
+// jam on the fifth
+const JAM_SEED = 3
+
+// printAll prints n documents on p and counts the failures
+func printAll(p *pool.Pool, n int) (failed int) {
+	for range n {
+		if p.Print(device.Text("memo.txt", "hello")) != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// TestLeastQueuedAvoidsJam makes sure a jammed member gets no more jobs
+// once it reports the jam
+func TestLeastQueuedAvoidsJam(t *testing.T) {
+	flaky := &device.SimulatedMFP{Out: io.Discard, JamRate: 0.3, Seed: JAM_SEED}
+	p := &pool.Pool{
+		Members:  []pool.Member{{"flaky", flaky}, {"healthy", &device.SimulatedMFP{Out: io.Discard}}},
+		Strategy: pool.LeastQueued{},
+	}
+	if failed := printAll(p, 8); failed != 1 {
+		t.Errorf("%d jobs failed, want only the one that jammed", failed)
+	}
+	want := []pool.Utilization{{Name: "flaky", Jobs: 5, Failed: 1}, {Name: "healthy", Jobs: 3}}
+	for i, u := range p.Metrics() {
+		if u.Name != want[i].Name || u.Jobs != want[i].Jobs || u.Failed != want[i].Failed || u.InFlight != 0 {
+			t.Errorf("Metrics()[%d] = %+v, want %d jobs with %d failed", i, u, want[i].Jobs, want[i].Failed)
+		}
+	}
+
+	flaky.Clear()
+	if err := p.Print(device.Text("memo.txt", "hello")); err != nil {
+		t.Fatalf("Print after Clear returned error: %v", err)
+	}
+	if u := p.Metrics()[0]; u.Jobs != 6 {
+		t.Errorf("flaky printed %d jobs after Clear, want it back in the pool", u.Jobs)
+	}
+}
+
+// TestRoundRobinKeepsJam shows the difference: taking turns sends every
+// other job to the jammed member
+func TestRoundRobinKeepsJam(t *testing.T) {
+	p := &pool.Pool{Members: []pool.Member{
+		{"jammed", &device.SimulatedMFP{Out: io.Discard, JamRate: 1}},
+		{"healthy", &device.SimulatedMFP{Out: io.Discard}},
+	}}
+	if failed := printAll(p, 6); failed != 3 {
+		t.Errorf("%d jobs failed, want every job sent to the jammed member", failed)
+	}
+	metrics := p.Metrics()
+	if metrics[0].Failed != 3 || metrics[1].Failed != 0 {
+		t.Errorf("Metrics() = %+v, want 3 failed on jammed and none on healthy", metrics)
+	}
+	if metrics[0].Share != 0.5 {
+		t.Errorf("jammed share = %v, want 0.5", metrics[0].Share)
+	}
+}
+
+func TestEveryMemberJammed(t *testing.T) {
+	p := &pool.Pool{
+		Members: []pool.Member{
+			{"first", &device.SimulatedMFP{Out: io.Discard, JamRate: 1}},
+			{"second", &device.SimulatedMFP{Out: io.Discard, JamRate: 1}},
+		},
+		Strategy: pool.LeastQueued{},
+	}
+	for range 2 {
+		if err := p.Print(device.Text("memo.txt", "hello")); !errors.Is(err, device.ErrDeviceFault) {
+			t.Fatalf("Print error = %v, want %v", err, device.ErrDeviceFault)
+		}
+	}
+	if err := p.Print(device.Text("memo.txt", "hello")); !errors.Is(err, pool.ErrNoPrinter) {
+		t.Errorf("Print with every member jammed error = %v, want %v", err, pool.ErrNoPrinter)
+	}
+}
@@ -0,0 +1,103 @@
+package pool
+
+import (
+	"sync"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+// Job is what a strategy decides on
+type Job struct {
+	Document device.Document
+	Options  device.PrintOptions
+}
+
+// Candidate is a member of the pool as a strategy sees it
+type Candidate struct {
+	Member
+	// InFlight is the number of jobs the pool has printing on it
+	InFlight int
+}
+
+// Strategy picks the index of the candidate that prints job, or fails
+// with ErrNoPrinter
+type Strategy interface {
+	Pick(job Job, candidates []Candidate) (int, error)
+}
+
+// RoundRobin takes turns. The zero value is ready to use.
+type RoundRobin struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (r *RoundRobin) Pick(job Job, candidates []Candidate) (int, error) {
+	if len(candidates) == 0 {
+		return 0, ErrNoPrinter
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.next % len(candidates)
+	r.next = i + 1
+	return i, nil
+}
+
+// LeastQueued picks the candidate with the fewest jobs waiting: those the
+// pool has in flight on it plus the queue depth a StatusReporter reports.
+// Ties go to the earlier candidate.
+type LeastQueued struct{}
+
+func (LeastQueued) Pick(job Job, candidates []Candidate) (int, error) {
+	best, bestDepth := -1, 0
+	for i, c := range candidates {
+		depth := c.InFlight
+		if reporter, ok := c.Printer.(device.StatusReporter); ok {
+			health, err := reporter.Status()
+			if err != nil || health.State != device.StateOnline {
+				continue
+			}
+			depth += health.QueueDepth
+		}
+		if best < 0 || depth < bestDepth {
+			best, bestDepth = i, depth
+		}
+	}
+	if best < 0 {
+		return 0, ErrNoPrinter
+	}
+	return best, nil
+}
+
+// CapabilityAware leaves out the candidates that cannot honor the print
+// options of a job, and lets Next pick among the rest
+type CapabilityAware struct {
+	// Next defaults to LeastQueued
+	Next Strategy
+}
+
+func (c CapabilityAware) Pick(job Job, candidates []Candidate) (int, error) {
+	var able []Candidate
+	var index []int
+	for i, candidate := range candidates {
+		caps := device.PrintCapabilities{}
+		if configurable, ok := candidate.Printer.(device.ConfigurablePrinter); ok {
+			caps = configurable.PrintCapabilities()
+		}
+		if job.Options.Check(caps) == nil {
+			able = append(able, candidate)
+			index = append(index, i)
+		}
+	}
+	if len(able) == 0 {
+		return 0, ErrNoPrinter
+	}
+	var next Strategy = LeastQueued{}
+	if c.Next != nil {
+		next = c.Next
+	}
+	i, err := next.Pick(job, able)
+	if err != nil {
+		return 0, err
+	}
+	return index[i], nil
+}
@@ -0,0 +1,172 @@
+// Command printer3d applies the same segregation to 3D printers. They
+// "print" too, but nothing about device.Printer fits them: they take
+// sliced models rather than documents, and around printing there are
+// chores that only some machines can do themselves.
+//
+//	SlicedModelPrinter  builds a sliced model layer by layer
+//	BedLeveler          probes the bed and compensates for its tilt
+//	FilamentMonitor     reports what is left on the spool
+//
+// A hobby printer only prints, a workshop printer does all three and a
+// clip-on filament sensor only monitors, so each claims just its roles.
+//
+// Run it with: go run ./4-ISP/printer3d
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrNotEnoughFilament is returned when a spool cannot finish a model
+	ErrNotEnoughFilament = errors.New("printer3d: not enough filament")
+	// ErrBedNotLevel is returned when the bed is tilted beyond what the
+	// printer can compensate
+	ErrBedNotLevel = errors.New("printer3d: bed not level")
+)
+
+// MAX_BED_DEVIATION is the largest tilt, in millimeters, that leveling
+// can compensate
+const MAX_BED_DEVIATION = 0.2
+
+// SlicedModel is a model cut into layers, ready to print
+type SlicedModel struct {
+	Name        string
+	Layers      int
+	LayerHeight float64
+	// Filament is how many grams the model uses
+	Filament float64
+}
+
+// Spool is the filament loaded in a printer
+type Spool struct {
+	Material string
+	// Remaining is in grams
+	Remaining float64
+}
+
+type SlicedModelPrinter interface {
+	PrintModel(model SlicedModel) error
+}
+
+type BedLeveler interface {
+	// LevelBed returns the largest deviation it measured, in millimeters
+	LevelBed() (float64, error)
+}
+
+type FilamentMonitor interface {
+	Filament() (Spool, error)
+}
+
+var (
+	_ SlicedModelPrinter = (*HobbyPrinter)(nil)
+	_ SlicedModelPrinter = (*WorkshopPrinter)(nil)
+	_ BedLeveler         = (*WorkshopPrinter)(nil)
+	_ FilamentMonitor    = (*WorkshopPrinter)(nil)
+	_ FilamentMonitor    = (*FilamentSensor)(nil)
+)
+
+// HobbyPrinter only prints. Its bed is leveled by hand and it cannot tell
+// how much filament is left.
+type HobbyPrinter struct{}
+
+func (h *HobbyPrinter) PrintModel(model SlicedModel) error {
+	fmt.Printf("hobby printer: printing %s, %d layers of %.2fmm\n", model.Name, model.Layers, model.LayerHeight)
+	return nil
+}
+
+// WorkshopPrinter probes its bed and weighs its spool
+type WorkshopPrinter struct {
+	Spool Spool
+	// Tilt is how far the bed is off, in millimeters
+	Tilt float64
+}
+
+func (w *WorkshopPrinter) PrintModel(model SlicedModel) error {
+	if model.Filament > w.Spool.Remaining {
+		return fmt.Errorf("%w: %s needs %.0fg, %.0fg left", ErrNotEnoughFilament, model.Name, model.Filament, w.Spool.Remaining)
+	}
+	w.Spool.Remaining -= model.Filament
+	fmt.Printf("workshop printer: printing %s in %s, %d layers of %.2fmm\n", model.Name, w.Spool.Material, model.Layers, model.LayerHeight)
+	return nil
+}
+
+func (w *WorkshopPrinter) LevelBed() (float64, error) {
+	deviation := math.Abs(w.Tilt)
+	if deviation > MAX_BED_DEVIATION {
+		return deviation, fmt.Errorf("%w: %.2fmm off", ErrBedNotLevel, deviation)
+	}
+	w.Tilt = 0
+	return deviation, nil
+}
+
+func (w *WorkshopPrinter) Filament() (Spool, error) {
+	return w.Spool, nil
+}
+
+// FilamentSensor clips onto any printer's spool holder. It monitors
+// filament and does nothing else, so it is not a printer of any kind.
+type FilamentSensor struct {
+	Spool Spool
+}
+
+func (f *FilamentSensor) Filament() (Spool, error) {
+	return f.Spool, nil
+}
+
+// build prints model, doing the chores first where the printer can do
+// them itself. A printer that cannot is simply trusted.
+func build(p SlicedModelPrinter, model SlicedModel) error {
+	if leveler, ok := p.(BedLeveler); ok {
+		deviation, err := leveler.LevelBed()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("bed leveled, was %.2fmm off\n", deviation)
+	}
+	if monitor, ok := p.(FilamentMonitor); ok {
+		if err := enoughFilament(monitor, model); err != nil {
+			return err
+		}
+	}
+	return p.PrintModel(model)
+}
+
+// enoughFilament works with anything that monitors filament, printer or
+// not
+func enoughFilament(m FilamentMonitor, model SlicedModel) error {
+	spool, err := m.Filament()
+	if err != nil {
+		return err
+	}
+	if spool.Remaining < model.Filament {
+		return fmt.Errorf("%w: %s needs %.0fg of %s, %.0fg left", ErrNotEnoughFilament, model.Name, model.Filament, spool.Material, spool.Remaining)
+	}
+	return nil
+}
+
+func main() {
+	bracket := SlicedModel{Name: "bracket", Layers: 120, LayerHeight: 0.2, Filament: 35}
+	vase := SlicedModel{Name: "vase", Layers: 900, LayerHeight: 0.2, Filament: 400}
+
+	hobby := &HobbyPrinter{}
+	workshop := &WorkshopPrinter{Spool: Spool{Material: "PLA", Remaining: 250}, Tilt: 0.15}
+	for _, p := range []SlicedModelPrinter{hobby, workshop} {
+		for _, model := range []SlicedModel{bracket, vase} {
+			if err := build(p, model); err != nil {
+				fmt.Printf("%T: %v\n", p, err)
+			}
+		}
+	}
+
+	// The hobby printer has no idea about its spool, a sensor clipped onto
+	// it answers the same question through the same role
+	sensor := &FilamentSensor{Spool: Spool{Material: "PETG", Remaining: 120}}
+	for _, m := range []FilamentMonitor{workshop, sensor} {
+		if err := enoughFilament(m, vase); err != nil {
+			fmt.Printf("%T: %v\n", m, err)
+		}
+	}
+}
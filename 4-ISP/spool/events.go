@@ -0,0 +1,130 @@
+package spool
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/imrancluster/go-solid/clock"
+)
+
+// EventKind is what happened to a job
+type EventKind string
+
+const (
+	EventQueued    EventKind = "queued"
+	EventStarted   EventKind = "started"
+	EventCompleted EventKind = "completed"
+	EventFailed    EventKind = "failed"
+	EventCanceled  EventKind = "canceled"
+)
+
+// Event is one change of status of a job
+type Event struct {
+	Kind EventKind
+	// Job is a snapshot of the job right after the change
+	Job  Job
+	Time time.Time
+}
+
+// Subscriber follows the jobs of a queue. Events arrive one at a time and
+// in the order they happened, after the queue let go of its lock, so a
+// subscriber may call back into the queue. A slow subscriber holds up the
+// worker that delivers to it and should hand events off to a goroutine.
+type Subscriber interface {
+	JobEvent(e Event)
+}
+
+// SubscriberFunc lets an ordinary function follow jobs
+type SubscriberFunc func(e Event)
+
+func (f SubscriberFunc) JobEvent(e Event) {
+	f(e)
+}
+
+// EventLogger writes one line per event
+type EventLogger struct {
+	// Out defaults to os.Stderr
+	Out io.Writer
+}
+
+func (l EventLogger) JobEvent(e Event) {
+	out := l.Out
+	if out == nil {
+		out = os.Stderr
+	}
+	line := fmt.Sprintf("%s %s %s", e.Job.ID, e.Job.Document.Name, e.Kind)
+	if e.Job.Err != nil {
+		line += ": " + e.Job.Err.Error()
+	}
+	fmt.Fprintln(out, line)
+}
+
+// Subscribe sends every later change of a job to s until the returned
+// function is called
+func (q *Queue) Subscribe(s Subscriber) (unsubscribe func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sub := &subscription{s}
+	q.subscribers = append(q.subscribers, sub)
+	return func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.subscribers = slices.DeleteFunc(q.subscribers, func(other *subscription) bool { return other == sub })
+	}
+}
+
+// subscription makes every Subscribe distinct, even of the same subscriber
+type subscription struct {
+	Subscriber
+}
+
+// emit records the change of job for delivery, the caller holds the lock
+func (q *Queue) emit(job *Job) {
+	if len(q.subscribers) == 0 {
+		return
+	}
+	q.outbox = append(q.outbox, Event{Kind: eventKind(job.Status), Job: *job, Time: clock.Or(q.Clock).Now()})
+}
+
+// deliver sends the recorded events to the subscribers without holding the
+// lock. Only one goroutine delivers at a time, the others leave their
+// events to it, which keeps the events in order.
+func (q *Queue) deliver() {
+	q.mu.Lock()
+	if q.delivering {
+		q.mu.Unlock()
+		return
+	}
+	q.delivering = true
+	for len(q.outbox) > 0 {
+		events, subscribers := q.outbox, slices.Clone(q.subscribers)
+		q.outbox = nil
+		q.mu.Unlock()
+		for _, e := range events {
+			for _, sub := range subscribers {
+				sub.JobEvent(e)
+			}
+		}
+		q.mu.Lock()
+	}
+	q.delivering = false
+	q.mu.Unlock()
+}
+
+// eventKind is the event of a job reaching status
+func eventKind(status Status) EventKind {
+	switch status {
+	case StatusPrinting:
+		return EventStarted
+	case StatusDone:
+		return EventCompleted
+	case StatusFailed:
+		return EventFailed
+	case StatusCanceled:
+		return EventCanceled
+	}
+	return EventQueued
+}
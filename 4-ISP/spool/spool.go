@@ -0,0 +1,399 @@
+// Package spool queues print jobs and feeds them to a Printer. The queue
+// only needs the Printer role, so any device that prints can drain it.
+package spool
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/clock"
+)
+
+var (
+	// ErrUnknownJob is returned for job IDs the queue never issued
+	ErrUnknownJob = errors.New("spool: unknown job")
+	// ErrJobFinished is returned when canceling a job that already finished
+	ErrJobFinished = errors.New("spool: job already finished")
+	// ErrQueueFull is returned by Enqueue when Capacity jobs are waiting
+	ErrQueueFull = errors.New("spool: queue full")
+)
+
+// Status tells where a job is
+type Status string
+
+const (
+	StatusQueued   Status = "queued"
+	StatusPrinting Status = "printing"
+	StatusDone     Status = "done"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+// Priority orders jobs, higher priorities print first
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// Job is one document waiting for or sent to a printer
+type Job struct {
+	ID        string
+	Document  device.Document
+	Priority  Priority
+	Status    Status
+	Submitted time.Time
+	// Err is why a failed job failed
+	Err error
+
+	seq int
+}
+
+// Queue holds jobs in priority order, first come first served within a
+// priority. The zero value is ready to use and keeps its jobs in memory
+// only.
+type Queue struct {
+	// Store persists every change of a job when it is set. Call Recover
+	// before using a queue with a store that may hold jobs.
+	Store JobStore
+	// Capacity bounds the jobs waiting to print, zero means no bound
+	Capacity int
+	// Clock stamps jobs and events, it defaults to the real clock
+	Clock clock.Clock
+
+	mu      sync.Mutex
+	seq     int
+	pending jobHeap
+	jobs    map[string]*Job
+	ready   chan struct{}
+	// space is ready when a job may have left the queue
+	space chan struct{}
+	// printing holds the cancel functions of the jobs being printed
+	printing map[string]context.CancelFunc
+
+	subscribers []*subscription
+	// outbox holds the events not yet delivered to subscribers
+	outbox     []Event
+	delivering bool
+}
+
+// Enqueue adds doc to the queue and returns its job ID. It fails with
+// ErrQueueFull rather than wait when the queue is at Capacity.
+func (q *Queue) Enqueue(doc device.Document, priority Priority) (string, error) {
+	if err := doc.Validate(); err != nil {
+		return "", err
+	}
+	defer q.deliver()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.init()
+	if q.Capacity > 0 && q.pending.Len() >= q.Capacity {
+		return "", ErrQueueFull
+	}
+	q.seq++
+	job := &Job{
+		ID:        fmt.Sprintf("job-%d", q.seq),
+		Document:  doc,
+		Priority:  priority,
+		Status:    StatusQueued,
+		Submitted: clock.Or(q.Clock).Now(),
+		seq:       q.seq,
+	}
+	if err := q.save(job); err != nil {
+		q.seq--
+		return "", err
+	}
+	q.jobs[job.ID] = job
+	heap.Push(&q.pending, job)
+	q.emit(job)
+	signal(q.ready)
+	if q.Capacity > 0 && q.pending.Len() < q.Capacity {
+		// pass on the room that is left to the next waiting producer
+		signal(q.space)
+	}
+	return job.ID, nil
+}
+
+// EnqueueWait adds doc like Enqueue, but waits for room when the queue is
+// at Capacity, so producers slow down to the pace of the workers
+func (q *Queue) EnqueueWait(ctx context.Context, doc device.Document, priority Priority) (string, error) {
+	for {
+		space := q.wait(&q.space)
+		id, err := q.Enqueue(doc, priority)
+		if !errors.Is(err, ErrQueueFull) {
+			return id, err
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-space:
+		}
+	}
+}
+
+// Job returns a snapshot of the job with id
+func (q *Queue) Job(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %q", ErrUnknownJob, id)
+	}
+	return *job, nil
+}
+
+// Len is the number of jobs waiting to print
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending.Len()
+}
+
+// Recover loads the jobs of Store. Jobs that were queued are queued
+// again, and so are jobs that were printing when the process stopped, so
+// a job may print twice but is never lost. Finished jobs can be looked up
+// again. It returns the number of jobs queued again.
+func (q *Queue) Recover() (int, error) {
+	if q.Store == nil {
+		return 0, nil
+	}
+	jobs, err := q.Store.Load()
+	if err != nil {
+		return 0, err
+	}
+	defer q.deliver()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.init()
+	requeued := 0
+	for _, stored := range jobs {
+		job := &stored
+		q.seq = max(q.seq, job.seq)
+		q.jobs[job.ID] = job
+		if job.Status != StatusQueued && job.Status != StatusPrinting {
+			continue
+		}
+		job.Status = StatusQueued
+		heap.Push(&q.pending, job)
+		q.emit(job)
+		requeued++
+	}
+	if requeued > 0 {
+		signal(q.ready)
+	}
+	return requeued, nil
+}
+
+// Cancel stops the job with id. A queued job is taken off the queue, a
+// job that is printing has the context of its print canceled, which stops
+// printers that honor it half way.
+func (q *Queue) Cancel(id string) error {
+	defer q.deliver()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownJob, id)
+	}
+	switch job.Status {
+	case StatusQueued:
+		heap.Remove(&q.pending, slices.Index(q.pending, job))
+		signal(q.space)
+		job.Status = StatusCanceled
+		q.emit(job)
+		return q.save(job)
+	case StatusPrinting:
+		q.printing[id]()
+		return nil
+	}
+	return fmt.Errorf("%w: %q is %s", ErrJobFinished, id, job.Status)
+}
+
+// take marks the next job as printing and returns the context to print it
+// with, it reports false if none is queued. When more jobs wait it wakes
+// another worker, so idle workers join in one after the other.
+func (q *Queue) take(ctx context.Context) (Job, context.Context, bool, error) {
+	defer q.deliver()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending.Len() == 0 {
+		return Job{}, nil, false, nil
+	}
+	job := heap.Pop(&q.pending).(*Job)
+	job.Status = StatusPrinting
+	if err := q.save(job); err != nil {
+		job.Status = StatusQueued
+		heap.Push(&q.pending, job)
+		return Job{}, nil, false, err
+	}
+	jobCtx, cancel := context.WithCancel(ctx)
+	q.init()
+	q.printing[job.ID] = cancel
+	q.emit(job)
+	signal(q.space)
+	if q.pending.Len() > 0 {
+		signal(q.ready)
+	}
+	return *job, jobCtx, true, nil
+}
+
+// finish records the outcome of a job that was printing. A job whose own
+// context was canceled is canceled, a job interrupted because the worker
+// stopped goes back on the queue.
+func (q *Queue) finish(ctx context.Context, id string, err error) error {
+	defer q.deliver()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job := q.jobs[id]
+	q.printing[id]()
+	delete(q.printing, id)
+	switch {
+	case err == nil:
+		job.Status, job.Err = StatusDone, nil
+	case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		job.Status = StatusQueued
+		heap.Push(&q.pending, job)
+	case errors.Is(err, context.Canceled):
+		job.Status, job.Err = StatusCanceled, nil
+	default:
+		job.Status, job.Err = StatusFailed, err
+	}
+	q.emit(job)
+	return q.save(job)
+}
+
+// save persists job if the queue has a store
+func (q *Queue) save(job *Job) error {
+	if q.Store == nil {
+		return nil
+	}
+	return q.Store.Save(*job)
+}
+
+// wait returns ch, the ready or the space channel of the queue
+func (q *Queue) wait(ch *chan struct{}) <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.init()
+	return *ch
+}
+
+func (q *Queue) init() {
+	if q.jobs == nil {
+		q.jobs = make(map[string]*Job)
+	}
+	if q.ready == nil {
+		q.ready = make(chan struct{}, 1)
+	}
+	if q.space == nil {
+		q.space = make(chan struct{}, 1)
+	}
+	if q.printing == nil {
+		q.printing = make(map[string]context.CancelFunc)
+	}
+}
+
+// signal wakes one waiter on ch without blocking
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Worker prints the jobs of Queue on Printer one at a time
+type Worker struct {
+	Queue   *Queue
+	Printer device.Printer
+}
+
+// Drain prints every queued job and returns once the queue is empty. Failed
+// and canceled jobs do not stop the worker, failing to persist a job does.
+// When ctx is done the job being printed goes back on the queue.
+func (w Worker) Drain(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		job, jobCtx, ok, err := w.Queue.take(ctx)
+		if err != nil || !ok {
+			return err
+		}
+		printed := device.PrintContext(jobCtx, w.Printer, job.Document)
+		if err := w.Queue.finish(ctx, job.ID, printed); err != nil {
+			return err
+		}
+	}
+}
+
+// Run prints jobs as they are queued until ctx is done
+func (w Worker) Run(ctx context.Context) error {
+	for {
+		if err := w.Drain(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.Queue.wait(&w.Queue.ready):
+		}
+	}
+}
+
+// jobHeap orders pending jobs by priority, then by arrival
+type jobHeap []*Job
+
+func (h jobHeap) Len() int { return len(h) }
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority > h[j].Priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x any)   { *h = append(*h, x.(*Job)) }
+func (h *jobHeap) Pop() any {
+	old := *h
+	job := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return job
+}
+
+// SpooledPrinter is a Printer that queues documents for a Worker to print
+// on Device, and reports the depth of its queue along with the health of
+// Device
+type SpooledPrinter struct {
+	Queue  *Queue
+	Device device.Printer
+}
+
+// Print queues doc at normal priority
+func (s SpooledPrinter) Print(doc device.Document) error {
+	_, err := s.Queue.Enqueue(doc, PriorityNormal)
+	return err
+}
+
+func (s SpooledPrinter) Status() (device.Health, error) {
+	health := device.Health{State: device.StateOnline}
+	if reporter, ok := s.Device.(device.StatusReporter); ok {
+		var err error
+		if health, err = reporter.Status(); err != nil {
+			return device.Health{}, err
+		}
+	}
+	health.QueueDepth = s.Queue.Len()
+	return health, nil
+}
+
+// Worker returns the worker that drains the queue onto Device
+func (s SpooledPrinter) Worker() Worker {
+	return Worker{Queue: s.Queue, Printer: s.Device}
+}
@@ -0,0 +1,250 @@
+package spool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/devicetest"
+	"github.com/imrancluster/go-solid/4-ISP/spool"
+	"github.com/imrancluster/go-solid/4-ISP/spooltest"
+)
+
+// JOB_TIME is how long the slow printer of the cancel tests takes per job
+const JOB_TIME = 300 * time.Millisecond
+
+// TestCancelWhilePrinting cancels a job half way through a print that
+// takes JOB_TIME: the printer stops, the job is canceled and the worker
+// goes on with the next job
+func TestCancelWhilePrinting(t *testing.T) {
+	queue := &spool.Queue{}
+	slow, err := queue.Enqueue(device.Text("slow.txt", "hello"), spool.PriorityHigh)
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	next, err := queue.Enqueue(device.Text("next.txt", "hello"), spool.PriorityNormal)
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	started := make(chan struct{})
+	queue.Subscribe(spool.SubscriberFunc(func(e spool.Event) {
+		if e.Kind == spool.EventStarted && e.Job.ID == slow {
+			close(started)
+		}
+	}))
+
+	printer := &devicetest.FakePrinter{Delay: JOB_TIME}
+	drained := make(chan error, 1)
+	start := time.Now()
+	go func() { drained <- spool.Worker{Queue: queue, Printer: printer}.Drain(context.Background()) }()
+	<-started
+	if err := queue.Cancel(slow); err != nil {
+		t.Fatalf("Cancel(%q) while printing returned error: %v", slow, err)
+	}
+	// next takes the whole JOB_TIME, slow must not take another
+	if err := <-drained; err != nil {
+		t.Fatalf("Drain returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > JOB_TIME*3/2 {
+		t.Errorf("Drain took %v, want the canceled job to stop half way", elapsed)
+	}
+
+	if job, _ := queue.Job(slow); job.Status != spool.StatusCanceled {
+		t.Errorf("Job(%q) status = %q, want %q", slow, job.Status, spool.StatusCanceled)
+	}
+	if job, _ := queue.Job(next); job.Status != spool.StatusDone {
+		t.Errorf("Job(%q) status = %q, want %q", next, job.Status, spool.StatusDone)
+	}
+	if printed := printer.Printed(); len(printed) != 1 || printed[0].Name != "next.txt" {
+		t.Errorf("printed %v, want only next.txt", printed)
+	}
+	if err := queue.Cancel(slow); !errors.Is(err, spool.ErrJobFinished) {
+		t.Errorf("Cancel(%q) again error = %v, want %v", slow, err, spool.ErrJobFinished)
+	}
+}
+
+func TestCancelQueued(t *testing.T) {
+	queue := &spool.Queue{}
+	id, err := queue.Enqueue(device.Text("memo.txt", "hello"), spool.PriorityNormal)
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	if err := queue.Cancel(id); err != nil {
+		t.Fatalf("Cancel(%q) returned error: %v", id, err)
+	}
+	if queue.Len() != 0 {
+		t.Errorf("Len after Cancel = %d, want 0", queue.Len())
+	}
+	printer := &devicetest.FakePrinter{}
+	if err := (spool.Worker{Queue: queue, Printer: printer}).Drain(context.Background()); err != nil {
+		t.Fatalf("Drain returned error: %v", err)
+	}
+	if printed := printer.Printed(); len(printed) != 0 {
+		t.Errorf("printed %d documents, want the canceled one left out", len(printed))
+	}
+	if err := queue.Cancel("job-99"); !errors.Is(err, spool.ErrUnknownJob) {
+		t.Errorf("Cancel(job-99) error = %v, want %v", err, spool.ErrUnknownJob)
+	}
+}
+
+// TestStoppedWorkerRequeues makes sure a job interrupted because its
+// worker stopped goes back on the queue instead of being canceled
+func TestStoppedWorkerRequeues(t *testing.T) {
+	queue := &spool.Queue{}
+	id, err := queue.Enqueue(device.Text("memo.txt", "hello"), spool.PriorityNormal)
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = spool.Worker{Queue: queue, Printer: &devicetest.FakePrinter{Delay: JOB_TIME}}.Drain(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if job, _ := queue.Job(id); job.Status != spool.StatusQueued {
+		t.Errorf("Job(%q) status = %q, want %q", id, job.Status, spool.StatusQueued)
+	}
+}
+
+// TestPriorityOrder makes sure higher priorities print first and jobs of
+// the same priority print in the order they were queued
+func TestPriorityOrder(t *testing.T) {
+	queue := &spool.Queue{}
+	for _, job := range []struct {
+		name     string
+		priority spool.Priority
+	}{
+		{"low-1", spool.PriorityLow},
+		{"normal-1", spool.PriorityNormal},
+		{"high-1", spool.PriorityHigh},
+		{"low-2", spool.PriorityLow},
+		{"normal-2", spool.PriorityNormal},
+		{"high-2", spool.PriorityHigh},
+	} {
+		if _, err := queue.Enqueue(device.Text(job.name, "hello"), job.priority); err != nil {
+			t.Fatalf("Enqueue(%s) returned error: %v", job.name, err)
+		}
+	}
+	printer := &devicetest.FakePrinter{}
+	if err := (spool.Worker{Queue: queue, Printer: printer}).Drain(context.Background()); err != nil {
+		t.Fatalf("Drain returned error: %v", err)
+	}
+	var got []string
+	for _, doc := range printer.Printed() {
+		got = append(got, doc.Name)
+	}
+	if want := []string{"high-1", "high-2", "normal-1", "normal-2", "low-1", "low-2"}; !slices.Equal(got, want) {
+		t.Errorf("printed %v, want %v", got, want)
+	}
+}
+
+// TestJobStatus follows a job from queued through printing to done, and
+// one that fails to failed with the error of its printer
+func TestJobStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		printer *devicetest.FakePrinter
+		status  spool.Status
+	}{
+		{"done", &devicetest.FakePrinter{}, spool.StatusDone},
+		{"failed", &devicetest.FakePrinter{Err: device.ErrDeviceFault}, spool.StatusFailed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			queue := &spool.Queue{}
+			id, err := queue.Enqueue(device.Text("memo.txt", "hello"), spool.PriorityNormal)
+			if err != nil {
+				t.Fatalf("Enqueue returned error: %v", err)
+			}
+			if job, err := queue.Job(id); err != nil || job.Status != spool.StatusQueued {
+				t.Fatalf("Job(%q) = %q, %v, want %q", id, job.Status, err, spool.StatusQueued)
+			}
+			var printing spool.Status
+			queue.Subscribe(spool.SubscriberFunc(func(e spool.Event) {
+				if e.Kind == spool.EventStarted {
+					job, _ := queue.Job(e.Job.ID)
+					printing = job.Status
+				}
+			}))
+			if err := (spool.Worker{Queue: queue, Printer: tc.printer}).Drain(context.Background()); err != nil {
+				t.Fatalf("Drain returned error: %v", err)
+			}
+			if printing != spool.StatusPrinting {
+				t.Errorf("Job(%q) status when started = %q, want %q", id, printing, spool.StatusPrinting)
+			}
+			job, _ := queue.Job(id)
+			if job.Status != tc.status || !errors.Is(job.Err, tc.printer.Err) {
+				t.Errorf("Job(%q) = %q, %v, want %q, %v", id, job.Status, job.Err, tc.status, tc.printer.Err)
+			}
+			if tc.printer.Err != nil && job.Err == nil {
+				t.Errorf("Job(%q) failed without Err", id)
+			}
+		})
+	}
+}
+
+// JAM_SEED makes a SimulatedMFP with a JamRate of 0.3 print four jobs and
+// jam on the fifth, then print at least the next ten once cleared
+const JAM_SEED = 3
+
+// TestWorkerSimulatedJam drains a queue to a seeded SimulatedMFP: the jobs
+// before the jam are done, the jam and every job after it fail with the
+// fault until the printer is cleared
+func TestWorkerSimulatedJam(t *testing.T) {
+	queue := &spool.Queue{}
+	var ids []string
+	for i := range 8 {
+		id, err := queue.Enqueue(device.Text(fmt.Sprintf("memo-%d.txt", i), "hello"), spool.PriorityNormal)
+		if err != nil {
+			t.Fatalf("Enqueue returned error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	printer := &device.SimulatedMFP{Out: io.Discard, JamRate: 0.3, Seed: JAM_SEED}
+	worker := spool.Worker{Queue: queue, Printer: printer}
+	if err := worker.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain returned error: %v", err)
+	}
+
+	for i, id := range ids {
+		job, _ := queue.Job(id)
+		if i < 4 {
+			if job.Status != spool.StatusDone {
+				t.Errorf("Job(%q) status = %q, want %q", id, job.Status, spool.StatusDone)
+			}
+			continue
+		}
+		if job.Status != spool.StatusFailed || !errors.Is(job.Err, device.ErrDeviceFault) {
+			t.Errorf("Job(%q) = %q, %v, want %q with %v", id, job.Status, job.Err, spool.StatusFailed, device.ErrDeviceFault)
+		}
+	}
+	if health, _ := printer.Status(); health.State != device.StateError || health.Message != "paper jam" {
+		t.Errorf("Status after the jam = %+v, want %q with a paper jam", health, device.StateError)
+	}
+
+	printer.Clear()
+	id, err := queue.Enqueue(device.Text("retry.txt", "hello"), spool.PriorityNormal)
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	if err := worker.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain returned error: %v", err)
+	}
+	if job, _ := queue.Job(id); job.Status != spool.StatusDone {
+		t.Errorf("Job(%q) status after Clear = %q, want %q", id, job.Status, spool.StatusDone)
+	}
+}
+
+// BenchmarkThroughput measures one worker on a printer that takes no time,
+// which is the overhead of the queue itself
+func BenchmarkThroughput(b *testing.B) {
+	spooltest.BenchmarkThroughput(b, &devicetest.FakePrinter{}, 1)
+}
+
+// BenchmarkWorkers compares worker counts on a slow printer, run it with
+// go test -bench Workers
+func BenchmarkWorkers(b *testing.B) { spooltest.BenchmarkWorkers(b) }
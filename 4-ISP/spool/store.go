@@ -0,0 +1,146 @@
+package spool
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+// JobStore persists the jobs of a queue, so they survive a restart. Save
+// is called on every change of a job, Load once when the queue recovers.
+type JobStore interface {
+	Save(job Job) error
+	Load() ([]Job, error)
+}
+
+// MemoryStore keeps jobs in memory. It survives a queue being replaced,
+// not the process, which is what tests of recovery need. The zero value is
+// ready to use.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+func (m *MemoryStore) Save(job Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.jobs == nil {
+		m.jobs = make(map[string]Job)
+	}
+	m.jobs[job.ID] = job
+	return nil
+}
+
+func (m *MemoryStore) Load() ([]Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// FileStore keeps jobs in a JSON file at Path. Every Save rewrites the
+// file and renames it into place, so a crash leaves either the old or the
+// new file behind, never half of one.
+type FileStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// storedJob is a Job as it is written to disk
+type storedJob struct {
+	ID        string          `json:"id"`
+	Document  device.Document `json:"document"`
+	Priority  Priority        `json:"priority"`
+	Status    Status          `json:"status"`
+	Submitted time.Time       `json:"submitted"`
+	Err       string          `json:"error,omitempty"`
+	Seq       int             `json:"seq"`
+}
+
+func (f *FileStore) Save(job Job) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, err := f.read()
+	if err != nil {
+		return err
+	}
+	record := storedJob{job.ID, job.Document, job.Priority, job.Status, job.Submitted, "", job.seq}
+	if job.Err != nil {
+		record.Err = job.Err.Error()
+	}
+	if i := slices.IndexFunc(stored, func(s storedJob) bool { return s.ID == job.ID }); i >= 0 {
+		stored[i] = record
+	} else {
+		stored = append(stored, record)
+	}
+	return f.write(stored)
+}
+
+// Load returns no jobs when the file does not exist yet
+func (f *FileStore) Load() ([]Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]Job, 0, len(stored))
+	for _, s := range stored {
+		job := Job{ID: s.ID, Document: s.Document, Priority: s.Priority, Status: s.Status, Submitted: s.Submitted, seq: s.Seq}
+		if s.Err != "" {
+			job.Err = errors.New(s.Err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (f *FileStore) read() ([]storedJob, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored []storedJob
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (f *FileStore) write(stored []storedJob) error {
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
@@ -0,0 +1,105 @@
+package spool_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/devicetest"
+	"github.com/imrancluster/go-solid/4-ISP/spool"
+)
+
+// hanging prints its first Pass jobs and then hangs on the next one like a
+// process that died half way through it, until Release is closed
+type hanging struct {
+	Pass    int
+	Hung    chan struct{}
+	Release chan struct{}
+
+	mu      sync.Mutex
+	printed int
+}
+
+func (h *hanging) Print(doc device.Document) error {
+	h.mu.Lock()
+	h.printed++
+	hang := h.printed == h.Pass+1
+	h.mu.Unlock()
+	if hang {
+		close(h.Hung)
+		<-h.Release
+	}
+	return nil
+}
+
+// TestCrashRecovery stops a spool in the middle of a job without letting
+// it record anything more, as a crash would, and reopens the store with a
+// new queue: the job that was printing and the ones still queued print,
+// the finished job stays finished
+func TestCrashRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	crashed := &spool.Queue{Store: &spool.FileStore{Path: path}}
+	var ids []string
+	for i := range 4 {
+		id, err := crashed.Enqueue(device.Text(fmt.Sprintf("page-%d.txt", i), "hello"), spool.PriorityNormal)
+		if err != nil {
+			t.Fatalf("Enqueue returned error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	printer := &hanging{Pass: 1, Hung: make(chan struct{}), Release: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- spool.Worker{Queue: crashed, Printer: printer}.Drain(ctx) }()
+	<-printer.Hung
+	// the worker does not return from the second job while the spool
+	// restarts, and stops once it does
+	t.Cleanup(func() {
+		cancel()
+		close(printer.Release)
+		<-stopped
+	})
+
+	reopened := &spool.Queue{Store: &spool.FileStore{Path: path}}
+	requeued, err := reopened.Recover()
+	if err != nil {
+		t.Fatalf("Recover returned error: %v", err)
+	}
+	if requeued != 3 {
+		t.Errorf("Recover queued %d jobs again, want the printing one and the 2 queued", requeued)
+	}
+	if job, err := reopened.Job(ids[0]); err != nil || job.Status != spool.StatusDone {
+		t.Errorf("Job(%q) after recovery = %v, %v, want it done", ids[0], job.Status, err)
+	}
+
+	fake := &devicetest.FakePrinter{}
+	if err := (spool.Worker{Queue: reopened, Printer: fake}).Drain(context.Background()); err != nil {
+		t.Fatalf("Drain after recovery returned error: %v", err)
+	}
+	var names []string
+	for _, doc := range fake.Printed() {
+		names = append(names, doc.Name)
+	}
+	if want := []string{"page-1.txt", "page-2.txt", "page-3.txt"}; fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("printed %v after recovery, want %v", names, want)
+	}
+	for _, id := range ids {
+		if job, err := reopened.Job(id); err != nil || job.Status != spool.StatusDone {
+			t.Errorf("Job(%q) = %v, %v, want it done", id, job.Status, err)
+		}
+	}
+
+	// a new job after recovery does not reuse the ID of a stored one
+	id, err := reopened.Enqueue(device.Text("page-4.txt", "hello"), spool.PriorityNormal)
+	if err != nil {
+		t.Fatalf("Enqueue after recovery returned error: %v", err)
+	}
+	for _, old := range ids {
+		if id == old {
+			t.Errorf("Enqueue after recovery issued %q again", id)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package spool
+
+import (
+	"context"
+	"sync"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+const DEFAULT_CONCURRENCY = 4
+
+// Workers drain one queue with up to Concurrency jobs printing at once.
+// Printer must be safe for concurrent use, as a pool.Pool or a
+// NetworkPrinter is. Together with a Queue Capacity and EnqueueWait,
+// producers are held back once the workers fall behind.
+type Workers struct {
+	Queue   *Queue
+	Printer device.Printer
+	// Concurrency defaults to DEFAULT_CONCURRENCY
+	Concurrency int
+}
+
+// Drain prints every queued job concurrently and returns once the queue
+// is empty and every job finished
+func (w Workers) Drain(ctx context.Context) error {
+	return w.each(func(worker Worker) error { return worker.Drain(ctx) })
+}
+
+// Run prints jobs as they are queued until ctx is done
+func (w Workers) Run(ctx context.Context) error {
+	return w.each(func(worker Worker) error { return worker.Run(ctx) })
+}
+
+// each runs f for every worker and returns the first error
+func (w Workers) each(f func(Worker) error) error {
+	concurrency := w.Concurrency
+	if concurrency <= 0 {
+		concurrency = DEFAULT_CONCURRENCY
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, concurrency)
+	for i := range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = f(Worker{Queue: w.Queue, Printer: w.Printer})
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
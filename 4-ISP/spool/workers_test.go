@@ -0,0 +1,138 @@
+package spool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/devicetest"
+	"github.com/imrancluster/go-solid/4-ISP/spool"
+)
+
+const (
+	PRODUCERS         = 8
+	JOBS_PER_PRODUCER = 25
+)
+
+// TestWorkersUnderLoad has PRODUCERS goroutines fill a bounded queue with
+// EnqueueWait while Workers drain it, with subscribers and status reads
+// on the side. Run it with go test -race to catch unguarded state.
+func TestWorkersUnderLoad(t *testing.T) {
+	queue := &spool.Queue{Capacity: 4}
+	var (
+		mu       sync.Mutex
+		finished = make(map[string]int)
+	)
+	queue.Subscribe(spool.SubscriberFunc(func(e spool.Event) {
+		if e.Kind == spool.EventCompleted {
+			mu.Lock()
+			finished[e.Job.ID]++
+			mu.Unlock()
+		}
+	}))
+	printer := &devicetest.FakePrinter{Delay: 100 * time.Microsecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	running := make(chan error, 1)
+	go func() { running <- spool.Workers{Queue: queue, Printer: printer, Concurrency: 4}.Run(ctx) }()
+
+	var (
+		producers sync.WaitGroup
+		ids       = make(chan string, PRODUCERS*JOBS_PER_PRODUCER)
+	)
+	for p := range PRODUCERS {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			for i := range JOBS_PER_PRODUCER {
+				id, err := queue.EnqueueWait(ctx, device.Text(fmt.Sprintf("%d-%d.txt", p, i), "hello"), spool.PriorityNormal)
+				if err != nil {
+					t.Errorf("EnqueueWait returned error: %v", err)
+					return
+				}
+				ids <- id
+				queue.Job(id)
+				queue.Len()
+			}
+		}()
+	}
+	producers.Wait()
+	close(ids)
+
+	const total = PRODUCERS * JOBS_PER_PRODUCER
+	deadline := time.Now().Add(5 * time.Second)
+	for len(printer.Printed()) < total && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-running; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run error = %v, want %v", err, context.Canceled)
+	}
+
+	if printed := len(printer.Printed()); printed != total {
+		t.Errorf("printed %d jobs, want %d", printed, total)
+	}
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("job ID %q was issued twice", id)
+		}
+		seen[id] = true
+		if job, err := queue.Job(id); err != nil || job.Status != spool.StatusDone {
+			t.Errorf("Job(%q) = %v, %v, want it done", id, job.Status, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for id, n := range finished {
+		if n != 1 {
+			t.Errorf("job %q completed %d times, want once", id, n)
+		}
+	}
+}
+
+// TestBackpressure makes sure a full queue refuses Enqueue and holds
+// EnqueueWait back until a job leaves it or the context is done
+func TestBackpressure(t *testing.T) {
+	queue := &spool.Queue{Capacity: 2}
+	for range 2 {
+		if _, err := queue.Enqueue(device.Text("memo.txt", "hello"), spool.PriorityNormal); err != nil {
+			t.Fatalf("Enqueue returned error: %v", err)
+		}
+	}
+	if _, err := queue.Enqueue(device.Text("memo.txt", "hello"), spool.PriorityNormal); !errors.Is(err, spool.ErrQueueFull) {
+		t.Fatalf("Enqueue on a full queue error = %v, want %v", err, spool.ErrQueueFull)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := queue.EnqueueWait(ctx, device.Text("memo.txt", "hello"), spool.PriorityNormal); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("EnqueueWait on a full queue error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		_, err := queue.EnqueueWait(context.Background(), device.Text("memo.txt", "hello"), spool.PriorityNormal)
+		waited <- err
+	}()
+	select {
+	case err := <-waited:
+		t.Fatalf("EnqueueWait returned %v before there was room", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if err := (spool.Worker{Queue: queue, Printer: &devicetest.FakePrinter{}}).Drain(context.Background()); err != nil {
+		t.Fatalf("Drain returned error: %v", err)
+	}
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Errorf("EnqueueWait returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("EnqueueWait still waits after the queue drained")
+	}
+}
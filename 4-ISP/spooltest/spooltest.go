@@ -0,0 +1,86 @@
+// Package spooltest measures the spooler. Like paymenttest in the LSP
+// module it holds exported helpers for the test files of other packages,
+// so every spooler setup is measured the same way.
+package spooltest
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/devicetest"
+	"github.com/imrancluster/go-solid/4-ISP/spool"
+)
+
+const (
+	// JOB_TIME is how long each job takes on the benchmark printer
+	JOB_TIME = 100 * time.Microsecond
+	// QUEUE_CAPACITY is the capacity of the benchmark queue, small enough
+	// that the producer is held back
+	QUEUE_CAPACITY = 64
+)
+
+// CONCURRENCY are the worker counts BenchmarkWorkers compares
+var CONCURRENCY = []int{1, 2, 4, 8, 16}
+
+// BenchmarkThroughput pushes b.N jobs through a queue of QUEUE_CAPACITY
+// with EnqueueWait while concurrency workers print them on p, and reports
+// jobs per second
+func BenchmarkThroughput(b *testing.B, p device.Printer, concurrency int) {
+	b.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := &spool.Queue{Capacity: QUEUE_CAPACITY}
+	printed := &counter{Printer: p, want: int64(b.N), done: cancel}
+	doc := device.Text("bench.txt", "benchmark page")
+
+	b.ResetTimer()
+	start := time.Now()
+	workers := make(chan error, 1)
+	go func() {
+		workers <- spool.Workers{Queue: queue, Printer: printed, Concurrency: concurrency}.Run(ctx)
+	}()
+	for range b.N {
+		if _, err := queue.EnqueueWait(ctx, doc, spool.PriorityNormal); err != nil {
+			b.Fatalf("EnqueueWait returned error: %v", err)
+		}
+	}
+	<-workers
+	b.StopTimer()
+	if n := printed.n.Load(); n != int64(b.N) {
+		b.Fatalf("printed %d jobs, want %d", n, b.N)
+	}
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "jobs/s")
+}
+
+// BenchmarkWorkers compares the throughput of CONCURRENCY workers on a
+// printer that takes JOB_TIME per job and can print many at once. Call it
+// from a benchmark:
+//
+//	func BenchmarkWorkers(b *testing.B) { spooltest.BenchmarkWorkers(b) }
+func BenchmarkWorkers(b *testing.B) {
+	for _, concurrency := range CONCURRENCY {
+		b.Run(fmt.Sprintf("workers=%d", concurrency), func(b *testing.B) {
+			BenchmarkThroughput(b, &devicetest.FakePrinter{Delay: JOB_TIME}, concurrency)
+		})
+	}
+}
+
+// counter counts the jobs printed and calls done once it saw want of them
+type counter struct {
+	device.Printer
+	want int64
+	done func()
+	n    atomic.Int64
+}
+
+func (c *counter) Print(doc device.Document) error {
+	err := c.Printer.Print(doc)
+	if c.n.Add(1) == c.want {
+		c.done()
+	}
+	return err
+}
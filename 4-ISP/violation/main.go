@@ -0,0 +1,96 @@
+// Command violation shows the fat interface the device roles were split
+// from. Machine makes every device promise to print, scan and fax, so a
+// printer that can only print has to stub the rest, and callers holding a
+// Machine find out at run time.
+//
+//	OfficeMachine  honors every method of Machine
+//	SimplePrinter  panics in Scan and Fax, and has to change again
+//	               whenever Machine grows another method
+//
+// With the roles of package device a printer only claims to be a Printer,
+// so the same calls are not even possible and the checks do not apply.
+//
+// Run it with: go run ./4-ISP/violation
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+// Machine is the fat interface: one contract for every device
+type Machine interface {
+	Print(doc device.Document) error
+	Scan(dst io.Writer) error
+	Fax(number string, doc device.Document) error
+}
+
+// OfficeMachine can do all of it, so Machine fits
+type OfficeMachine struct {
+	device.OfficeMFP
+}
+
+// SimplePrinter only prints but has to be a Machine to be used at all
+type SimplePrinter struct {
+	device.SimplePrinter
+}
+
+func (p SimplePrinter) Scan(dst io.Writer) error {
+	panic("SimplePrinter cannot scan")
+}
+
+func (p SimplePrinter) Fax(number string, doc device.Document) error {
+	panic("SimplePrinter cannot fax")
+}
+
+// check is a behavior callers of a Machine rely on
+type check struct {
+	name string
+	run  func(m Machine) error
+}
+
+var checks = []check{
+	{"Print", func(m Machine) error { return m.Print(device.Text("memo.txt", "hello")) }},
+	{"Scan", func(m Machine) error { return m.Scan(io.Discard) }},
+	{"Fax", func(m Machine) error { return m.Fax("+1-555-0100", device.Text("memo.txt", "hello")) }},
+}
+
+// run executes c and turns a panic into a failure
+func run(c check, m Machine) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	return c.run(m)
+}
+
+func main() {
+	original := device.Text("contract.txt", "Signed by both parties")
+	for _, m := range []struct {
+		name    string
+		machine Machine
+	}{
+		{"OfficeMachine", OfficeMachine{device.OfficeMFP{Out: io.Discard, Original: original}}},
+		{"SimplePrinter", SimplePrinter{device.SimplePrinter{Out: io.Discard}}},
+	} {
+		fmt.Println(m.name)
+		for _, c := range checks {
+			report(c.name, run(c, m.machine))
+		}
+	}
+
+	// After the split a printer claims only what it does
+	fmt.Println("device.SimplePrinter can", device.Roles(device.SimplePrinter{}))
+	fmt.Println("device.OfficeMFP can", device.Roles(device.OfficeMFP{}))
+}
+
+func report(name string, err error) {
+	if err != nil {
+		fmt.Printf("  FAIL %s: %v\n", name, err)
+		return
+	}
+	fmt.Printf("  ok   %s\n", name)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+// TestOfficeMachinePasses makes sure a device that can do everything
+// honors the fat interface
+func TestOfficeMachinePasses(t *testing.T) {
+	m := OfficeMachine{device.OfficeMFP{Out: io.Discard, Original: device.Text("contract.txt", "Signed by both parties")}}
+	for _, c := range checks {
+		if err := run(c, m); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+	}
+}
+
+// TestSimplePrinterViolates shows the stubs a fat interface forces: the
+// printer prints, and panics for everything else it had to claim
+func TestSimplePrinterViolates(t *testing.T) {
+	m := SimplePrinter{device.SimplePrinter{Out: io.Discard}}
+	for _, c := range checks {
+		err := run(c, m)
+		if c.name == "Print" {
+			if err != nil {
+				t.Errorf("Print: %v", err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), "panicked") {
+			t.Errorf("%s error = %v, want the stub to panic", c.name, err)
+		}
+	}
+}
+
+// TestSegregatedRoles makes sure that after the split a printer claims
+// only the role it has
+func TestSegregatedRoles(t *testing.T) {
+	if roles := device.Roles(device.SimplePrinter{}); slices.Contains(roles, device.RoleScan) || slices.Contains(roles, device.RoleFax) {
+		t.Errorf("device.SimplePrinter has roles %v, want neither scan nor fax", roles)
+	}
+	roles := device.Roles(device.OfficeMFP{})
+	for _, role := range []device.Role{device.RolePrint, device.RoleScan, device.RoleFax} {
+		if !slices.Contains(roles, role) {
+			t.Errorf("device.OfficeMFP has roles %v, want %s among them", roles, role)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/mail"
+)
+
+// ErrNoRecipient is returned for a scanned page nobody should receive
+var ErrNoRecipient = errors.New("workflow: page has no recipient")
+
+// ScanSplitter works through a stack of originals in a document feeder
+// and treats every page as a document of its own: it recognizes the text
+// of the page and mails the page to whoever the text says it is for, such
+// as invoices to accounts and letters to the front desk.
+type ScanSplitter struct {
+	Scanner device.BatchScanner
+	OCR     device.OCR
+	Mailer  mail.Mailer
+	From    string
+	// Route picks the recipients of a page from its text, pages it returns
+	// no one for are mailed to To. Without Route every page goes to To.
+	Route func(text string) []string
+	To    []string
+}
+
+// SplitResult is what happened to one scanned page
+type SplitResult struct {
+	Document device.Document
+	Text     string
+	To       []string
+	Err      error
+}
+
+// Run scans the whole batch. A page that cannot be recognized or mailed
+// does not stop the others; their errors are joined. A failing scanner
+// ends the batch.
+func (s ScanSplitter) Run(ctx context.Context) ([]SplitResult, error) {
+	var results []SplitResult
+	var errs []error
+	for page, err := range s.Scanner.ScanAll(ctx) {
+		if err != nil {
+			return results, errors.Join(append(errs, fmt.Errorf("scanning batch: %w", err))...)
+		}
+		result := s.split(ctx, page)
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+		results = append(results, result)
+	}
+	return results, errors.Join(errs...)
+}
+
+// split recognizes and mails one page
+func (s ScanSplitter) split(ctx context.Context, page device.Document) SplitResult {
+	result := SplitResult{Document: page}
+	text, err := s.OCR.Recognize(ctx, page)
+	if err != nil {
+		result.Err = fmt.Errorf("recognizing %s: %w", page.Name, err)
+		return result
+	}
+	result.Text, result.To = text, s.To
+	if s.Route != nil {
+		if to := s.Route(text); len(to) > 0 {
+			result.To = to
+		}
+	}
+	if len(result.To) == 0 {
+		result.Err = fmt.Errorf("mailing %s: %w", page.Name, ErrNoRecipient)
+		return result
+	}
+	err = s.Mailer.Send(ctx, mail.Message{
+		From:    s.From,
+		To:      result.To,
+		Subject: "Scanned page: " + page.Name,
+		Body:    text,
+		Attachments: []mail.Attachment{
+			{Name: page.Name, ContentType: string(page.Format), Content: page.Content},
+		},
+	})
+	if err != nil {
+		result.Err = fmt.Errorf("mailing %s: %w", page.Name, err)
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+// Package workflow composes the narrow office roles into end-to-end jobs.
+// Each workflow depends only on the interfaces it uses, never on a device.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/mail"
+)
+
+// EmailToPrint prints the attachments of incoming mail, so anyone can print
+// by sending an email to the office printer
+type EmailToPrint struct {
+	Source  mail.MailSource
+	Printer device.Printer
+	// Allow decides whose mail gets printed, it defaults to everyone
+	Allow func(from string) bool
+}
+
+// PrintResult is what happened to one attachment
+type PrintResult struct {
+	From     string
+	Document device.Document
+	Err      error
+}
+
+// Run fetches the waiting mail once and prints every attachment. A failed
+// attachment does not stop the others; their errors are joined.
+func (e EmailToPrint) Run(ctx context.Context) ([]PrintResult, error) {
+	messages, err := e.Source.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var results []PrintResult
+	var errs []error
+	for _, msg := range messages {
+		if e.Allow != nil && !e.Allow(msg.From) {
+			continue
+		}
+		for _, a := range msg.Attachments {
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+			doc := device.Document{Name: a.Name, Content: a.Content, Format: device.Format(a.ContentType)}
+			err := e.Printer.Print(doc)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("printing %s from %s: %w", a.Name, msg.From, err))
+			}
+			results = append(results, PrintResult{From: msg.From, Document: doc, Err: err})
+		}
+	}
+	return results, errors.Join(errs...)
+}
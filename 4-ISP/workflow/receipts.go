@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/imrancluster/go-solid/3-LSP/payment"
+	"github.com/imrancluster/go-solid/3-LSP/receipt"
+	"github.com/imrancluster/go-solid/4-ISP/device"
+)
+
+// ErrUnpaid is returned when printing an invoice that is not paid in full
+var ErrUnpaid = errors.New("workflow: invoice not paid in full")
+
+// Invoice is a bill and the payments that settled it, shaped like the
+// invoice of the SRP example
+type Invoice struct {
+	ID       int
+	Amount   float64
+	Payments []payment.PaymentResult
+}
+
+// ReceiptPrinting prints payment receipts and paid invoices. Receipts are
+// rendered by package receipt and handed over as plain documents, so any
+// Printer will do, from a ReceiptPrinter at the till to a PDFPrinter.
+type ReceiptPrinting struct {
+	Printer device.Printer
+}
+
+// PrintReceipt prints the receipt of one captured or refunded payment
+func (r ReceiptPrinting) PrintReceipt(result payment.PaymentResult) error {
+	rec, err := receipt.New(result)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	if err := (receipt.TextRenderer{}).Render(&b, rec); err != nil {
+		return err
+	}
+	return r.Printer.Print(device.Text("receipt-"+rec.PaymentID+".txt", b.String()))
+}
+
+// PrintInvoice prints invoice with the receipts of the payments that
+// settled it. Invoices whose captured payments fall short of the amount
+// fail with ErrUnpaid and are not printed.
+func (r ReceiptPrinting) PrintInvoice(invoice Invoice) error {
+	trail := receipt.Trail(invoice.Payments...)
+	var paid float64
+	for _, rec := range trail {
+		if rec.Status == payment.StatusCaptured {
+			paid += rec.Amount
+		}
+	}
+	if paid < invoice.Amount {
+		return fmt.Errorf("%w: invoice %d has %.2f of %.2f", ErrUnpaid, invoice.ID, paid, invoice.Amount)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Invoice %d\n  Amount: %.2f\n  Paid:   %.2f\n\n", invoice.ID, invoice.Amount, paid)
+	if err := (receipt.TextRenderer{}).Render(&b, trail...); err != nil {
+		return err
+	}
+	return r.Printer.Print(device.Text(fmt.Sprintf("invoice-%d.txt", invoice.ID), b.String()))
+}
@@ -0,0 +1,38 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/4-ISP/device"
+	"github.com/imrancluster/go-solid/4-ISP/mail"
+)
+
+// ScanToEmail scans a document and mails it as an attachment. It needs a
+// Scanner and a Mailer and nothing else, so any scanner works with any
+// way of sending mail.
+type ScanToEmail struct {
+	Scanner device.Scanner
+	Mailer  mail.Mailer
+	From    string
+}
+
+// Send scans once and mails the scan to recipients as name
+func (s ScanToEmail) Send(ctx context.Context, name string, format device.Format, to ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	doc, err := device.ScanToMemory(s.Scanner, name, format)
+	if err != nil {
+		return fmt.Errorf("scanning %s: %w", name, err)
+	}
+	return s.Mailer.Send(ctx, mail.Message{
+		From:    s.From,
+		To:      to,
+		Subject: "Scanned document: " + doc.Name,
+		Body:    fmt.Sprintf("%s is attached (%d bytes).", doc.Name, len(doc.Content)),
+		Attachments: []mail.Attachment{
+			{Name: doc.Name, ContentType: string(doc.Format), Content: doc.Content},
+		},
+	})
+}
@@ -0,0 +1,86 @@
+// Package api serves payments over HTTP. The handler is a high-level
+// module at the transport boundary: it depends on the small Processor
+// interface it declares itself, not on how payments are made, so it can be
+// served with any processor and tested with fakes.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+// MAX_BODY bounds the request bodies the handler reads
+const MAX_BODY = 1 << 20
+
+// Processor is everything the handler needs from a payment processor.
+// payment.PaymentProcessor implements it.
+type Processor interface {
+	Process(ctx context.Context, amount float64) (payment.Transaction, error)
+}
+
+var _ Processor = payment.PaymentProcessor{}
+
+// PaymentRequest is the body of POST /payments
+type PaymentRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+// errorResponse is the body of every response that is not a transaction
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler serves POST /payments with processor. A payment that goes
+// through is answered with 201 Created, a failed one with the status
+// picked by failureStatus; both with the transaction as JSON. A client
+// that goes away cancels its payment.
+func NewHandler(processor Processor) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /payments", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, MAX_BODY))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+			return
+		}
+		var req PaymentRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{"malformed payment request"})
+			return
+		}
+		if req.Amount <= 0 {
+			writeJSON(w, http.StatusUnprocessableEntity, errorResponse{"amount must be positive"})
+			return
+		}
+		tx, err := processor.Process(r.Context(), req.Amount)
+		if err != nil {
+			writeJSON(w, failureStatus(err), tx)
+			return
+		}
+		writeJSON(w, http.StatusCreated, tx)
+	})
+	return mux
+}
+
+// failureStatus is 503 Service Unavailable for a method that is down, 504
+// Gateway Timeout for a payment that ran out of time and 402 Payment
+// Required for every other failure, declines included
+func failureStatus(err error) int {
+	switch {
+	case errors.Is(err, payment.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusPaymentRequired
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
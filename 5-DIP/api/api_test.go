@@ -0,0 +1,139 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/api"
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/5-DIP/paymenttest"
+)
+
+// post sends body to POST /payments of a handler paying with method and
+// returns the response and the transaction it carried
+func post(t *testing.T, method payment.PaymentMethod, body string) (*http.Response, payment.Transaction) {
+	t.Helper()
+	server := httptest.NewServer(api.NewHandler(payment.NewPaymentProcessor(method)))
+	t.Cleanup(server.Close)
+	resp, err := server.Client().Post(server.URL+"/payments", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /payments returned error: %v", err)
+	}
+	defer resp.Body.Close()
+	var tx payment.Transaction
+	if err := json.NewDecoder(resp.Body).Decode(&tx); err != nil {
+		t.Fatalf("decoding the response: %v", err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	return resp, tx
+}
+
+func TestPaymentCreated(t *testing.T) {
+	spy := &paymenttest.SpyMethod{}
+	resp, tx := post(t, spy, `{"amount": 90}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if !tx.Paid || tx.Amount != 90 || tx.ID == "" {
+		t.Errorf("transaction = %+v, want 90 paid", tx)
+	}
+	if calls := spy.Calls(); len(calls) != 1 || calls[0] != 90 {
+		t.Errorf("the method was asked to pay %v, want [90]", calls)
+	}
+}
+
+// TestBadRequests makes sure requests the handler cannot pay never reach
+// the method
+func TestBadRequests(t *testing.T) {
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"amount":`, http.StatusBadRequest},
+		{`"90"`, http.StatusBadRequest},
+		{`{"amount": "90"}`, http.StatusBadRequest},
+		{`{}`, http.StatusUnprocessableEntity},
+		{`{"amount": 0}`, http.StatusUnprocessableEntity},
+		{`{"amount": -5}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		spy := &paymenttest.SpyMethod{}
+		if resp, _ := post(t, spy, tt.body); resp.StatusCode != tt.status {
+			t.Errorf("POST %s status = %d, want %d", tt.body, resp.StatusCode, tt.status)
+		}
+		if calls := spy.Calls(); len(calls) != 0 {
+			t.Errorf("POST %s asked the method to pay %v", tt.body, calls)
+		}
+	}
+}
+
+// TestFailedPayments makes sure every failure is answered with the status
+// that tells the client whether to try again
+func TestFailedPayments(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{payment.ErrDeclined, http.StatusPaymentRequired},
+		{payment.ErrSuspectedFraud, http.StatusPaymentRequired},
+		{payment.ErrUnavailable, http.StatusServiceUnavailable},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+	}
+	for _, tt := range tests {
+		resp, tx := post(t, paymenttest.FailingMethod{Err: tt.err}, `{"amount": 90}`)
+		if resp.StatusCode != tt.status {
+			t.Errorf("status of a payment failing with %v = %d, want %d", tt.err, resp.StatusCode, tt.status)
+		}
+		if tx.Paid || tx.Amount != 90 || !strings.Contains(tx.Error, tt.err.Error()) {
+			t.Errorf("transaction of a payment failing with %v = %+v, want it unpaid with the reason", tt.err, tx)
+		}
+	}
+}
+
+// TestClientCancels makes sure a client that goes away abandons its
+// payment instead of leaving the method to finish it
+func TestClientCancels(t *testing.T) {
+	spy := &paymenttest.SpyMethod{Method: paymenttest.SlowMethod{Delay: time.Minute}}
+	logs := &paymenttest.LogRecorder{}
+	server := httptest.NewServer(api.NewHandler(payment.NewPaymentProcessor(spy, payment.WithLogger(logs))))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for len(spy.Calls()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/payments", strings.NewReader(`{"amount": 90}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := server.Client().Do(req); err == nil {
+		resp.Body.Close()
+		t.Fatalf("POST /payments answered %s, want the request canceled", resp.Status)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		for _, entry := range logs.Entries() {
+			if entry.Msg != "payment failed" {
+				continue
+			}
+			if err, _ := entry.Args[len(entry.Args)-1].(error); !errors.Is(err, context.Canceled) {
+				t.Errorf("the payment failed with %v, want %v", err, context.Canceled)
+			}
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("the payment of a client that went away was not abandoned")
+}
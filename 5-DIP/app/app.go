@@ -0,0 +1,136 @@
+// Package app is the composition root of the DIP example: the one place
+// where concrete types are named. It builds the low-level modules and
+// hands them to the high-level ones as abstractions, so swapping an
+// implementation changes this package and nothing else.
+package app
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+// DEFAULT_TIMEOUT bounds every call to a payment gateway
+const DEFAULT_TIMEOUT = 10 * time.Second
+
+// Config is what the composition root is told about the outside world.
+// It is usually read with ParseConfig or ConfigFromEnv.
+type Config struct {
+	// Provider names the payment method, it defaults to DEFAULT_PROVIDER
+	Provider string
+	// Decorators wrap the method, the first one innermost
+	Decorators []string
+	// Fallback lists the providers the fallback provider tries in order,
+	// comma separated. It defaults to DEFAULT_FALLBACK.
+	Fallback string
+	// StripeURL and PayPalURL default to the providers' test APIs
+	StripeURL string
+	PayPalURL string
+	// Secrets names where the gateways find their credentials: env, file
+	// or the three fields below when it is empty. The file source reads
+	// them from SecretsDir.
+	Secrets            string
+	SecretsDir         string
+	StripeKey          string
+	PayPalClientID     string
+	PayPalClientSecret string
+	// StripePaymentMethod is the card Stripe charges, it defaults to its
+	// test card
+	StripePaymentMethod string
+	// Notifier names how receipts are sent: console, email, sms or not
+	// at all when it is empty
+	Notifier string
+	// SMTPAddr is the host:port of the mail server, SMTPUser and
+	// SMTPPassword may be empty. MailTo is a comma separated list.
+	SMTPAddr     string
+	SMTPUser     string
+	SMTPPassword string
+	MailFrom     string
+	MailTo       string
+	// SMSURL defaults to the Twilio API
+	SMSURL        string
+	SMSAccountSID string
+	SMSAuthToken  string
+	SMSFrom       string
+	SMSTo         string
+	// Log names how payments are logged: text, json or not at all when
+	// it is empty
+	Log string
+	// LogOutput defaults to os.Stdout
+	LogOutput io.Writer
+	// Store names where transactions are kept: memory, file, sql or
+	// nowhere when it is empty. The file store writes to StorePath, the
+	// sql store to DB, which has no configuration key since its driver is
+	// the caller's choice.
+	Store     string
+	StorePath string
+	DB        *sql.DB
+	// IDs names how transactions are identified: uuid, ulid or
+	// sequential, which numbers them tx-1, tx-2 and so on. It defaults to
+	// uuid.
+	IDs string
+	// Fraud names how payments are vetted before they are paid: rules or
+	// not at all when it is empty. FraudMaxAmount is the largest payment
+	// the rules let through, it defaults to fraud.DEFAULT_MAX_AMOUNT.
+	Fraud          string
+	FraudMaxAmount string
+	// HTTPClient is shared by the gateways and the SMS notifier, it
+	// defaults to a client with DEFAULT_TIMEOUT
+	HTTPClient *http.Client
+}
+
+func (c Config) client() *http.Client {
+	if c.HTTPClient == nil {
+		return &http.Client{Timeout: DEFAULT_TIMEOUT}
+	}
+	return c.HTTPClient
+}
+
+// App is the wired object graph
+type App struct {
+	Processor payment.PaymentProcessor
+	// Store is the processor's transaction store, it may be nil
+	Store payment.TransactionStore
+}
+
+// Wire builds every dependency explicitly and connects them. Nothing it
+// returns constructs a dependency of its own.
+func Wire(cfg Config) (App, error) {
+	method, err := NewMethod(cfg)
+	if err != nil {
+		return App{}, err
+	}
+	notifier, err := NewNotifier(cfg)
+	if err != nil {
+		return App{}, err
+	}
+	logger, err := NewLogger(cfg)
+	if err != nil {
+		return App{}, err
+	}
+	store, err := NewStore(cfg)
+	if err != nil {
+		return App{}, err
+	}
+	ids, err := NewIDs(cfg)
+	if err != nil {
+		return App{}, err
+	}
+	checker, err := NewFraudChecker(cfg)
+	if err != nil {
+		return App{}, err
+	}
+	return App{
+		Processor: payment.NewPaymentProcessor(method,
+			payment.WithNotifier(notifier),
+			payment.WithLogger(logger),
+			payment.WithStore(store),
+			payment.WithIDs(ids),
+			payment.WithFraudChecker(checker),
+		),
+		Store: store,
+	}, nil
+}
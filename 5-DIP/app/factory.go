@@ -0,0 +1,267 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/imrancluster/go-solid/5-DIP/gateway"
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/5-DIP/resilience"
+)
+
+const (
+	DEFAULT_PROVIDER = "card"
+	// DEFAULT_FALLBACK is the chain of the fallback provider: card, then
+	// wallet, then bank transfer
+	DEFAULT_FALLBACK = "card, paypal, bank"
+	// ENV_PREFIX starts the environment variable of every configuration
+	// key, PAYMENT_PROVIDER for provider
+	ENV_PREFIX = "PAYMENT_"
+)
+
+var (
+	// ErrUnknownProvider is returned for providers no factory builds
+	ErrUnknownProvider = errors.New("app: unknown payment provider")
+	// ErrUnknownDecorator is returned for decorators nobody registered
+	ErrUnknownDecorator = errors.New("app: unknown decorator")
+	// ErrBadConfig is returned for configuration that cannot be parsed
+	ErrBadConfig = errors.New("app: bad configuration")
+)
+
+// Provider builds the payment method of one provider from cfg. client is
+// the HTTP client gateways share.
+type Provider func(cfg Config, client *http.Client) (payment.PaymentMethod, error)
+
+// Decorator wraps a payment method in a cross-cutting concern
+type Decorator func(method payment.PaymentMethod, cfg Config) payment.PaymentMethod
+
+var (
+	factoryMu sync.RWMutex
+	providers = map[string]Provider{
+		"card": func(Config, *http.Client) (payment.PaymentMethod, error) {
+			return payment.CreditCard{}, nil
+		},
+		"paypal": func(Config, *http.Client) (payment.PaymentMethod, error) {
+			return payment.PayPal{}, nil
+		},
+		"bank": func(Config, *http.Client) (payment.PaymentMethod, error) {
+			return payment.BankTransfer{}, nil
+		},
+		"stripe": func(cfg Config, client *http.Client) (payment.PaymentMethod, error) {
+			secrets, err := NewSecrets(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return gateway.Stripe{BaseURL: cfg.StripeURL, Secrets: secrets, PaymentMethod: cfg.StripePaymentMethod, Client: client}, nil
+		},
+		"paypal-api": func(cfg Config, client *http.Client) (payment.PaymentMethod, error) {
+			secrets, err := NewSecrets(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return gateway.PayPal{BaseURL: cfg.PayPalURL, Secrets: secrets, Client: client}, nil
+		},
+	}
+	decorators = map[string]Decorator{
+		"retry": func(method payment.PaymentMethod, _ Config) payment.PaymentMethod {
+			return resilience.Retry{Method: method}
+		},
+		"breaker": func(method payment.PaymentMethod, _ Config) payment.PaymentMethod {
+			return &resilience.Breaker{Method: method}
+		},
+	}
+)
+
+// RegisterProvider makes a payment method available by provider name. It
+// panics if provider is nil or name is already registered.
+func RegisterProvider(name string, provider Provider) {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	if provider == nil {
+		panic("app: RegisterProvider provider is nil")
+	}
+	if _, dup := providers[name]; dup {
+		panic("app: RegisterProvider called twice for provider " + name)
+	}
+	providers[name] = provider
+}
+
+// RegisterDecorator makes a decorator available by name. It panics if
+// decorator is nil or name is already registered.
+func RegisterDecorator(name string, decorator Decorator) {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	if decorator == nil {
+		panic("app: RegisterDecorator decorator is nil")
+	}
+	if _, dup := decorators[name]; dup {
+		panic("app: RegisterDecorator called twice for decorator " + name)
+	}
+	decorators[name] = decorator
+}
+
+// Providers lists the registered providers in sorted order
+func Providers() []string {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewMethod builds the payment method of cfg.Provider, DEFAULT_PROVIDER
+// when it is empty, wrapped in cfg.Decorators with the first one
+// innermost
+func NewMethod(cfg Config) (payment.PaymentMethod, error) {
+	name := cfg.Provider
+	if name == "" {
+		name = DEFAULT_PROVIDER
+	}
+	factoryMu.RLock()
+	provider, ok := providers[name]
+	wrappers := make([]Decorator, len(cfg.Decorators))
+	var unknown []string
+	for i, d := range cfg.Decorators {
+		if wrappers[i] = decorators[d]; wrappers[i] == nil {
+			unknown = append(unknown, d)
+		}
+	}
+	factoryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDecorator, strings.Join(unknown, ", "))
+	}
+
+	method, err := provider(cfg, cfg.client())
+	if err != nil {
+		return nil, err
+	}
+	for _, wrap := range wrappers {
+		method = wrap(method, cfg)
+	}
+	return method, nil
+}
+
+// fallback refers to providers, so it cannot be in their initializer
+func init() {
+	providers["fallback"] = fallback
+}
+
+// fallback chains the providers of cfg.Fallback, DEFAULT_FALLBACK when it
+// is empty, trying each in turn while payments are declined or a method is
+// unavailable
+func fallback(cfg Config, client *http.Client) (payment.PaymentMethod, error) {
+	names := list(cfg.Fallback)
+	if len(names) == 0 {
+		names = list(DEFAULT_FALLBACK)
+	}
+	var chain resilience.Fallback
+	for _, name := range names {
+		factoryMu.RLock()
+		provider, ok := providers[name]
+		factoryMu.RUnlock()
+		if !ok || name == "fallback" {
+			return nil, fmt.Errorf("%w: %q in fallback", ErrUnknownProvider, name)
+		}
+		method, err := provider(cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		chain.Methods = append(chain.Methods, method)
+	}
+	return chain, nil
+}
+
+// settings are the configuration keys and where they go
+func (c *Config) settings() map[string]*string {
+	return map[string]*string{
+		"provider":              &c.Provider,
+		"fallback":              &c.Fallback,
+		"stripe_url":            &c.StripeURL,
+		"stripe_key":            &c.StripeKey,
+		"stripe_payment_method": &c.StripePaymentMethod,
+		"paypal_url":            &c.PayPalURL,
+		"paypal_client_id":      &c.PayPalClientID,
+		"paypal_client_secret":  &c.PayPalClientSecret,
+		"secrets":               &c.Secrets,
+		"secrets_dir":           &c.SecretsDir,
+		"notifier":              &c.Notifier,
+		"smtp_addr":             &c.SMTPAddr,
+		"smtp_user":             &c.SMTPUser,
+		"smtp_password":         &c.SMTPPassword,
+		"mail_from":             &c.MailFrom,
+		"mail_to":               &c.MailTo,
+		"sms_url":               &c.SMSURL,
+		"sms_account_sid":       &c.SMSAccountSID,
+		"sms_auth_token":        &c.SMSAuthToken,
+		"sms_from":              &c.SMSFrom,
+		"sms_to":                &c.SMSTo,
+		"log":                   &c.Log,
+		"store":                 &c.Store,
+		"store_path":            &c.StorePath,
+		"ids":                   &c.IDs,
+		"fraud":                 &c.Fraud,
+		"fraud_max_amount":      &c.FraudMaxAmount,
+	}
+}
+
+// set stores value under key, decorators being a comma separated list
+func (c *Config) set(key, value string) error {
+	if key == "decorators" {
+		c.Decorators = list(value)
+		return nil
+	}
+	field, ok := c.settings()[key]
+	if !ok {
+		return fmt.Errorf("%w: unknown key %q", ErrBadConfig, key)
+	}
+	*field = value
+	return nil
+}
+
+// ParseConfig reads key=value lines such as provider=paypal. Blank lines
+// and lines starting with # are skipped.
+func ParseConfig(text string) (Config, error) {
+	var cfg Config
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("%w: line %d: missing =", ErrBadConfig, n)
+		}
+		if err := cfg.set(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return Config{}, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return cfg, scanner.Err()
+}
+
+// ConfigFromEnv reads every key from its environment variable, ENV_PREFIX
+// followed by the key in upper case. Pass os.Getenv as getenv.
+func ConfigFromEnv(getenv func(key string) string) Config {
+	var cfg Config
+	keys := []string{"decorators"}
+	for key := range cfg.settings() {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		if value := getenv(ENV_PREFIX + strings.ToUpper(key)); value != "" {
+			cfg.set(key, value)
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/imrancluster/go-solid/5-DIP/fraud"
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+// ErrUnknownFraudChecker is returned for fraud checkers NewFraudChecker
+// cannot build
+var ErrUnknownFraudChecker = errors.New("app: unknown fraud checker")
+
+// NewFraudChecker builds the fraud checker named by cfg.Fraud: rules,
+// which rejects payments over cfg.FraudMaxAmount and too many payments in
+// a row. Without a name payments are not vetted and it returns nil.
+func NewFraudChecker(cfg Config) (payment.FraudChecker, error) {
+	switch cfg.Fraud {
+	case "":
+		return nil, nil
+	case "rules":
+		limit := fraud.DEFAULT_MAX_AMOUNT
+		if cfg.FraudMaxAmount != "" {
+			var err error
+			if limit, err = strconv.ParseFloat(cfg.FraudMaxAmount, 64); err != nil || limit <= 0 {
+				return nil, fmt.Errorf("%w: fraud_max_amount %q is not a positive number", ErrBadConfig, cfg.FraudMaxAmount)
+			}
+		}
+		return fraud.Rules{fraud.MaxAmount(limit), &fraud.Velocity{}}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFraudChecker, cfg.Fraud)
+}
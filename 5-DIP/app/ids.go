@@ -0,0 +1,27 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/id"
+)
+
+// ErrUnknownIDs is returned for ID generators NewIDs cannot build
+var ErrUnknownIDs = errors.New("app: unknown ID generator")
+
+// NewIDs builds the generator named by cfg.IDs: uuid, ulid or sequential.
+// Without a name it returns nil and the processor falls back to UUIDs.
+func NewIDs(cfg Config) (id.Generator, error) {
+	switch cfg.IDs {
+	case "":
+		return nil, nil
+	case "uuid":
+		return id.UUID{}, nil
+	case "ulid":
+		return id.ULID{}, nil
+	case "sequential":
+		return &id.Sequential{Prefix: "tx"}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownIDs, cfg.IDs)
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/imrancluster/go-solid/5-DIP/logging"
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+// ErrUnknownLogFormat is returned for log formats NewLogger cannot build
+var ErrUnknownLogFormat = errors.New("app: unknown log format")
+
+// NewLogger builds the logger named by cfg.Log: text or json, written to
+// cfg.LogOutput. Without a name nothing is logged and it returns nil.
+func NewLogger(cfg Config) (payment.Logger, error) {
+	out := cfg.LogOutput
+	if out == nil {
+		out = os.Stdout
+	}
+	switch cfg.Log {
+	case "":
+		return nil, nil
+	case "text":
+		return logging.New(slog.NewTextHandler(out, nil)), nil
+	case "json":
+		return logging.New(slog.NewJSONHandler(out, nil)), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownLogFormat, cfg.Log)
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/imrancluster/go-solid/5-DIP/notify"
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+// ErrUnknownNotifier is returned for notifiers NewNotifier cannot build
+var ErrUnknownNotifier = errors.New("app: unknown notifier")
+
+// NewNotifier builds the receipt notifier named by cfg.Notifier: console,
+// email or sms. Without a name receipts are not sent and it returns nil.
+func NewNotifier(cfg Config) (payment.Notifier, error) {
+	switch cfg.Notifier {
+	case "":
+		return nil, nil
+	case "console":
+		return notify.Console{}, nil
+	case "email":
+		var auth smtp.Auth
+		if cfg.SMTPUser != "" {
+			host, _, _ := net.SplitHostPort(cfg.SMTPAddr)
+			auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, host)
+		}
+		return notify.Email{Addr: cfg.SMTPAddr, Auth: auth, From: cfg.MailFrom, To: list(cfg.MailTo)}, nil
+	case "sms":
+		return notify.SMS{
+			BaseURL:    cfg.SMSURL,
+			AccountSID: cfg.SMSAccountSID,
+			AuthToken:  cfg.SMSAuthToken,
+			From:       cfg.SMSFrom,
+			To:         cfg.SMSTo,
+			Client:     cfg.client(),
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownNotifier, cfg.Notifier)
+}
+
+// list splits a comma separated list and drops empty items
+func list(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
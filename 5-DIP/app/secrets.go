@@ -0,0 +1,41 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/5-DIP/gateway"
+	"github.com/imrancluster/go-solid/5-DIP/secrets"
+)
+
+// ErrUnknownSecrets is returned for secret sources NewSecrets cannot build
+var ErrUnknownSecrets = errors.New("app: unknown secrets source")
+
+// NewSecrets builds the source of gateway credentials named by
+// cfg.Secrets: env, which reads PAYMENT_STRIPE_KEY and the like, or file,
+// which reads them from cfg.SecretsDir. Without a name the credentials
+// are the ones in cfg.
+func NewSecrets(cfg Config) (gateway.Secrets, error) {
+	switch cfg.Secrets {
+	case "":
+		memory := secrets.Memory{}
+		for name, value := range map[string]string{
+			gateway.STRIPE_KEY:           cfg.StripeKey,
+			gateway.PAYPAL_CLIENT_ID:     cfg.PayPalClientID,
+			gateway.PAYPAL_CLIENT_SECRET: cfg.PayPalClientSecret,
+		} {
+			if value != "" {
+				memory[name] = value
+			}
+		}
+		return memory, nil
+	case "env":
+		return secrets.Env{Prefix: ENV_PREFIX}, nil
+	case "file":
+		if cfg.SecretsDir == "" {
+			return nil, fmt.Errorf("%w: file secrets need secrets_dir", ErrBadConfig)
+		}
+		return secrets.File{Dir: cfg.SecretsDir}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownSecrets, cfg.Secrets)
+}
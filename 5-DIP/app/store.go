@@ -0,0 +1,39 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/5-DIP/store"
+)
+
+// ErrUnknownStore is returned for transaction stores NewStore cannot build
+var ErrUnknownStore = errors.New("app: unknown transaction store")
+
+// NewStore builds the transaction store named by cfg.Store: memory, file
+// at cfg.StorePath or sql in cfg.DB. Without a name transactions are not
+// kept and it returns nil.
+func NewStore(cfg Config) (payment.TransactionStore, error) {
+	switch cfg.Store {
+	case "":
+		return nil, nil
+	case "memory":
+		return &store.Memory{}, nil
+	case "file":
+		if cfg.StorePath == "" {
+			return nil, fmt.Errorf("%w: file store needs store_path", ErrBadConfig)
+		}
+		return &store.File{Path: cfg.StorePath}, nil
+	case "sql":
+		if cfg.DB == nil {
+			return nil, fmt.Errorf("%w: sql store needs a database", ErrBadConfig)
+		}
+		s := store.SQL{DB: cfg.DB}
+		if err := s.CreateTable(); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownStore, cfg.Store)
+}
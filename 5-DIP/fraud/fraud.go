@@ -0,0 +1,93 @@
+// Package fraud vets payments with rules. Each rule, and a set of them,
+// implements payment.FraudChecker, so the processor consults them without
+// knowing which rules there are.
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/clock"
+)
+
+const (
+	DEFAULT_MAX_AMOUNT = 10000.0
+	DEFAULT_VELOCITY   = 10
+	DEFAULT_WINDOW     = time.Minute
+)
+
+var (
+	_ payment.FraudChecker = Rules{}
+	_ payment.FraudChecker = MaxAmount(0)
+	_ payment.FraudChecker = (*Velocity)(nil)
+)
+
+// Rules lets a payment through only when every rule does, checking them
+// in order and stopping at the first one that rejects it
+type Rules []payment.FraudChecker
+
+func (r Rules) Check(ctx context.Context, amount float64) error {
+	for _, rule := range r {
+		if err := rule.Check(ctx, amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxAmount rejects payments over the amount
+type MaxAmount float64
+
+func (m MaxAmount) Check(_ context.Context, amount float64) error {
+	if amount > float64(m) {
+		return fmt.Errorf("%w: %.2f is over the limit of %.2f", payment.ErrSuspectedFraud, amount, float64(m))
+	}
+	return nil
+}
+
+// Velocity rejects payments once it let Max through within Window, like a
+// card being tried over and over. Every payment it lets through counts,
+// even one a later rule or the payment method rejects; the ones it rejects
+// itself do not. It is safe for concurrent use; the zero value allows
+// DEFAULT_VELOCITY payments per DEFAULT_WINDOW.
+type Velocity struct {
+	// Max defaults to DEFAULT_VELOCITY
+	Max int
+	// Window defaults to DEFAULT_WINDOW
+	Window time.Duration
+	// Clock defaults to the real clock
+	Clock clock.Clock
+
+	mu     sync.Mutex
+	recent []time.Time
+}
+
+func (v *Velocity) Check(_ context.Context, _ float64) error {
+	max := v.Max
+	if max <= 0 {
+		max = DEFAULT_VELOCITY
+	}
+	window := v.Window
+	if window <= 0 {
+		window = DEFAULT_WINDOW
+	}
+	now := clock.Or(v.Clock).Now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	kept := v.recent[:0]
+	for _, t := range v.recent {
+		if now.Sub(t) < window {
+			kept = append(kept, t)
+		}
+	}
+	v.recent = kept
+	if len(v.recent) >= max {
+		return fmt.Errorf("%w: more than %d payments in %s", payment.ErrSuspectedFraud, max, window)
+	}
+	v.recent = append(v.recent, now)
+	return nil
+}
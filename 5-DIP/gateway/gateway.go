@@ -0,0 +1,285 @@
+// Package gateway adapts remote payment APIs to payment.PaymentMethod.
+// The processor only ever sees a PaymentMethod: the HTTP calls and the
+// shape of each provider's API stay in here, while the credentials come
+// from whatever Secrets source the adapters are handed.
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+const (
+	// STRIPE_URL is the Stripe API, test mode is chosen by the key
+	STRIPE_URL = "https://api.stripe.com"
+	// PAYPAL_SANDBOX_URL is the PayPal sandbox API
+	PAYPAL_SANDBOX_URL = "https://api-m.sandbox.paypal.com"
+	// STRIPE_TEST_CARD is the payment method of Stripe's test Visa card
+	STRIPE_TEST_CARD = "pm_card_visa"
+	// CURRENCY is what every payment of the example is in
+	CURRENCY = "usd"
+)
+
+// The names the adapters look their credentials up by
+const (
+	// STRIPE_KEY is the Stripe secret key, sk_test_ keys use test mode
+	STRIPE_KEY = "stripe_key"
+	// PAYPAL_CLIENT_ID and PAYPAL_CLIENT_SECRET are the PayPal app's
+	// OAuth client credentials
+	PAYPAL_CLIENT_ID     = "paypal_client_id"
+	PAYPAL_CLIENT_SECRET = "paypal_client_secret"
+)
+
+// ErrIncomplete is a payment the provider accepted but did not complete,
+// such as one waiting for the buyer to authenticate
+var ErrIncomplete = errors.New("gateway: payment not completed")
+
+// Secrets hands out credentials by name. The adapters own this
+// abstraction and ask for their keys on every payment, so where keys are
+// kept, and rotating them, is decided outside this package.
+type Secrets interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// Stripe takes card payments through the Stripe payment intents API
+type Stripe struct {
+	// BaseURL defaults to STRIPE_URL
+	BaseURL string
+	// Secrets holds the STRIPE_KEY
+	Secrets Secrets
+	// PaymentMethod is the card to charge, it defaults to STRIPE_TEST_CARD
+	PaymentMethod string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// stripeIntent is the part of a payment intent the adapter reads
+type stripeIntent struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+var (
+	_ payment.PaymentMethod = Stripe{}
+	_ payment.PaymentMethod = PayPal{}
+)
+
+// Pay reports a declined card as payment.ErrDeclined and a Stripe that
+// cannot be reached as payment.ErrUnavailable. The transaction references
+// the payment intent.
+func (s Stripe) Pay(ctx context.Context, amount float64) (payment.Transaction, error) {
+	method := s.PaymentMethod
+	if method == "" {
+		method = STRIPE_TEST_CARD
+	}
+	form := url.Values{
+		"amount":                 {strconv.FormatInt(cents(amount), 10)},
+		"currency":               {CURRENCY},
+		"confirm":                {"true"},
+		"payment_method":         {method},
+		"payment_method_types[]": {"card"},
+	}
+	key, err := secret(ctx, s.Secrets, STRIPE_KEY)
+	if err != nil {
+		return failed("Stripe", amount, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base(s.BaseURL, STRIPE_URL)+"/v1/payment_intents", strings.NewReader(form.Encode()))
+	if err != nil {
+		return failed("Stripe", amount, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var intent stripeIntent
+	if err := call(s.Client, req, &intent); err != nil {
+		if intent.Error != nil {
+			err = fmt.Errorf("%w: %s", err, intent.Error.Message)
+		}
+		return failed("Stripe", amount, err)
+	}
+	if intent.Status != "succeeded" {
+		return failed("Stripe", amount, fmt.Errorf("%w: payment intent %s is %s", ErrIncomplete, intent.ID, intent.Status))
+	}
+	return payment.Transaction{Amount: amount, Method: "Stripe", Reference: intent.ID, Paid: true}, nil
+}
+
+// PayPal takes payments through the PayPal orders API: it creates an order
+// and captures it, authenticating with OAuth client credentials on every
+// payment
+type PayPal struct {
+	// BaseURL defaults to PAYPAL_SANDBOX_URL
+	BaseURL string
+	// Secrets holds the PAYPAL_CLIENT_ID and PAYPAL_CLIENT_SECRET
+	Secrets Secrets
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// paypalOrder is the part of an order, or of an error, the adapter reads
+type paypalOrder struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Pay reports a PayPal that cannot be reached as payment.ErrUnavailable
+// and an order that waits for the buyer as ErrIncomplete. The transaction
+// references the order.
+func (p PayPal) Pay(ctx context.Context, amount float64) (payment.Transaction, error) {
+	token, err := p.token(ctx)
+	if err != nil {
+		return failed("PayPal", amount, err)
+	}
+	order, err := p.post(ctx, token, "/v2/checkout/orders", map[string]any{
+		"intent": "CAPTURE",
+		"purchase_units": []map[string]any{{
+			"amount": map[string]string{
+				"currency_code": strings.ToUpper(CURRENCY),
+				"value":         strconv.FormatFloat(float64(cents(amount))/100, 'f', 2, 64),
+			},
+		}},
+	})
+	if err != nil {
+		return failed("PayPal", amount, fmt.Errorf("creating order: %w", err))
+	}
+	if order.Status != "CREATED" && order.Status != "APPROVED" {
+		return failed("PayPal", amount, fmt.Errorf("%w: order %s is %s", ErrIncomplete, order.ID, order.Status))
+	}
+	captured, err := p.post(ctx, token, "/v2/checkout/orders/"+url.PathEscape(order.ID)+"/capture", map[string]any{})
+	if err != nil {
+		return failed("PayPal", amount, fmt.Errorf("capturing order %s: %w", order.ID, err))
+	}
+	if captured.Status != "COMPLETED" {
+		return failed("PayPal", amount, fmt.Errorf("%w: order %s is %s", ErrIncomplete, order.ID, captured.Status))
+	}
+	return payment.Transaction{Amount: amount, Method: "PayPal", Reference: order.ID, Paid: true}, nil
+}
+
+// post sends body as JSON to path of the orders API and returns the order
+// it answers with
+func (p PayPal) post(ctx context.Context, token, path string, body any) (paypalOrder, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return paypalOrder{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base(p.BaseURL, PAYPAL_SANDBOX_URL)+path, bytes.NewReader(data))
+	if err != nil {
+		return paypalOrder{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	var order paypalOrder
+	if err := call(p.Client, req, &order); err != nil {
+		if order.Message != "" {
+			err = fmt.Errorf("%w: %s", err, order.Message)
+		}
+		return paypalOrder{}, err
+	}
+	return order, nil
+}
+
+// token gets an OAuth access token for the client credentials
+func (p PayPal) token(ctx context.Context) (string, error) {
+	clientID, err := secret(ctx, p.Secrets, PAYPAL_CLIENT_ID)
+	if err != nil {
+		return "", err
+	}
+	clientSecret, err := secret(ctx, p.Secrets, PAYPAL_CLIENT_SECRET)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base(p.BaseURL, PAYPAL_SANDBOX_URL)+"/v1/oauth2/token", strings.NewReader("grant_type=client_credentials"))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(clientID, clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Description string `json:"error_description"`
+	}
+	if err := call(p.Client, req, &token); err != nil {
+		if token.Description != "" {
+			err = fmt.Errorf("%w: %s", err, token.Description)
+		}
+		return "", fmt.Errorf("authenticating: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// secret looks name up in secrets
+func secret(ctx context.Context, secrets Secrets, name string) (string, error) {
+	if secrets == nil {
+		return "", fmt.Errorf("reading %s: no secrets", name)
+	}
+	value, err := secrets.Secret(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// call sends req and decodes the JSON response into v, error responses
+// included, so callers can read the provider's reason. It fails for any
+// status but 2xx, with payment.ErrDeclined for 402 Payment Required and
+// payment.ErrUnavailable when the provider cannot be reached or fails
+// itself. A canceled request fails with the error of its context.
+func call(client *http.Client, req *http.Request, v any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("%w: %v", payment.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	decodeErr := json.Unmarshal(body, v)
+	switch {
+	case resp.StatusCode == http.StatusPaymentRequired:
+		return fmt.Errorf("%w: gateway: %s", payment.ErrDeclined, resp.Status)
+	case resp.StatusCode/100 == 5:
+		return fmt.Errorf("%w: gateway: %s", payment.ErrUnavailable, resp.Status)
+	case resp.StatusCode/100 != 2:
+		return fmt.Errorf("gateway: %s", resp.Status)
+	}
+	return decodeErr
+}
+
+// failed reports a payment the provider did not take
+func failed(provider string, amount float64, err error) (payment.Transaction, error) {
+	return payment.Transaction{}, &payment.Failure{Method: provider, Amount: amount, Reason: err}
+}
+
+func base(baseURL, fallback string) string {
+	if baseURL == "" {
+		return fallback
+	}
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+// cents is amount in the smallest unit of CURRENCY
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
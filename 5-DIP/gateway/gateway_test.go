@@ -0,0 +1,232 @@
+package gateway_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/gateway"
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/5-DIP/secrets"
+)
+
+// SANDBOX_SECRETS are the credentials the sandboxes of these tests accept
+var SANDBOX_SECRETS = secrets.Memory{
+	gateway.STRIPE_KEY:           "sk_test_sandbox",
+	gateway.PAYPAL_CLIENT_ID:     "sandbox-client",
+	gateway.PAYPAL_CLIENT_SECRET: "sandbox-secret",
+}
+
+// newPayPal returns a PayPal adapter talking to sandbox
+func newPayPal(t *testing.T, sandbox *gateway.PayPalSandbox) gateway.PayPal {
+	t.Helper()
+	sandbox.ClientID, sandbox.ClientSecret = SANDBOX_SECRETS[gateway.PAYPAL_CLIENT_ID], SANDBOX_SECRETS[gateway.PAYPAL_CLIENT_SECRET]
+	server := httptest.NewServer(sandbox)
+	t.Cleanup(server.Close)
+	return gateway.PayPal{BaseURL: server.URL, Secrets: SANDBOX_SECRETS, Client: server.Client()}
+}
+
+// TestPayPalCreatesAndCaptures makes sure a payment is an order that is
+// created and then captured
+func TestPayPalCreatesAndCaptures(t *testing.T) {
+	paypal := newPayPal(t, &gateway.PayPalSandbox{})
+	tx, err := paypal.Pay(context.Background(), 25.5)
+	if err != nil {
+		t.Fatalf("Pay returned error: %v", err)
+	}
+	if !tx.Paid || tx.Amount != 25.5 || tx.Method != "PayPal" || !strings.HasPrefix(tx.Reference, "SANDBOX-ORDER-") {
+		t.Errorf("Pay = %+v, want a paid PayPal transaction referencing the order", tx)
+	}
+}
+
+// TestPayPalPayerAction makes sure an order waiting for the buyer is
+// reported as incomplete and never captured
+func TestPayPalPayerAction(t *testing.T) {
+	paypal := newPayPal(t, &gateway.PayPalSandbox{PayerAction: true})
+	_, err := paypal.Pay(context.Background(), 25.5)
+	if !errors.Is(err, gateway.ErrIncomplete) {
+		t.Fatalf("Pay error = %v, want %v", err, gateway.ErrIncomplete)
+	}
+	if !strings.Contains(err.Error(), "PAYER_ACTION_REQUIRED") {
+		t.Errorf("Pay error = %v, want it to name the status of the order", err)
+	}
+}
+
+func TestPayPalBadCredentials(t *testing.T) {
+	paypal := newPayPal(t, &gateway.PayPalSandbox{})
+	paypal.Secrets = secrets.Memory{gateway.PAYPAL_CLIENT_ID: "sandbox-client", gateway.PAYPAL_CLIENT_SECRET: "wrong"}
+	_, err := paypal.Pay(context.Background(), 25.5)
+	if err == nil || !strings.Contains(err.Error(), "authenticating") {
+		t.Errorf("Pay with a wrong client secret error = %v, want it to fail authenticating", err)
+	}
+}
+
+// newStripe returns a Stripe adapter talking to handler
+func newStripe(t *testing.T, handler http.Handler) gateway.Stripe {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return gateway.Stripe{BaseURL: server.URL, Secrets: SANDBOX_SECRETS, Client: server.Client()}
+}
+
+func TestStripePays(t *testing.T) {
+	stripe := newStripe(t, &gateway.StripeSandbox{})
+	tx, err := stripe.Pay(context.Background(), 12.34)
+	if err != nil {
+		t.Fatalf("Pay returned error: %v", err)
+	}
+	if !tx.Paid || tx.Amount != 12.34 || tx.Method != "Stripe" || !strings.HasPrefix(tx.Reference, "pi_sandbox_") {
+		t.Errorf("Pay = %+v, want a paid Stripe transaction referencing the payment intent", tx)
+	}
+}
+
+func TestStripeDeclined(t *testing.T) {
+	stripe := newStripe(t, &gateway.StripeSandbox{})
+	stripe.PaymentMethod = gateway.STRIPE_DECLINED_CARD
+	_, err := stripe.Pay(context.Background(), 12.34)
+	if !errors.Is(err, payment.ErrDeclined) {
+		t.Fatalf("Pay error = %v, want %v", err, payment.ErrDeclined)
+	}
+	if errors.Is(err, payment.ErrUnavailable) {
+		t.Errorf("Pay error = %v, a declined card is not an unavailable gateway", err)
+	}
+	if !strings.Contains(err.Error(), "Your card was declined.") {
+		t.Errorf("Pay error = %v, want the reason Stripe gave", err)
+	}
+}
+
+// TestServerErrors makes sure a gateway failing itself is reported as
+// unavailable, so callers can retry it or fall back to another
+func TestServerErrors(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable} {
+		// PayPal hands out a token and then fails to create the order
+		sandbox := &gateway.PayPalSandbox{ClientID: SANDBOX_SECRETS[gateway.PAYPAL_CLIENT_ID], ClientSecret: SANDBOX_SECRETS[gateway.PAYPAL_CLIENT_SECRET]}
+		server := httptest.NewServer(failing(sandbox, "/v2/checkout/orders", status))
+		t.Cleanup(server.Close)
+		paypal := gateway.PayPal{BaseURL: server.URL, Secrets: SANDBOX_SECRETS, Client: server.Client()}
+		stripe := newStripe(t, failing(&gateway.StripeSandbox{}, "/v1/payment_intents", status))
+		for name, method := range map[string]payment.PaymentMethod{"Stripe": stripe, "PayPal": paypal} {
+			_, err := method.Pay(context.Background(), 10)
+			if !errors.Is(err, payment.ErrUnavailable) {
+				t.Errorf("%s Pay with status %d error = %v, want %v", name, status, err, payment.ErrUnavailable)
+			}
+			if errors.Is(err, payment.ErrDeclined) {
+				t.Errorf("%s Pay with status %d error = %v, a failing gateway declines nothing", name, status, err)
+			}
+		}
+	}
+}
+
+// failing answers requests to path with status and passes the others on
+// to sandbox
+func failing(sandbox http.Handler, path string, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			sandbox.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error": {"message": "try again later"}}`))
+	})
+}
+
+// TestTimeout makes sure a gateway that does not answer within the
+// timeout of the client is reported as unavailable
+func TestTimeout(t *testing.T) {
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	stripe := gateway.Stripe{BaseURL: hanging(t), Secrets: SANDBOX_SECRETS, Client: client}
+	paypal := gateway.PayPal{BaseURL: stripe.BaseURL, Secrets: SANDBOX_SECRETS, Client: client}
+
+	for name, method := range map[string]payment.PaymentMethod{"Stripe": stripe, "PayPal": paypal} {
+		_, err := method.Pay(context.Background(), 10)
+		if !errors.Is(err, payment.ErrUnavailable) {
+			t.Errorf("%s Pay error = %v, want %v", name, err, payment.ErrUnavailable)
+		}
+	}
+
+	// a deadline of the caller ends the payment the same way, but is the
+	// caller's error
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stripe.Client = nil
+	if _, err := stripe.Pay(ctx, 10); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Pay past the deadline of its context error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// hanging starts a server that answers no request until the test ends and
+// returns its URL
+func hanging(t *testing.T) string {
+	released := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-released:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(released) })
+	return server.URL
+}
+
+// TestCancelMidRequest makes sure a caller giving up while the gateway
+// works on the payment ends it at once with the caller's error, which no
+// retry or circuit breaker mistakes for a gateway that is down
+func TestCancelMidRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		sandbox http.Handler
+		// path is the request the gateway stalls on
+		path   string
+		method func(url string, client *http.Client) payment.PaymentMethod
+	}{
+		{"Stripe", &gateway.StripeSandbox{}, "/v1/payment_intents", func(url string, client *http.Client) payment.PaymentMethod {
+			return gateway.Stripe{BaseURL: url, Secrets: SANDBOX_SECRETS, Client: client}
+		}},
+		{"PayPal", &gateway.PayPalSandbox{ClientID: SANDBOX_SECRETS[gateway.PAYPAL_CLIENT_ID], ClientSecret: SANDBOX_SECRETS[gateway.PAYPAL_CLIENT_SECRET]}, "/v2/checkout/orders", func(url string, client *http.Client) payment.PaymentMethod {
+			return gateway.PayPal{BaseURL: url, Secrets: SANDBOX_SECRETS, Client: client}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arrived := make(chan struct{})
+			released := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					tt.sandbox.ServeHTTP(w, r)
+					return
+				}
+				close(arrived)
+				select {
+				case <-released:
+				case <-r.Context().Done():
+				}
+			}))
+			t.Cleanup(server.Close)
+			t.Cleanup(func() { close(released) })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				<-arrived
+				cancel()
+			}()
+			start := time.Now()
+			_, err := tt.method(server.URL, server.Client()).Pay(ctx, 10)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("Pay canceled mid-request error = %v, want %v", err, context.Canceled)
+			}
+			if errors.Is(err, payment.ErrUnavailable) {
+				t.Errorf("Pay canceled mid-request error = %v, the gateway is not unavailable", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Pay returned %s after it was canceled, want it to return at once", elapsed)
+			}
+		})
+	}
+}
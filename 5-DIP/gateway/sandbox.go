@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// STRIPE_DECLINED_CARD is the test card the Stripe sandbox declines
+	STRIPE_DECLINED_CARD = "pm_card_chargeDeclined"
+	// SANDBOX_TOKEN is the access token the PayPal sandbox hands out
+	SANDBOX_TOKEN = "sandbox-access-token"
+)
+
+// StripeSandbox answers the payment intents API like Stripe in test mode,
+// for demos and tests that run without network access. It accepts any
+// sk_test_ key and declines STRIPE_DECLINED_CARD.
+type StripeSandbox struct {
+	mu  sync.Mutex
+	seq int
+}
+
+func (s *StripeSandbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/payment_intents" {
+		stripeError(w, http.StatusNotFound, "Unrecognized request URL")
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer sk_test_") {
+		stripeError(w, http.StatusUnauthorized, "Invalid API Key provided")
+		return
+	}
+	amount, err := strconv.ParseInt(r.PostFormValue("amount"), 10, 64)
+	if err != nil || amount <= 0 {
+		stripeError(w, http.StatusBadRequest, "Invalid positive integer")
+		return
+	}
+	if r.PostFormValue("payment_method") == STRIPE_DECLINED_CARD {
+		stripeError(w, http.StatusPaymentRequired, "Your card was declined.")
+		return
+	}
+	s.mu.Lock()
+	s.seq++
+	id := fmt.Sprintf("pi_sandbox_%d", s.seq)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "status": "succeeded", "amount": amount})
+}
+
+func stripeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]string{"message": message}})
+}
+
+// PayPalSandbox answers the OAuth token and orders APIs like the PayPal
+// sandbox. Orders are created as if the buyer approved them at once, so
+// they can be captured right away.
+type PayPalSandbox struct {
+	ClientID     string
+	ClientSecret string
+	// PayerAction makes every order wait for the buyer, with the status
+	// PAYER_ACTION_REQUIRED, instead of letting it be captured
+	PayerAction bool
+
+	mu     sync.Mutex
+	seq    int
+	orders map[string]string
+}
+
+func (p *PayPalSandbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && r.URL.Path == "/v1/oauth2/token" {
+		if id, secret, ok := r.BasicAuth(); !ok || id != p.ClientID || secret != p.ClientSecret {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client", "error_description": "Client Authentication failed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"access_token": SANDBOX_TOKEN, "token_type": "Bearer"})
+		return
+	}
+	id, capture := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v2/checkout/orders/"), "/capture")
+	switch {
+	case r.Method != http.MethodPost || (r.URL.Path != "/v2/checkout/orders" && !capture):
+		paypalError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "The specified resource does not exist.")
+	case r.Header.Get("Authorization") != "Bearer "+SANDBOX_TOKEN:
+		paypalError(w, http.StatusUnauthorized, "AUTHENTICATION_FAILURE", "Authentication failed due to invalid authentication credentials.")
+	case capture:
+		p.capture(w, id)
+	default:
+		p.create(w, r)
+	}
+}
+
+// create answers POST /v2/checkout/orders
+func (p *PayPalSandbox) create(w http.ResponseWriter, r *http.Request) {
+	var order struct {
+		PurchaseUnits []struct {
+			Amount struct {
+				Value string `json:"value"`
+			} `json:"amount"`
+		} `json:"purchase_units"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&order)
+	if err != nil || len(order.PurchaseUnits) == 0 {
+		paypalError(w, http.StatusBadRequest, "INVALID_REQUEST", "Request is not well-formed.")
+		return
+	}
+	if value, err := strconv.ParseFloat(order.PurchaseUnits[0].Amount.Value, 64); err != nil || value <= 0 {
+		paypalError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The amount must be positive.")
+		return
+	}
+	status := "CREATED"
+	if p.PayerAction {
+		status = "PAYER_ACTION_REQUIRED"
+	}
+	p.mu.Lock()
+	p.seq++
+	id := fmt.Sprintf("SANDBOX-ORDER-%d", p.seq)
+	if p.orders == nil {
+		p.orders = make(map[string]string)
+	}
+	p.orders[id] = status
+	p.mu.Unlock()
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id, "status": status})
+}
+
+// capture answers POST /v2/checkout/orders/{id}/capture
+func (p *PayPalSandbox) capture(w http.ResponseWriter, id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch p.orders[id] {
+	case "":
+		paypalError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "The specified resource does not exist.")
+	case "PAYER_ACTION_REQUIRED":
+		paypalError(w, http.StatusUnprocessableEntity, "ORDER_NOT_APPROVED", "Payer has not yet approved the Order for payment.")
+	case "COMPLETED":
+		paypalError(w, http.StatusUnprocessableEntity, "ORDER_ALREADY_CAPTURED", "Order already captured.")
+	default:
+		p.orders[id] = "COMPLETED"
+		writeJSON(w, http.StatusCreated, map[string]string{"id": id, "status": "COMPLETED"})
+	}
+}
+
+func paypalError(w http.ResponseWriter, status int, name, message string) {
+	writeJSON(w, status, map[string]string{"name": name, "message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
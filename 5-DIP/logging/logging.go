@@ -0,0 +1,40 @@
+// Package logging adapts log/slog to payment.Logger. The processor only
+// knows its own small Logger interface; which handler formats the entries
+// and where they go is decided here and in the composition root.
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+var _ payment.Logger = Slog{}
+
+// Slog logs through a slog.Logger
+type Slog struct {
+	// Logger defaults to slog.Default()
+	Logger *slog.Logger
+}
+
+// New returns a Slog logging to h
+func New(h slog.Handler) Slog {
+	return Slog{Logger: slog.New(h)}
+}
+
+func (s Slog) Log(level payment.Level, msg string, args ...any) {
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Log(context.Background(), slogLevel(level), msg, args...)
+}
+
+// slogLevel maps level to slog, unknown levels are logged as info
+func slogLevel(level payment.Level) slog.Level {
+	if level == payment.LevelError {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
@@ -1,41 +1,124 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 
-// PaymentMethod interface (abstraction)
-type PaymentMethod interface {
-	Pay(amount float64) string
-}
+	"github.com/imrancluster/go-solid/5-DIP/api"
+	"github.com/imrancluster/go-solid/5-DIP/app"
+	"github.com/imrancluster/go-solid/5-DIP/gateway"
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/5-DIP/wiring"
+)
 
-// CreditCard struct (low-level module)
-type CreditCard struct{}
+func main() {
+	ctx := context.Background()
+	// Local sandboxes stand in for the providers' test APIs
+	stripe := httptest.NewServer(&gateway.StripeSandbox{})
+	defer stripe.Close()
+	paypal := httptest.NewServer(&gateway.PayPalSandbox{ClientID: "sandbox-client", ClientSecret: "sandbox-secret"})
+	defer paypal.Close()
 
-func (cc CreditCard) Pay(amount float64) string {
-	return fmt.Sprintf("Paid %f using Credit Card", amount)
-}
+	// Configuration picks the payment method, the composition root builds
+	// it and main only runs what it gets
+	cfg, err := app.ParseConfig(fmt.Sprintf(`
+stripe_url = %s
+stripe_key = sk_test_example
+paypal_url = %s
+paypal_client_id = sandbox-client
+paypal_client_secret = sandbox-secret
+notifier = console
+log = text
+decorators = retry, breaker
+`, stripe.URL, paypal.URL))
+	if err != nil {
+		fmt.Println("Reading configuration failed:", err)
+		return
+	}
+	for i, provider := range []string{"card", "paypal", "stripe", "paypal-api"} {
+		cfg.Provider = provider
+		a, err := app.Wire(cfg)
+		if err != nil {
+			fmt.Println("Wiring failed:", err)
+			continue
+		}
+		a.Processor.Process(ctx, float64(100*(i+1)))
+	}
 
-// PayPal struct (low-level module)
-type PayPal struct{}
+	// A fallback chain moves on when the card is declined
+	cfg.Provider, cfg.Fallback, cfg.StripePaymentMethod = "fallback", "stripe, paypal-api, bank", gateway.STRIPE_DECLINED_CARD
+	if a, err := app.Wire(cfg); err != nil {
+		fmt.Println("Wiring failed:", err)
+	} else {
+		a.Processor.Process(ctx, 150)
+	}
+	// Without one the caller branches on the typed failure
+	cfg.Provider = "stripe"
+	if a, err := app.Wire(cfg); err != nil {
+		fmt.Println("Wiring failed:", err)
+	} else if _, err := a.Processor.Process(ctx, 60); errors.Is(err, payment.ErrDeclined) {
+		fmt.Println("Card declined, asking for another one")
+	}
 
-func (pp PayPal) Pay(amount float64) string {
-	return fmt.Sprintf("Paid %f using PayPal", amount)
-}
+	// Fraud rules vet payments before the method sees them; the methods
+	// did not change to get them
+	cfg.Provider, cfg.Fraud, cfg.FraudMaxAmount = "card", "rules", "1000"
+	if a, err := app.Wire(cfg); err != nil {
+		fmt.Println("Wiring failed:", err)
+	} else if _, err := a.Processor.Process(ctx, 5000); errors.Is(err, payment.ErrSuspectedFraud) {
+		fmt.Println("Payment held for review:", err)
+	}
+	cfg.Fraud = ""
 
-// PaymentProcessor struct (high-level module)
-type PaymentProcessor struct {
-	Method PaymentMethod
-}
+	// The store keeps every transaction the processor handles, numbered
+	// so the run can be compared with the last one
+	cfg.Store, cfg.IDs = "memory", "sequential"
+	recorded, err := app.Wire(cfg)
+	if err != nil {
+		fmt.Println("Wiring failed:", err)
+		return
+	}
+	recorded.Processor.Process(ctx, 50)
+	recorded.Processor.Process(ctx, 75)
+	if transactions, err := recorded.Store.All(); err == nil {
+		fmt.Println("Recorded", len(transactions), "transactions")
+		for _, tx := range transactions {
+			fmt.Printf("  %s: %.2f using %s\n", tx.ID, tx.Amount, tx.Method)
+		}
+	}
 
-func (p PaymentProcessor) Process(amount float64) {
-	fmt.Println(p.Method.Pay(amount))
-}
+	// The HTTP API only knows the processor as an interface
+	server := httptest.NewServer(api.NewHandler(recorded.Processor))
+	defer server.Close()
+	resp, err := http.Post(server.URL+"/payments", "application/json", strings.NewReader(`{"amount": 90}`))
+	if err != nil {
+		fmt.Println("Posting payment failed:", err)
+		return
+	}
+	resp.Body.Close()
+	fmt.Println("POST /payments:", resp.Status)
 
-func main() {
-	// Process payment using Credit Card
-	processor := PaymentProcessor{Method: CreditCard{}}
-	processor.Process(100)
+	// The injector generated by google/wire builds the same graph
+	generated, err := wiring.InitializeApp(cfg)
+	if err != nil {
+		fmt.Println("Generated wiring failed:", err)
+		return
+	}
+	generated.Processor.Process(ctx, 25)
 
-	// Process payment using PayPal
-	processor = PaymentProcessor{Method: PayPal{}}
-	processor.Process(200)
+	// The environment works the same way:
+	// PAYMENT_PROVIDER=paypal PAYMENT_LOG=json go run ./5-DIP
+	// and gateway keys can be read from it too:
+	// PAYMENT_PROVIDER=stripe PAYMENT_SECRETS=env PAYMENT_STRIPE_KEY=sk_test_... go run ./5-DIP
+	a, err := app.Wire(app.ConfigFromEnv(os.Getenv))
+	if err != nil {
+		fmt.Println("Wiring from the environment failed:", err)
+		return
+	}
+	a.Processor.Process(ctx, 500)
 }
@@ -0,0 +1,117 @@
+// Package notify delivers payment receipts by email, SMS or to a console.
+// Each notifier implements payment.Notifier, so the processor sends
+// receipts without knowing about SMTP servers or SMS APIs.
+package notify
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+// TWILIO_URL is the API of the Twilio-style SMS provider
+const TWILIO_URL = "https://api.twilio.com"
+
+var (
+	_ payment.Notifier = Console{}
+	_ payment.Notifier = Email{}
+	_ payment.Notifier = SMS{}
+)
+
+// Console writes receipts to Out
+type Console struct {
+	// Out defaults to os.Stdout
+	Out io.Writer
+}
+
+func (c Console) Notify(receipt payment.Receipt) error {
+	out := c.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, err := fmt.Fprintf(out, "Receipt: %s\n", describe(receipt))
+	return err
+}
+
+// describe says how receipt was paid, such as "Paid 10.00 using PayPal (ORDER-1)"
+func describe(receipt payment.Receipt) string {
+	text := fmt.Sprintf("Paid %.2f using %s", receipt.Amount, receipt.Method)
+	if receipt.Reference != "" {
+		text += " (" + receipt.Reference + ")"
+	}
+	return text
+}
+
+// Email mails receipts through an SMTP server
+type Email struct {
+	// Addr is the host:port of the SMTP server
+	Addr string
+	// Auth may be nil for servers that need no authentication
+	Auth smtp.Auth
+	From string
+	To   []string
+	// Send defaults to smtp.SendMail
+	Send func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func (e Email) Notify(receipt payment.Receipt) error {
+	send := e.Send
+	if send == nil {
+		send = smtp.SendMail
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nDate: %s\r\nSubject: Payment receipt\r\n\r\nWe received your payment of %.2f.\r\n%s\r\n",
+		e.From, strings.Join(e.To, ", "), receipt.Time.Format(time.RFC1123Z), receipt.Amount, describe(receipt))
+	return send(e.Addr, e.Auth, e.From, e.To, []byte(msg))
+}
+
+// SMS texts receipts through a Twilio-style messages API
+type SMS struct {
+	// BaseURL defaults to TWILIO_URL
+	BaseURL    string
+	AccountSID string
+	AuthToken  string
+	From       string
+	To         string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+func (s SMS) Notify(receipt payment.Receipt) error {
+	baseURL := s.BaseURL
+	if baseURL == "" {
+		baseURL = TWILIO_URL
+	}
+	form := url.Values{
+		"From": {s.From},
+		"To":   {s.To},
+		"Body": {fmt.Sprintf("Payment of %.2f received", receipt.Amount)},
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimSuffix(baseURL, "/"), url.PathEscape(s.AccountSID))
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.AccountSID, s.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notify: sms: %s: %s", resp.Status, strings.TrimSpace(string(reason)))
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDeclined is a payment the provider refused, such as a declined card
+	ErrDeclined = errors.New("payment: declined")
+	// ErrUnavailable is a method that could not be reached or is down
+	ErrUnavailable = errors.New("payment: method unavailable")
+)
+
+// Failure is a payment a method did not take. Callers branch on its Reason
+// with errors.Is or errors.As rather than on its message.
+type Failure struct {
+	// Method names the method that failed, such as Stripe
+	Method string
+	Amount float64
+	// Reason wraps ErrDeclined, ErrUnavailable, the error of a context or
+	// whatever else the method ran into
+	Reason error
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("%s payment of %f failed: %v", f.Method, f.Amount, f.Reason)
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Reason
+}
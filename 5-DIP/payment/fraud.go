@@ -0,0 +1,14 @@
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSuspectedFraud is a payment a FraudChecker refused to let through
+var ErrSuspectedFraud = errors.New("payment: suspected fraud")
+
+// FraudChecker rejects a payment before it is paid, wrapping ErrSuspectedFraud
+type FraudChecker interface {
+	Check(ctx context.Context, amount float64) error
+}
@@ -0,0 +1,19 @@
+package payment
+
+// Level tells how much a log entry matters
+type Level string
+
+const (
+	LevelInfo  Level = "info"
+	LevelError Level = "error"
+)
+
+// Logger records a message followed by alternating keys and values
+type Logger interface {
+	Log(level Level, msg string, args ...any)
+}
+
+// discard is the Logger of processors that were given none
+type discard struct{}
+
+func (discard) Log(Level, string, ...any) {}
@@ -0,0 +1,19 @@
+package payment
+
+import "time"
+
+// Receipt tells the customer about a payment that went through
+type Receipt struct {
+	Amount float64
+	// Method names what paid, such as Credit Card
+	Method string
+	// Reference is the provider's ID of the payment, it may be empty
+	Reference string
+	// Time is when the payment went through
+	Time time.Time
+}
+
+// Notifier delivers the receipts of payments that went through
+type Notifier interface {
+	Notify(receipt Receipt) error
+}
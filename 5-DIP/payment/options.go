@@ -0,0 +1,68 @@
+package payment
+
+import (
+	"github.com/imrancluster/go-solid/clock"
+	"github.com/imrancluster/go-solid/id"
+)
+
+// Option sets an optional dependency of a PaymentProcessor
+type Option func(p *PaymentProcessor)
+
+// NewPaymentProcessor returns a processor paying with method. Without
+// options it logs nothing, keeps no transactions, sends no receipts, reads
+// the real clock and names transactions with random UUIDs.
+func NewPaymentProcessor(method PaymentMethod, opts ...Option) PaymentProcessor {
+	p := PaymentProcessor{Method: method, Clock: clock.Real{}, Logger: discard{}, IDs: id.UUID{}}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return p
+}
+
+// WithLogger logs every payment to logger
+func WithLogger(logger Logger) Option {
+	return func(p *PaymentProcessor) {
+		if logger != nil {
+			p.Logger = logger
+		}
+	}
+}
+
+// WithClock dates transactions and receipts with c
+func WithClock(c clock.Clock) Option {
+	return func(p *PaymentProcessor) {
+		if c != nil {
+			p.Clock = c
+		}
+	}
+}
+
+// WithNotifier sends a receipt for every successful payment through n
+func WithNotifier(n Notifier) Option {
+	return func(p *PaymentProcessor) {
+		p.Notifier = n
+	}
+}
+
+// WithStore keeps every transaction in store
+func WithStore(store TransactionStore) Option {
+	return func(p *PaymentProcessor) {
+		p.Store = store
+	}
+}
+
+// WithIDs names transactions with ids
+func WithIDs(ids id.Generator) Option {
+	return func(p *PaymentProcessor) {
+		if ids != nil {
+			p.IDs = ids
+		}
+	}
+}
+
+// WithFraudChecker vets every payment with checker before paying it
+func WithFraudChecker(checker FraudChecker) Option {
+	return func(p *PaymentProcessor) {
+		p.Fraud = checker
+	}
+}
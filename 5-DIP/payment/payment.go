@@ -0,0 +1,119 @@
+// Package payment is the DIP example. The high-level PaymentProcessor
+// depends on the PaymentMethod abstraction and never on a concrete method,
+// so methods can be added or swapped without touching it. The fraud checks,
+// notifier, logger and store it is given are interfaces of this package for
+// the same reason.
+package payment
+
+import (
+	"context"
+
+	"github.com/imrancluster/go-solid/clock"
+	"github.com/imrancluster/go-solid/id"
+)
+
+// PaymentMethod interface (abstraction). Pay returns the paid transaction,
+// or an error wrapping ErrDeclined, ErrUnavailable, the error of ctx once
+// ctx is done, or whatever else kept the payment from going through.
+type PaymentMethod interface {
+	Pay(ctx context.Context, amount float64) (Transaction, error)
+}
+
+// CreditCard struct (low-level module)
+type CreditCard struct{}
+
+func (cc CreditCard) Pay(ctx context.Context, amount float64) (Transaction, error) {
+	return pay(ctx, "Credit Card", amount)
+}
+
+// PayPal struct (low-level module)
+type PayPal struct{}
+
+func (pp PayPal) Pay(ctx context.Context, amount float64) (Transaction, error) {
+	return pay(ctx, "PayPal", amount)
+}
+
+// BankTransfer struct (low-level module)
+type BankTransfer struct{}
+
+func (bt BankTransfer) Pay(ctx context.Context, amount float64) (Transaction, error) {
+	return pay(ctx, "Bank Transfer", amount)
+}
+
+// pay takes amount at once unless ctx is done
+func pay(ctx context.Context, method string, amount float64) (Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return Transaction{}, &Failure{Method: method, Amount: amount, Reason: err}
+	}
+	return Transaction{Amount: amount, Method: method, Paid: true}, nil
+}
+
+// PaymentProcessor struct (high-level module)
+type PaymentProcessor struct {
+	Method PaymentMethod
+	// Notifier sends a receipt for every successful payment, it may be nil
+	Notifier Notifier
+	// Clock dates transactions and receipts, it defaults to the real clock
+	Clock clock.Clock
+	// Logger records every payment, it defaults to discarding them
+	Logger Logger
+	// Store keeps every transaction, it may be nil
+	Store TransactionStore
+	// IDs names transactions, it defaults to random UUIDs
+	IDs id.Generator
+	// Fraud vets every payment before Method is called, it may be nil
+	Fraud FraudChecker
+}
+
+// Process pays amount and returns the transaction. A failed payment is
+// returned as well, unpaid, along with the method's error, or the fraud
+// checker's when it rejected the payment and the method was never called.
+// ctx is passed on to both, so canceling it abandons the payment.
+func (p PaymentProcessor) Process(ctx context.Context, amount float64) (Transaction, error) {
+	logger := p.Logger
+	if logger == nil {
+		logger = discard{}
+	}
+	tx, err := p.pay(ctx, amount)
+	if err != nil {
+		tx = Transaction{Amount: amount, Error: err.Error()}
+	}
+	tx.ID = p.newID()
+	tx.Time = clock.Or(p.Clock).Now()
+	if p.Store != nil {
+		if err := p.Store.Save(tx); err != nil {
+			logger.Log(LevelError, "recording transaction failed", "amount", amount, "err", err)
+		}
+	}
+	if err != nil {
+		logger.Log(LevelError, "payment failed", "id", tx.ID, "amount", amount, "err", err)
+		return tx, err
+	}
+	logger.Log(LevelInfo, "payment succeeded", "id", tx.ID, "amount", amount, "method", tx.Method, "reference", tx.Reference)
+	if p.Notifier == nil {
+		return tx, nil
+	}
+	receipt := Receipt{Amount: tx.Amount, Method: tx.Method, Reference: tx.Reference, Time: tx.Time}
+	if err := p.Notifier.Notify(receipt); err != nil {
+		logger.Log(LevelError, "sending receipt failed", "amount", amount, "err", err)
+	}
+	return tx, nil
+}
+
+// pay calls the method unless the fraud checker rejects the payment
+func (p PaymentProcessor) pay(ctx context.Context, amount float64) (Transaction, error) {
+	if p.Fraud != nil {
+		if err := p.Fraud.Check(ctx, amount); err != nil {
+			return Transaction{}, err
+		}
+	}
+	return p.Method.Pay(ctx, amount)
+}
+
+// newID names a transaction
+func (p PaymentProcessor) newID() string {
+	if p.IDs == nil {
+		return id.UUID{}.NewID()
+	}
+	return p.IDs.NewID()
+}
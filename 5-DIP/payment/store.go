@@ -0,0 +1,26 @@
+package payment
+
+import "time"
+
+// Transaction is one payment. Methods return the ones that went through;
+// the processor also records failed ones, with the reason in Error.
+type Transaction struct {
+	// ID is set by the processor and names the transaction in the store
+	ID     string  `json:"id"`
+	Amount float64 `json:"amount"`
+	// Method names what paid, such as Credit Card
+	Method string `json:"method,omitempty"`
+	// Reference is the provider's ID of the payment, it may be empty
+	Reference string `json:"reference,omitempty"`
+	Paid      bool   `json:"paid"`
+	// Error is why the payment failed
+	Error string `json:"error,omitempty"`
+	// Time is set by the processor
+	Time time.Time `json:"time"`
+}
+
+// TransactionStore keeps every transaction the processor handles in order
+type TransactionStore interface {
+	Save(tx Transaction) error
+	All() ([]Transaction, error)
+}
@@ -0,0 +1,152 @@
+// Package paymenttest provides fake payment methods, a fraud checker that
+// lets everything through and a recording logger, so code that consumes a
+// PaymentProcessor can be tested without a real method behind it. The
+// fakes are safe for concurrent use.
+package paymenttest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+var (
+	_ payment.PaymentMethod = (*SpyMethod)(nil)
+	_ payment.PaymentMethod = (*StubMethod)(nil)
+	_ payment.PaymentMethod = FailingMethod{}
+	_ payment.PaymentMethod = SlowMethod{}
+	_ payment.FraudChecker  = AllowAll{}
+	_ payment.Logger        = (*LogRecorder)(nil)
+)
+
+// SpyMethod records every amount it is asked to pay and pays through
+// Method, or pays at once when Method is nil. The zero value is ready to
+// use.
+type SpyMethod struct {
+	Method payment.PaymentMethod
+
+	mu    sync.Mutex
+	calls []float64
+}
+
+func (s *SpyMethod) Pay(ctx context.Context, amount float64) (payment.Transaction, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, amount)
+	s.mu.Unlock()
+	if s.Method != nil {
+		return s.Method.Pay(ctx, amount)
+	}
+	return paid(ctx, "Spy", amount)
+}
+
+// Calls returns the amounts paid so far, in order
+func (s *SpyMethod) Calls() []float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.calls)
+}
+
+// StubMethod answers with Errs one after the other and keeps repeating
+// the last one. A nil error is a payment that goes through, so without
+// Errs every payment does.
+type StubMethod struct {
+	Errs []error
+
+	mu   sync.Mutex
+	next int
+}
+
+// Stub returns a StubMethod answering with errs
+func Stub(errs ...error) *StubMethod {
+	return &StubMethod{Errs: errs}
+}
+
+func (s *StubMethod) Pay(ctx context.Context, amount float64) (payment.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	if len(s.Errs) > 0 {
+		err = s.Errs[min(s.next, len(s.Errs)-1)]
+		s.next++
+	}
+	if err != nil {
+		return payment.Transaction{}, &payment.Failure{Method: "Stub", Amount: amount, Reason: err}
+	}
+	return payment.Transaction{Amount: amount, Method: "Stub", Paid: true}, nil
+}
+
+// FailingMethod fails every payment with Err
+type FailingMethod struct {
+	// Err defaults to payment.ErrDeclined
+	Err error
+}
+
+func (f FailingMethod) Pay(ctx context.Context, amount float64) (payment.Transaction, error) {
+	err := f.Err
+	if err == nil {
+		err = payment.ErrDeclined
+	}
+	return payment.Transaction{}, &payment.Failure{Method: "Failing", Amount: amount, Reason: err}
+}
+
+// SlowMethod takes Delay to pay, so tests can cancel payments in flight.
+// A payment whose ctx is done first fails with the error of ctx.
+type SlowMethod struct {
+	Delay time.Duration
+}
+
+func (s SlowMethod) Pay(ctx context.Context, amount float64) (payment.Transaction, error) {
+	timer := time.NewTimer(s.Delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	return paid(ctx, "Slow", amount)
+}
+
+// paid is a payment by method that goes through unless ctx is done
+func paid(ctx context.Context, method string, amount float64) (payment.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return payment.Transaction{}, &payment.Failure{Method: method, Amount: amount, Reason: err}
+	}
+	return payment.Transaction{Amount: amount, Method: method, Paid: true}, nil
+}
+
+// AllowAll is a fraud checker that lets every payment through, for tests
+// that are not about fraud
+type AllowAll struct{}
+
+func (AllowAll) Check(context.Context, float64) error {
+	return nil
+}
+
+// Entry is one message a LogRecorder was given
+type Entry struct {
+	Level payment.Level
+	Msg   string
+	Args  []any
+}
+
+// LogRecorder keeps everything it is asked to log. The zero value is ready
+// to use.
+type LogRecorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (l *LogRecorder) Log(level payment.Level, msg string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, Entry{Level: level, Msg: msg, Args: slices.Clone(args)})
+}
+
+// Entries returns what was logged so far, in order
+func (l *LogRecorder) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.entries)
+}
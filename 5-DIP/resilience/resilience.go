@@ -0,0 +1,264 @@
+// Package resilience wraps payment methods in retries, a circuit breaker
+// and fallbacks to other methods. The wrappers are payment methods
+// themselves, so the processor cannot tell them from the method they wrap
+// and the wiring layer decides whether a method gets them at all.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/clock"
+)
+
+const (
+	DEFAULT_ATTEMPTS    = 3
+	DEFAULT_BACKOFF     = 100 * time.Millisecond
+	DEFAULT_MAX_BACKOFF = 2 * time.Second
+	DEFAULT_THRESHOLD   = 5
+	DEFAULT_COOLDOWN    = 30 * time.Second
+)
+
+var (
+	// ErrCircuitOpen is why a Breaker refuses payments
+	ErrCircuitOpen = errors.New("resilience: circuit open")
+	// ErrNoMethod is returned by a Fallback without methods
+	ErrNoMethod = errors.New("resilience: no payment method")
+)
+
+var (
+	_ payment.PaymentMethod = Retry{}
+	_ payment.PaymentMethod = (*Breaker)(nil)
+	_ payment.PaymentMethod = Fallback{}
+)
+
+// Retry pays again when Method fails with one of RetryOn, waiting twice
+// as long before every further attempt. Only wrap methods whose failed
+// payments charged nothing, or the customer may pay twice.
+type Retry struct {
+	Method payment.PaymentMethod
+	// RetryOn defaults to payment.ErrUnavailable, a declined payment is
+	// declined again
+	RetryOn []error
+	// Attempts bounds the payments tried, it defaults to DEFAULT_ATTEMPTS
+	Attempts int
+	// Backoff is the first wait, it defaults to DEFAULT_BACKOFF
+	Backoff time.Duration
+	// MaxBackoff caps the wait, it defaults to DEFAULT_MAX_BACKOFF
+	MaxBackoff time.Duration
+	// Sleep waits d unless ctx is done first, it defaults to a timer
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Pay returns the first payment that goes through or the last failure
+func (r Retry) Pay(ctx context.Context, amount float64) (payment.Transaction, error) {
+	attempts := r.Attempts
+	if attempts <= 0 {
+		attempts = DEFAULT_ATTEMPTS
+	}
+	backoff := r.Backoff
+	if backoff <= 0 {
+		backoff = DEFAULT_BACKOFF
+	}
+	maxBackoff := r.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DEFAULT_MAX_BACKOFF
+	}
+	wait := r.Sleep
+	if wait == nil {
+		wait = sleep
+	}
+
+	retryOn := r.RetryOn
+	if retryOn == nil {
+		retryOn = []error{payment.ErrUnavailable}
+	}
+
+	tx, err := r.Method.Pay(ctx, amount)
+	for attempt := 1; attempt < attempts && isAny(err, retryOn) && ctx.Err() == nil; attempt++ {
+		if err := wait(ctx, backoff); err != nil {
+			return payment.Transaction{}, err
+		}
+		backoff = min(2*backoff, maxBackoff)
+		tx, err = r.Method.Pay(ctx, amount)
+	}
+	return tx, err
+}
+
+// sleep waits d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// State is where a Breaker's circuit is
+type State string
+
+const (
+	// StateClosed lets every payment through
+	StateClosed State = "closed"
+	// StateOpen refuses payments until the cooldown has passed
+	StateOpen State = "open"
+	// StateHalfOpen lets a single probe through to find out whether Method
+	// has recovered
+	StateHalfOpen State = "half-open"
+)
+
+// Breaker stops calling Method after Threshold failures of Method itself
+// in a row and refuses payments with ErrCircuitOpen instead. Only failures
+// matching FailOn and timeouts count: a declined card shows Method is up,
+// so it counts like a payment that went through. Once Cooldown has passed
+// one probe payment goes through: if Method answers it the circuit closes
+// again, otherwise it stays open for another Cooldown. The zero value
+// around a Method is ready to use and it is safe for concurrent use.
+type Breaker struct {
+	Method payment.PaymentMethod
+	// FailOn defaults to payment.ErrUnavailable and
+	// context.DeadlineExceeded
+	FailOn []error
+	// Threshold defaults to DEFAULT_THRESHOLD
+	Threshold int
+	// Cooldown defaults to DEFAULT_COOLDOWN
+	Cooldown time.Duration
+	// Clock defaults to the real clock
+	Clock clock.Clock
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+}
+
+// Pay refuses payments with an error wrapping both ErrCircuitOpen and
+// payment.ErrUnavailable while the circuit is open
+func (b *Breaker) Pay(ctx context.Context, amount float64) (payment.Transaction, error) {
+	if !b.allow() {
+		return payment.Transaction{}, fmt.Errorf("%w: %w", payment.ErrUnavailable, ErrCircuitOpen)
+	}
+	tx, err := b.Method.Pay(ctx, amount)
+	if err != nil && ctx.Err() != nil {
+		// the caller gave up, which says nothing about Method
+		b.abandon()
+		return tx, err
+	}
+	b.record(b.failed(err))
+	return tx, err
+}
+
+// failed reports whether err is a failure of Method rather than of the
+// payment
+func (b *Breaker) failed(err error) bool {
+	failOn := b.FailOn
+	if failOn == nil {
+		failOn = []error{payment.ErrUnavailable, context.DeadlineExceeded}
+	}
+	var timeout interface{ Timeout() bool }
+	return isAny(err, failOn) || errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// State reports where the circuit is
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == "" {
+		return StateClosed
+	}
+	return b.state
+}
+
+// allow reports whether a payment may go through, turning an open circuit
+// half-open for the one probe once its cooldown has passed
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		cooldown := b.Cooldown
+		if cooldown <= 0 {
+			cooldown = DEFAULT_COOLDOWN
+		}
+		if clock.Or(b.Clock).Now().Sub(b.openedAt) < cooldown {
+			return false
+		}
+		b.state = StateHalfOpen
+		return true
+	case StateHalfOpen:
+		return false
+	}
+	return true
+}
+
+// abandon hands the probe of a half-open circuit to the next payment
+func (b *Breaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen {
+		b.state = StateOpen
+	}
+}
+
+// record moves the circuit on after a payment it let through
+func (b *Breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.state, b.failures = StateClosed, 0
+		return
+	}
+	threshold := b.Threshold
+	if threshold <= 0 {
+		threshold = DEFAULT_THRESHOLD
+	}
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= threshold {
+		b.state, b.openedAt = StateOpen, clock.Or(b.Clock).Now()
+	}
+}
+
+// Fallback tries Methods in order, such as card, then wallet, then bank
+// transfer, and moves on to the next only when a payment fails with one
+// of FallThrough. Any other failure, or the last method failing, is the
+// result. Which methods and in what order is left to the wiring layer.
+type Fallback struct {
+	Methods []payment.PaymentMethod
+	// FallThrough defaults to payment.ErrDeclined and
+	// payment.ErrUnavailable, failures another method may not have
+	FallThrough []error
+}
+
+// Pay returns the first payment that goes through or the failure that
+// ended the chain
+func (f Fallback) Pay(ctx context.Context, amount float64) (payment.Transaction, error) {
+	fallThrough := f.FallThrough
+	if fallThrough == nil {
+		fallThrough = []error{payment.ErrDeclined, payment.ErrUnavailable}
+	}
+	if len(f.Methods) == 0 {
+		return payment.Transaction{}, ErrNoMethod
+	}
+	var tx payment.Transaction
+	var err error
+	for _, method := range f.Methods {
+		tx, err = method.Pay(ctx, amount)
+		if !isAny(err, fallThrough) {
+			break
+		}
+	}
+	return tx, err
+}
+
+// isAny reports whether err is one of targets
+func isAny(err error, targets []error) bool {
+	return err != nil && slices.ContainsFunc(targets, func(target error) bool { return errors.Is(err, target) })
+}
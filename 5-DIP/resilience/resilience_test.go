@@ -0,0 +1,227 @@
+package resilience_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/5-DIP/paymenttest"
+	"github.com/imrancluster/go-solid/5-DIP/resilience"
+	"github.com/imrancluster/go-solid/clock"
+)
+
+// waits records the backoffs of a Retry instead of sleeping through them
+type waits []time.Duration
+
+func (w *waits) sleep(ctx context.Context, d time.Duration) error {
+	*w = append(*w, d)
+	return ctx.Err()
+}
+
+func TestRetryUnavailable(t *testing.T) {
+	var slept waits
+	method := &paymenttest.SpyMethod{Method: paymenttest.Stub(payment.ErrUnavailable, payment.ErrUnavailable, nil)}
+	retry := resilience.Retry{Method: method, Attempts: 5, Backoff: time.Second, MaxBackoff: 3 * time.Second, Sleep: slept.sleep}
+
+	tx, err := retry.Pay(context.Background(), 100)
+	if err != nil || !tx.Paid {
+		t.Fatalf("Pay = %+v, %v, want it paid on the third attempt", tx, err)
+	}
+	if calls := len(method.Calls()); calls != 3 {
+		t.Errorf("Method was called %d times, want 3", calls)
+	}
+	if want := (waits{time.Second, 2 * time.Second}); !slices.Equal(slept, want) {
+		t.Errorf("Retry waited %v, want %v", slept, want)
+	}
+}
+
+// TestRetryGivesUp makes sure Retry stops after Attempts, caps its backoff
+// and returns the last failure
+func TestRetryGivesUp(t *testing.T) {
+	var slept waits
+	method := &paymenttest.SpyMethod{Method: paymenttest.FailingMethod{Err: payment.ErrUnavailable}}
+	retry := resilience.Retry{Method: method, Attempts: 4, Backoff: time.Second, MaxBackoff: 3 * time.Second, Sleep: slept.sleep}
+
+	if _, err := retry.Pay(context.Background(), 100); !errors.Is(err, payment.ErrUnavailable) {
+		t.Fatalf("Pay error = %v, want %v", err, payment.ErrUnavailable)
+	}
+	if calls := len(method.Calls()); calls != 4 {
+		t.Errorf("Method was called %d times, want 4", calls)
+	}
+	if want := (waits{time.Second, 2 * time.Second, 3 * time.Second}); !slices.Equal(slept, want) {
+		t.Errorf("Retry waited %v, want %v", slept, want)
+	}
+}
+
+func TestRetryDoesNotRetryDeclines(t *testing.T) {
+	method := &paymenttest.SpyMethod{Method: paymenttest.FailingMethod{}}
+	retry := resilience.Retry{Method: method, Sleep: new(waits).sleep}
+	if _, err := retry.Pay(context.Background(), 100); !errors.Is(err, payment.ErrDeclined) {
+		t.Fatalf("Pay error = %v, want %v", err, payment.ErrDeclined)
+	}
+	if calls := len(method.Calls()); calls != 1 {
+		t.Errorf("a declined payment was tried %d times, want once", calls)
+	}
+}
+
+// TestRetryStopsWithContext makes sure a caller giving up during the
+// backoff ends the retries
+func TestRetryStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	method := &paymenttest.SpyMethod{Method: paymenttest.FailingMethod{Err: payment.ErrUnavailable}}
+	retry := resilience.Retry{Method: method, Attempts: 5, Backoff: time.Hour, Sleep: func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}}
+	if _, err := retry.Pay(ctx, 100); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Pay error = %v, want %v", err, context.Canceled)
+	}
+	if calls := len(method.Calls()); calls != 1 {
+		t.Errorf("Method was called %d times after the caller gave up, want once", calls)
+	}
+}
+
+// newBreaker returns a breaker over method that opens after 3 failures and
+// cools down for a minute of now
+func newBreaker(method payment.PaymentMethod, now clock.Clock) *resilience.Breaker {
+	return &resilience.Breaker{Method: method, Threshold: 3, Cooldown: time.Minute, Clock: now}
+}
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	now := clock.NewFrozen(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	stub := paymenttest.Stub(payment.ErrUnavailable, payment.ErrUnavailable, payment.ErrUnavailable, payment.ErrUnavailable, nil)
+	method := &paymenttest.SpyMethod{Method: stub}
+	breaker := newBreaker(method, now)
+
+	for range 3 {
+		breaker.Pay(context.Background(), 100)
+	}
+	if state := breaker.State(); state != resilience.StateOpen {
+		t.Fatalf("State after 3 failures = %q, want %q", state, resilience.StateOpen)
+	}
+	_, err := breaker.Pay(context.Background(), 100)
+	if !errors.Is(err, resilience.ErrCircuitOpen) || !errors.Is(err, payment.ErrUnavailable) {
+		t.Errorf("Pay with the circuit open error = %v, want %v and %v", err, resilience.ErrCircuitOpen, payment.ErrUnavailable)
+	}
+	if calls := len(method.Calls()); calls != 3 {
+		t.Errorf("Method was called %d times, want the open circuit to spare it", calls)
+	}
+
+	// the probe fails, the circuit stays open for another cooldown
+	now.Advance(time.Minute)
+	if _, err := breaker.Pay(context.Background(), 100); errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Fatalf("Pay after the cooldown error = %v, want a probe", err)
+	}
+	if state := breaker.State(); state != resilience.StateOpen {
+		t.Fatalf("State after a failed probe = %q, want %q", state, resilience.StateOpen)
+	}
+	// the next probe is paid and closes it
+	now.Advance(time.Minute)
+	if tx, err := breaker.Pay(context.Background(), 100); err != nil || !tx.Paid {
+		t.Fatalf("Pay of the second probe = %+v, %v, want it paid", tx, err)
+	}
+	if state := breaker.State(); state != resilience.StateClosed {
+		t.Errorf("State after a paid probe = %q, want %q", state, resilience.StateClosed)
+	}
+}
+
+// TestBreakerIgnoresDeclines makes sure only failures of the method count:
+// declined cards show the gateway is up
+func TestBreakerIgnoresDeclines(t *testing.T) {
+	now := clock.NewFrozen(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	breaker := newBreaker(paymenttest.FailingMethod{Err: payment.ErrDeclined}, now)
+	for range 10 {
+		if _, err := breaker.Pay(context.Background(), 100); !errors.Is(err, payment.ErrDeclined) {
+			t.Fatalf("Pay error = %v, want %v", err, payment.ErrDeclined)
+		}
+	}
+	if state := breaker.State(); state != resilience.StateClosed {
+		t.Errorf("State after 10 declines = %q, want %q", state, resilience.StateClosed)
+	}
+
+	// a decline between outages breaks the run of failures
+	stub := paymenttest.Stub(payment.ErrUnavailable, payment.ErrUnavailable, payment.ErrDeclined, payment.ErrUnavailable, payment.ErrUnavailable)
+	breaker = newBreaker(stub, now)
+	for range 5 {
+		breaker.Pay(context.Background(), 100)
+	}
+	if state := breaker.State(); state != resilience.StateClosed {
+		t.Errorf("State after 2 failures, a decline and 2 failures = %q, want %q", state, resilience.StateClosed)
+	}
+}
+
+func TestBreakerCountsTimeouts(t *testing.T) {
+	now := clock.NewFrozen(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	breaker := newBreaker(paymenttest.FailingMethod{Err: timeout{}}, now)
+	for range 3 {
+		breaker.Pay(context.Background(), 100)
+	}
+	if state := breaker.State(); state != resilience.StateOpen {
+		t.Errorf("State after 3 timeouts = %q, want %q", state, resilience.StateOpen)
+	}
+}
+
+// TestBreakerIgnoresCallers makes sure payments the caller gave up on say
+// nothing about the method
+func TestBreakerIgnoresCallers(t *testing.T) {
+	now := clock.NewFrozen(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	breaker := newBreaker(paymenttest.SlowMethod{Delay: time.Hour}, now)
+	for range 5 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		breaker.Pay(ctx, 100)
+		cancel()
+	}
+	if state := breaker.State(); state != resilience.StateClosed {
+		t.Errorf("State after 5 payments the caller gave up on = %q, want %q", state, resilience.StateClosed)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	declined := &paymenttest.SpyMethod{Method: paymenttest.FailingMethod{}}
+	unavailable := &paymenttest.SpyMethod{Method: paymenttest.FailingMethod{Err: payment.ErrUnavailable}}
+	last := &paymenttest.SpyMethod{}
+	fallback := resilience.Fallback{Methods: []payment.PaymentMethod{declined, unavailable, last}}
+
+	tx, err := fallback.Pay(context.Background(), 100)
+	if err != nil || tx.Method != "Spy" {
+		t.Fatalf("Pay = %+v, %v, want it paid by the last method", tx, err)
+	}
+	for i, method := range []*paymenttest.SpyMethod{declined, unavailable, last} {
+		if calls := method.Calls(); len(calls) != 1 {
+			t.Errorf("method %d was called %d times, want once", i, len(calls))
+		}
+	}
+}
+
+// TestFallbackStops makes sure a failure another method would share ends
+// the chain, and so does the last method
+func TestFallbackStops(t *testing.T) {
+	broken := errors.New("invalid amount")
+	next := &paymenttest.SpyMethod{}
+	fallback := resilience.Fallback{Methods: []payment.PaymentMethod{paymenttest.FailingMethod{Err: broken}, next}}
+	if _, err := fallback.Pay(context.Background(), 100); !errors.Is(err, broken) {
+		t.Errorf("Pay error = %v, want %v", err, broken)
+	}
+	if len(next.Calls()) != 0 {
+		t.Error("Fallback moved on after a failure that is not a fall-through")
+	}
+
+	fallback = resilience.Fallback{Methods: []payment.PaymentMethod{paymenttest.FailingMethod{}, paymenttest.FailingMethod{Err: payment.ErrUnavailable}}}
+	if _, err := fallback.Pay(context.Background(), 100); !errors.Is(err, payment.ErrUnavailable) {
+		t.Errorf("Pay error = %v, want the failure of the last method", err)
+	}
+	if _, err := (resilience.Fallback{}).Pay(context.Background(), 100); !errors.Is(err, resilience.ErrNoMethod) {
+		t.Errorf("Pay without methods error = %v, want %v", err, resilience.ErrNoMethod)
+	}
+}
+
+// timeout is a network error that timed out
+type timeout struct{}
+
+func (timeout) Error() string   { return "i/o timeout" }
+func (timeout) Timeout() bool   { return true }
+func (timeout) Temporary() bool { return true }
@@ -0,0 +1,83 @@
+// Package secrets looks up the credentials of the payment gateways. Each
+// source implements gateway.Secrets, so the adapters ask for a key by name
+// without knowing whether it comes from the environment, a mounted file or
+// a test.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/imrancluster/go-solid/5-DIP/gateway"
+)
+
+var (
+	// ErrNotFound is returned for secrets a source does not have
+	ErrNotFound = errors.New("secrets: not found")
+	// ErrBadName is returned for names File cannot look up
+	ErrBadName = errors.New("secrets: bad name")
+)
+
+var (
+	_ gateway.Secrets = Env{}
+	_ gateway.Secrets = File{}
+	_ gateway.Secrets = Memory{}
+)
+
+// Env reads secrets from environment variables, Prefix followed by the
+// name in upper case, so stripe_key is PAYMENT_STRIPE_KEY with the prefix
+// PAYMENT_. An empty variable is not found.
+type Env struct {
+	Prefix string
+	// Getenv defaults to os.Getenv
+	Getenv func(key string) string
+}
+
+func (e Env) Secret(_ context.Context, name string) (string, error) {
+	getenv := e.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	key := e.Prefix + strings.ToUpper(name)
+	if value := getenv(key); value != "" {
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+}
+
+// File reads every secret from the file of the same name in Dir, the way
+// Docker and Kubernetes mount them, such as /run/secrets/stripe_key. A
+// trailing newline is not part of the secret.
+type File struct {
+	Dir string
+}
+
+func (f File) Secret(_ context.Context, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%w: %q", ErrBadName, name)
+	}
+	path := filepath.Join(f.Dir, name)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Memory holds secrets by name, for tests and for credentials the caller
+// already has
+type Memory map[string]string
+
+func (m Memory) Secret(_ context.Context, name string) (string, error) {
+	if value, ok := m[name]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+}
@@ -0,0 +1,179 @@
+// Package store persists the transactions of a payment processor. Each
+// store implements payment.TransactionStore, so the processor records
+// payments without knowing about files, SQL or drivers.
+package store
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+)
+
+// DEFAULT_TABLE is the table SQL keeps transactions in
+const DEFAULT_TABLE = "transactions"
+
+// ErrBadTable is returned by SQL for a Table that is not a plain identifier
+var ErrBadTable = errors.New("store: table name must be a plain identifier")
+
+// identifier matches the table names SQL accepts
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var (
+	_ payment.TransactionStore = (*Memory)(nil)
+	_ payment.TransactionStore = (*File)(nil)
+	_ payment.TransactionStore = SQL{}
+)
+
+// Memory keeps transactions in memory. The zero value is ready to use.
+type Memory struct {
+	mu           sync.Mutex
+	transactions []payment.Transaction
+}
+
+func (m *Memory) Save(tx payment.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transactions = append(m.transactions, tx)
+	return nil
+}
+
+func (m *Memory) All() ([]payment.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.transactions), nil
+}
+
+// File appends transactions to Path as JSON lines
+type File struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (f *File) Save(tx payment.Transaction) error {
+	line, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// All reads the whole file. A missing file holds no transactions.
+func (f *File) All() ([]payment.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.Open(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var transactions []payment.Transaction
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var tx payment.Transaction
+		if err := json.Unmarshal(scanner.Bytes(), &tx); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", f.Path, line, err)
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions, scanner.Err()
+}
+
+// SQL keeps transactions in a database table. DB is opened by the caller
+// with whichever driver it registered. The statements are written for
+// SQLite and use ? placeholders. Times are kept as RFC 3339 text, so they
+// read back the same whatever the driver does with timestamps.
+type SQL struct {
+	DB *sql.DB
+	// Table defaults to DEFAULT_TABLE and must be a plain identifier, as
+	// it is written into the statements
+	Table string
+}
+
+// CreateTable creates the table unless it exists
+func (s SQL) CreateTable() error {
+	table, err := s.table()
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	id TEXT NOT NULL UNIQUE,
+	amount REAL NOT NULL,
+	method TEXT NOT NULL,
+	reference TEXT NOT NULL,
+	paid BOOLEAN NOT NULL,
+	error TEXT NOT NULL,
+	time TEXT NOT NULL
+)`, table))
+	return err
+}
+
+func (s SQL) Save(tx payment.Transaction) error {
+	table, err := s.table()
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.Exec(fmt.Sprintf("INSERT INTO %s (id, amount, method, reference, paid, error, time) VALUES (?, ?, ?, ?, ?, ?, ?)", table),
+		tx.ID, tx.Amount, tx.Method, tx.Reference, tx.Paid, tx.Error, tx.Time.UTC().Format(time.RFC3339Nano))
+	return err
+}
+
+func (s SQL) All() ([]payment.Transaction, error) {
+	table, err := s.table()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.DB.Query(fmt.Sprintf("SELECT id, amount, method, reference, paid, error, time FROM %s ORDER BY seq", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var transactions []payment.Transaction
+	for rows.Next() {
+		var tx payment.Transaction
+		var at string
+		if err := rows.Scan(&tx.ID, &tx.Amount, &tx.Method, &tx.Reference, &tx.Paid, &tx.Error, &at); err != nil {
+			return nil, err
+		}
+		if tx.Time, err = time.Parse(time.RFC3339Nano, at); err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", tx.ID, err)
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions, rows.Err()
+}
+
+// table returns the name of the table, failing with ErrBadTable for names
+// that are not plain identifiers
+func (s SQL) table() (string, error) {
+	if s.Table == "" {
+		return DEFAULT_TABLE, nil
+	}
+	if !identifier.MatchString(s.Table) {
+		return "", fmt.Errorf("%w: %q", ErrBadTable, s.Table)
+	}
+	return s.Table, nil
+}
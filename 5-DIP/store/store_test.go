@@ -0,0 +1,230 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/5-DIP/store"
+)
+
+// TRANSACTIONS are saved by every test, one paid and one failed, with
+// times in a zone other than UTC
+var TRANSACTIONS = []payment.Transaction{
+	{ID: "tx-1", Amount: 100, Method: "Credit Card", Reference: "pi_1", Paid: true, Time: time.Date(2024, 3, 1, 9, 30, 0, 123456789, time.FixedZone("CET", 3600))},
+	{ID: "tx-2", Amount: 60, Method: "Stripe", Error: "declined", Time: time.Date(2024, 3, 1, 9, 31, 0, 0, time.UTC)},
+}
+
+// TestStores runs the same round trip through every store
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) payment.TransactionStore{
+		"Memory": func(*testing.T) payment.TransactionStore { return &store.Memory{} },
+		"File": func(t *testing.T) payment.TransactionStore {
+			return &store.File{Path: filepath.Join(t.TempDir(), "transactions.jsonl")}
+		},
+		"SQL": func(t *testing.T) payment.TransactionStore {
+			s := store.SQL{DB: openFake(t, &fakeDB{})}
+			if err := s.CreateTable(); err != nil {
+				t.Fatalf("CreateTable returned error: %v", err)
+			}
+			return s
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			if all, err := s.All(); err != nil || len(all) != 0 {
+				t.Fatalf("All of a new store = %v, %v, want no transactions", all, err)
+			}
+			for _, tx := range TRANSACTIONS {
+				if err := s.Save(tx); err != nil {
+					t.Fatalf("Save(%s) returned error: %v", tx.ID, err)
+				}
+			}
+			all, err := s.All()
+			if err != nil {
+				t.Fatalf("All returned error: %v", err)
+			}
+			if len(all) != len(TRANSACTIONS) {
+				t.Fatalf("All returned %d transactions, want %d", len(all), len(TRANSACTIONS))
+			}
+			for i, tx := range all {
+				want := TRANSACTIONS[i]
+				if !tx.Time.Equal(want.Time) {
+					t.Errorf("transaction %d time = %v, want %v", i, tx.Time, want.Time)
+				}
+				tx.Time = want.Time
+				if tx != want {
+					t.Errorf("transaction %d = %+v, want %+v", i, tx, want)
+				}
+			}
+		})
+	}
+}
+
+// TestSQLTable makes sure the table name is checked before it is written
+// into a statement
+func TestSQLTable(t *testing.T) {
+	db := &fakeDB{}
+	custom := store.SQL{DB: openFake(t, db), Table: "payments_2024"}
+	if err := custom.CreateTable(); err != nil {
+		t.Fatalf("CreateTable with table payments_2024 returned error: %v", err)
+	}
+	if err := custom.Save(TRANSACTIONS[0]); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if _, ok := db.tables["payments_2024"]; !ok {
+		t.Errorf("the store wrote to %v, want payments_2024", db.statements)
+	}
+
+	db = &fakeDB{}
+	for _, table := range []string{"transactions; DROP TABLE users", "2024_payments", "payments-2024", `"payments"`} {
+		bad := store.SQL{DB: openFake(t, db), Table: table}
+		if err := bad.CreateTable(); !errors.Is(err, store.ErrBadTable) {
+			t.Errorf("CreateTable with table %q error = %v, want %v", table, err, store.ErrBadTable)
+		}
+		if err := bad.Save(TRANSACTIONS[0]); !errors.Is(err, store.ErrBadTable) {
+			t.Errorf("Save with table %q error = %v, want %v", table, err, store.ErrBadTable)
+		}
+		if _, err := bad.All(); !errors.Is(err, store.ErrBadTable) {
+			t.Errorf("All with table %q error = %v, want %v", table, err, store.ErrBadTable)
+		}
+	}
+	if len(db.statements) > 0 {
+		t.Errorf("bad table names reached the database: %q", db.statements)
+	}
+}
+
+// openFake opens a database on db
+func openFake(t *testing.T, db *fakeDB) *sql.DB {
+	t.Helper()
+	conn := sql.OpenDB(db)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// fakeDB is a database/sql driver that understands just the statements
+// store.SQL sends, since no real driver is a dependency of this module. It
+// keeps values the way SQLite does, booleans as integers and times as the
+// text they were given in.
+type fakeDB struct {
+	mu         sync.Mutex
+	tables     map[string][][]driver.Value
+	statements []string
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return f }
+func (f *fakeDB) Open(string) (driver.Conn, error)             { return fakeConn{f}, nil }
+
+// exec runs query and returns the rows it selects
+func (f *fakeDB) exec(query string, args []driver.Value) ([][]driver.Value, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statements = append(f.statements, query)
+	if f.tables == nil {
+		f.tables = make(map[string][][]driver.Value)
+	}
+	fields := strings.Fields(query)
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS "):
+		if _, ok := f.tables[fields[5]]; !ok {
+			f.tables[fields[5]] = nil
+		}
+		return nil, nil
+	case strings.HasPrefix(query, "INSERT INTO "):
+		rows, ok := f.tables[fields[2]]
+		if !ok {
+			return nil, fmt.Errorf("fake: no such table: %s", fields[2])
+		}
+		row := make([]driver.Value, len(args))
+		for i, arg := range args {
+			switch arg := arg.(type) {
+			case bool:
+				row[i] = int64(0)
+				if arg {
+					row[i] = int64(1)
+				}
+			case time.Time:
+				return nil, errors.New("fake: times are kept as text")
+			default:
+				row[i] = arg
+			}
+		}
+		for _, other := range rows {
+			if other[0] == row[0] {
+				return nil, fmt.Errorf("fake: UNIQUE constraint failed: %s.id", fields[2])
+			}
+		}
+		f.tables[fields[2]] = append(rows, row)
+		return nil, nil
+	case strings.HasPrefix(query, "SELECT "):
+		table := fields[len(fields)-4]
+		rows, ok := f.tables[table]
+		if !ok {
+			return nil, fmt.Errorf("fake: no such table: %s", table)
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("fake: cannot run %q", query)
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("fake: no transactions") }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if _, err := s.db.exec(s.query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := s.db.exec(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "amount", "method", "reference", "paid", "error", "time"}
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,70 @@
+// Command violation shows the processor package payment was refactored
+// from. PaymentProcessor builds its CreditCard itself and calls it
+// directly, so the high-level policy depends on the low-level detail:
+//
+//	PaymentProcessor -> CreditCard    the processor names the card, paying
+//	                                  by PayPal or a fake means editing it
+//
+// After the refactoring the processor owns the abstraction and the card
+// depends on it, so the arrow between them points the other way:
+//
+//	RefactoredProcessor -> PaymentMethod <- CreditCard, PayPal, fakes
+//
+// Run it with: go run ./5-DIP/violation
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/5-DIP/paymenttest"
+)
+
+// CreditCard is the low-level module, with nothing to implement
+type CreditCard struct{}
+
+func (cc CreditCard) Charge(amount float64) string {
+	return fmt.Sprintf("Paid %f using Credit Card", amount)
+}
+
+// PaymentProcessor is the high-level module before the refactoring. It
+// names the concrete card, constructs it and calls its own method on it.
+type PaymentProcessor struct{}
+
+func (p PaymentProcessor) Process(amount float64) {
+	card := CreditCard{}
+	fmt.Println(card.Charge(amount))
+}
+
+// RefactoredProcessor is the same policy after the refactoring. It is only
+// handed a payment.PaymentMethod, as payment.PaymentProcessor is.
+type RefactoredProcessor struct {
+	Method payment.PaymentMethod
+}
+
+func (p RefactoredProcessor) Process(ctx context.Context, amount float64) {
+	tx, err := p.Method.Pay(ctx, amount)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Paid %f using %s\n", tx.Amount, tx.Method)
+}
+
+func main() {
+	ctx := context.Background()
+	// Before: every payment charges a real card, there is no seam to
+	// pay another way or to observe what was charged
+	fmt.Println("Before")
+	PaymentProcessor{}.Process(100)
+
+	// After: the caller picks the method, the processor does not change
+	fmt.Println("After")
+	for _, method := range []payment.PaymentMethod{payment.CreditCard{}, payment.PayPal{}} {
+		RefactoredProcessor{Method: method}.Process(ctx, 100)
+	}
+	spy := &paymenttest.SpyMethod{}
+	RefactoredProcessor{Method: spy}.Process(ctx, 100)
+	fmt.Println("A test sees the processor pay", spy.Calls())
+}
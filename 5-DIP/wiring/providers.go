@@ -0,0 +1,21 @@
+//go:build wireinject
+
+package wiring
+
+import (
+	"github.com/google/wire"
+
+	"github.com/imrancluster/go-solid/5-DIP/app"
+)
+
+// ProviderSet builds an app.App from an app.Config
+var ProviderSet = wire.NewSet(
+	app.NewMethod,
+	app.NewNotifier,
+	app.NewLogger,
+	app.NewStore,
+	app.NewIDs,
+	app.NewFraudChecker,
+	NewProcessor,
+	wire.Struct(new(app.App), "*"),
+)
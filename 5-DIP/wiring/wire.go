@@ -0,0 +1,15 @@
+//go:build wireinject
+
+package wiring
+
+import (
+	"github.com/google/wire"
+
+	"github.com/imrancluster/go-solid/5-DIP/app"
+)
+
+// InitializeApp builds an app.App from the providers of ProviderSet
+func InitializeApp(cfg app.Config) (app.App, error) {
+	wire.Build(ProviderSet)
+	return app.App{}, nil
+}
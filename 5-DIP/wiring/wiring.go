@@ -0,0 +1,32 @@
+// Package wiring is the composition root of package app again, this time
+// declared for google/wire. The providers are the factories app.Wire calls
+// by hand; wire works out the order to call them in from their parameter
+// and result types and writes it down in wire_gen.go, so both roots build
+// the same object graph, which wiring_test.go checks.
+//
+// providers.go holds the provider set and wire.go the injector, both only
+// built with the wireinject tag. After changing them, regenerate
+// wire_gen.go with
+//
+//	go get github.com/google/wire@v0.7.0
+//	go generate ./5-DIP/wiring
+//
+// The generated code only calls the providers, so building and running
+// the example does not need wire at all.
+package wiring
+
+import (
+	"github.com/imrancluster/go-solid/5-DIP/payment"
+	"github.com/imrancluster/go-solid/id"
+)
+
+// NewProcessor connects a processor to its dependencies
+func NewProcessor(method payment.PaymentMethod, notifier payment.Notifier, logger payment.Logger, store payment.TransactionStore, ids id.Generator, checker payment.FraudChecker) payment.PaymentProcessor {
+	return payment.NewPaymentProcessor(method,
+		payment.WithNotifier(notifier),
+		payment.WithLogger(logger),
+		payment.WithStore(store),
+		payment.WithIDs(ids),
+		payment.WithFraudChecker(checker),
+	)
+}
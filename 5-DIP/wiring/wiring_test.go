@@ -0,0 +1,69 @@
+package wiring_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/imrancluster/go-solid/5-DIP/app"
+	"github.com/imrancluster/go-solid/5-DIP/wiring"
+)
+
+// logTime matches the time of a text log line, the one part that differs
+// between two runs
+var logTime = regexp.MustCompile(`time=\S+ `)
+
+// TestSameGraph builds the app by hand and with the injector wire generated
+// and makes sure both are the same object graph and pay the same way
+func TestSameGraph(t *testing.T) {
+	configs := map[string]app.Config{
+		"defaults": {},
+		"card":     {Provider: "card", Log: "text", Store: "memory", IDs: "sequential"},
+		"fraud":    {Provider: "paypal", Log: "text", Store: "memory", IDs: "sequential", Fraud: "rules", FraudMaxAmount: "100"},
+		"fallback": {Provider: "fallback", Fallback: "card, bank", Decorators: []string{"retry", "breaker"}, Log: "text", Store: "memory", IDs: "sequential"},
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			var manualLog, generatedLog bytes.Buffer
+			cfg.LogOutput = &manualLog
+			manual, err := app.Wire(cfg)
+			if err != nil {
+				t.Fatalf("app.Wire returned error: %v", err)
+			}
+			cfg.LogOutput = &generatedLog
+			generated, err := wiring.InitializeApp(cfg)
+			if err != nil {
+				t.Fatalf("InitializeApp returned error: %v", err)
+			}
+			if !reflect.DeepEqual(manual, generated) {
+				t.Errorf("InitializeApp built %#v, want %#v", generated, manual)
+			}
+
+			for _, amount := range []float64{50, 500, 75} {
+				want, wantErr := manual.Processor.Process(context.Background(), amount)
+				got, gotErr := generated.Processor.Process(context.Background(), amount)
+				// random IDs and the time are all that may differ
+				want.Time = got.Time
+				if cfg.IDs != "sequential" {
+					want.ID = got.ID
+				}
+				if got != want || fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+					t.Errorf("Process(%v) of the injected app = %+v, %v, want %+v, %v", amount, got, gotErr, want, wantErr)
+				}
+			}
+			if manual.Store != nil {
+				want, _ := manual.Store.All()
+				got, _ := generated.Store.All()
+				if len(got) != len(want) {
+					t.Errorf("the injected app stored %d transactions, want %d", len(got), len(want))
+				}
+			}
+			if got, want := logTime.ReplaceAllString(generatedLog.String(), ""), logTime.ReplaceAllString(manualLog.String(), ""); got != want {
+				t.Errorf("the injected app logged\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
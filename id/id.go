@@ -0,0 +1,115 @@
+// Package id hands out identifiers. Code that names transactions or
+// invoices depends on the Generator abstraction instead of calling a UUID
+// library, so tests can inject Sequential and get the same identifiers on
+// every run.
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/imrancluster/go-solid/clock"
+)
+
+// CROCKFORD is the base32 alphabet of ULIDs, without I, L, O and U
+const CROCKFORD = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Generator hands out a new identifier on every call. Identifiers are
+// unique for as long as the generator promises, which for UUID and ULID is
+// for all practical purposes forever.
+type Generator interface {
+	NewID() string
+}
+
+var (
+	_ Generator = UUID{}
+	_ Generator = ULID{}
+	_ Generator = (*Sequential)(nil)
+)
+
+// UUID generates random version 4 UUIDs such as
+// 0b6f3c1e-7d2a-4f7e-9c1b-2a5d8e4f6a10. It panics if Rand fails.
+type UUID struct {
+	// Rand defaults to crypto/rand.Reader
+	Rand io.Reader
+}
+
+func (u UUID) NewID() string {
+	var b [16]byte
+	read(u.Rand, b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// ULID generates universally unique lexicographically sortable
+// identifiers such as 01ARZ3NDEKTSV4RRFFQ69G5FAV: a millisecond timestamp
+// followed by randomness, so identifiers sort by when they were made. It
+// panics if Rand fails.
+type ULID struct {
+	// Clock defaults to the real clock
+	Clock clock.Clock
+	// Rand defaults to crypto/rand.Reader
+	Rand io.Reader
+}
+
+func (u ULID) NewID() string {
+	var b [16]byte
+	ms := uint64(clock.Or(u.Clock).Now().UnixMilli())
+	binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+	read(u.Rand, b[6:])
+
+	// 26 characters of 5 bits cover 130 bits, the first one only the top 3
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var s [26]byte
+	for i := range s {
+		var v uint64
+		switch shift := uint(125 - 5*i); {
+		case shift >= 64:
+			v = hi >> (shift - 64)
+		case shift+5 <= 64:
+			v = lo >> shift
+		default:
+			v = hi<<(64-shift) | lo>>shift
+		}
+		s[i] = CROCKFORD[v&31]
+	}
+	return string(s[:])
+}
+
+// Sequential numbers identifiers Prefix-1, Prefix-2 and so on, or just
+// 1, 2 without a Prefix. It is meant for tests and is safe for concurrent
+// use. The zero value is ready to use.
+type Sequential struct {
+	Prefix string
+
+	mu   sync.Mutex
+	next int
+}
+
+func (s *Sequential) NewID() string {
+	s.mu.Lock()
+	s.next++
+	n := s.next
+	s.mu.Unlock()
+	if s.Prefix == "" {
+		return fmt.Sprint(n)
+	}
+	return fmt.Sprintf("%s-%d", s.Prefix, n)
+}
+
+// read fills b from r, crypto/rand.Reader when r is nil
+func read(r io.Reader, b []byte) {
+	if r == nil {
+		r = rand.Reader
+	}
+	if _, err := io.ReadFull(r, b); err != nil {
+		panic("id: reading randomness: " + err.Error())
+	}
+}